@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator accepts a fixed list of keys sent in the X-API-Key
+// header, each key standing for one user.
+type APIKeyAuthenticator struct {
+	// keys are stored by hash, so looking one up does not leak how much of
+	// a wrong key matched.
+	keys map[[sha256.Size]byte]Claims
+}
+
+func NewAPIKeyAuthenticator(keys map[string]Claims) *APIKeyAuthenticator {
+	hashed := map[[sha256.Size]byte]Claims{}
+	for key, claims := range keys {
+		hashed[sha256.Sum256([]byte(key))] = claims
+	}
+
+	return &APIKeyAuthenticator{
+		keys: hashed,
+	}
+}
+
+func (aa *APIKeyAuthenticator) Authenticate(request *http.Request) (*Claims, error) {
+	key := request.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, fmt.Errorf("%w: missing %s header", ErrUnauthenticated, APIKeyHeader)
+	}
+
+	claims, ok := aa.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+	}
+
+	return &claims, nil
+}
+
+// Login is not possible, API keys are handed out ahead of time.
+func (aa *APIKeyAuthenticator) Login(ctx context.Context, username, password string) (*Token, error) {
+	return nil, ErrLoginUnsupported
+}
+
+func (aa *APIKeyAuthenticator) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	return nil, ErrLoginUnsupported
+}
+
+func (aa *APIKeyAuthenticator) Logout(ctx context.Context, refreshToken string) error {
+	return ErrLoginUnsupported
+}
+
+// ParseAPIKeys reads keys written as "key:user[:role|role]" and separated by
+// commas.
+func ParseAPIKeys(value string) (map[string]Claims, error) {
+	keys := map[string]Claims{}
+	for entry := range strings.SplitSeq(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("API key entry should be written as key:user[:role|role]")
+		}
+		claims := Claims{UserId: parts[1], Username: parts[1], Roles: []string{}}
+		if len(parts) == 3 {
+			claims.Roles = parseRoles(parts[2])
+		}
+		keys[parts[0]] = claims
+	}
+	return keys, nil
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	keys, err := ParseAPIKeys("key-1:service-a:admin,key-2:service-b")
+	if err != nil {
+		t.Fatalf("ParseAPIKeys error: %v", err)
+	}
+	authenticator := NewAPIKeyAuthenticator(keys)
+
+	t.Run("known key authenticates its user", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/tasks", nil)
+		request.Header.Set(APIKeyHeader, "key-2")
+
+		claims, err := authenticator.Authenticate(request)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if claims.UserId != "service-b" || len(claims.Roles) != 0 {
+			t.Errorf("expected service-b without roles, but got %v", claims)
+		}
+	})
+	t.Run("missing or unknown key fail", func(t *testing.T) {
+		for _, key := range []string{"", "key-3"} {
+			request, _ := http.NewRequest("GET", "/tasks", nil)
+			request.Header.Set(APIKeyHeader, key)
+
+			if _, err := authenticator.Authenticate(request); !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("expected key %q to be unauthenticated, but got %v", key, err)
+			}
+		}
+	})
+	t.Run("login is not supported", func(t *testing.T) {
+		if _, err := authenticator.Login(context.Background(), "service-a", "key-1"); !errors.Is(err, ErrLoginUnsupported) {
+			t.Errorf("expected login to be unsupported, but got %v", err)
+		}
+	})
+}
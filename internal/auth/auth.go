@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	. "github.com/Arup3201/gotasks/internal/utils"
+)
+
+const (
+	Keycloak  = "Keycloak"
+	StaticJWT = "StaticJWT"
+	APIKey    = "APIKey"
+)
+
+// Roles of the task endpoints, an admin has every role and can read the
+// tasks of every owner.
+const (
+	RoleRead  = "tasks:read"
+	RoleWrite = "tasks:write"
+	RoleAdmin = "tasks:admin"
+)
+
+var (
+	// ErrUnauthenticated is wrapped by the errors of requests without a
+	// valid credential, other errors mean the check itself failed.
+	ErrUnauthenticated    = errors.New("request is not authenticated")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrLoginUnsupported is returned by authenticators without login,
+	// refresh or logout.
+	ErrLoginUnsupported = errors.New("authenticator has no login")
+	// ErrBadGateway is wrapped by the errors of auth server answers that
+	// are server errors or can't be read.
+	ErrBadGateway = errors.New("auth server answered with an error")
+)
+
+// Claims are the identity and roles of an authenticated request.
+type Claims struct {
+	UserId   string
+	Username string
+	Roles    []string
+}
+
+// HasRole reports whether the claims grant role, directly or by being admin.
+func (claims *Claims) HasRole(role string) bool {
+	return slices.Contains(claims.Roles, role) || slices.Contains(claims.Roles, RoleAdmin)
+}
+
+// Token is the token set of a login, the refresh token is only set by
+// authenticators that can refresh.
+type Token struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresIn int    `json:"refresh_expires_in,omitempty"`
+}
+
+type Authenticator interface {
+	// Authenticate returns the claims of the credential the request carries.
+	Authenticate(request *http.Request) (*Claims, error)
+	// Login exchanges the credentials of a user for an access token.
+	Login(ctx context.Context, username, password string) (*Token, error)
+	// Refresh exchanges a refresh token for a new token set.
+	Refresh(ctx context.Context, refreshToken string) (*Token, error)
+	// Logout ends the session of a refresh token, so it can't be refreshed
+	// any more.
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+func New(authType string) (Authenticator, error) {
+	switch authType {
+	case Keycloak:
+		return NewKeycloakAuthenticator(context.Background(), KeycloakConfig{
+			ServerUrl:          Config.KeycloakServerUrl,
+			Realm:              Config.KeycloakRealName,
+			ClientId:           Config.KeycloakClientId,
+			ClientSecret:       Config.KeycloakClientSecret,
+			Issuer:             Config.KeycloakIssuer,
+			Audience:           Config.KeycloakAudience,
+			UserInfo:           Config.KeycloakUserInfo,
+			KeyRefreshInterval: Config.JWKSRefreshInterval,
+			Timeout:            Config.KeycloakTimeout,
+			BreakerFailures:    Config.BreakerFailures,
+			BreakerCooldown:    Config.BreakerCooldown,
+		}), nil
+	case StaticJWT:
+		users, err := ParseUsers(Config.AuthUsers)
+		if err != nil {
+			return nil, err
+		}
+		return NewJWTAuthenticator([]byte(Config.AuthJWTSecret), users, Config.AuthTokenTTL), nil
+	case APIKey:
+		keys, err := ParseAPIKeys(Config.AuthAPIKeys)
+		if err != nil {
+			return nil, err
+		}
+		return NewAPIKeyAuthenticator(keys), nil
+	default:
+		return nil, fmt.Errorf("auth type %s not supported", authType)
+	}
+}
+
+func bearerToken(request *http.Request) (string, error) {
+	header := strings.Fields(request.Header.Get("Authorization"))
+	if len(header) != 2 || header[0] != "Bearer" {
+		return "", fmt.Errorf("%w: malformed token", ErrUnauthenticated)
+	}
+	return header[1], nil
+}
+
+// parseRoles reads roles separated by '|', as used in the user and key lists.
+func parseRoles(value string) []string {
+	roles := []string{}
+	for role := range strings.SplitSeq(value, "|") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/utils"
+)
+
+func TestClaimsHasRole(t *testing.T) {
+	tests := []struct {
+		roles []string
+		role  string
+		want  bool
+	}{
+		{[]string{RoleRead}, RoleRead, true},
+		{[]string{RoleRead}, RoleWrite, false},
+		{[]string{RoleAdmin}, RoleWrite, true},
+		{[]string{}, RoleRead, false},
+	}
+
+	for _, test := range tests {
+		claims := Claims{Roles: test.roles}
+		if got := claims.HasRole(test.role); got != test.want {
+			t.Errorf("expected roles %v to have %s %v, but got %v", test.roles, test.role, test.want, got)
+		}
+	}
+}
+
+func TestNewKeycloakClient(t *testing.T) {
+	previous := *utils.Config
+	t.Cleanup(func() { *utils.Config = previous })
+	utils.Config.KeycloakServerUrl = "http://keycloak.test"
+	utils.Config.KeycloakRealName = "gotasks"
+	utils.Config.KeycloakTimeout = 3 * time.Second
+	utils.Config.BreakerFailures = 4
+	utils.Config.BreakerCooldown = 20 * time.Second
+
+	authenticator, err := New(Keycloak)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	keycloak, ok := authenticator.(*KeycloakAuthenticator)
+	if !ok {
+		t.Fatalf("expected a *KeycloakAuthenticator, but got %T", authenticator)
+	}
+	if keycloak.client.Timeout != 3*time.Second {
+		t.Errorf("expected the client timeout 3s, but got %v", keycloak.client.Timeout)
+	}
+	if keycloak.breaker.failures != 4 || keycloak.breaker.cooldown != 20*time.Second {
+		t.Errorf("expected the breaker to open after 4 failures for 20s, but got %d failures for %v", keycloak.breaker.failures, keycloak.breaker.cooldown)
+	}
+}
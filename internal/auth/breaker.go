@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRetryAfter is the wait suggested after a failed request while the
+// breaker is still closed.
+const minRetryAfter = time.Second
+
+var errBreakerOpen = errors.New("circuit breaker is open")
+
+// breaker stops the requests to the auth server for a cooldown after it
+// failed a number of times in a row, then lets one request through to probe
+// whether it is back. Zero failures turns it off.
+type breaker struct {
+	failures int
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	failed    int
+	openUntil time.Time
+	probing   bool
+}
+
+func newBreaker(failures int, cooldown time.Duration) *breaker {
+	return &breaker{
+		failures: failures,
+		cooldown: cooldown,
+	}
+}
+
+// allow reports how long requests are still stopped, zero lets the request
+// through.
+func (b *breaker) allow() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures == 0 || b.failed < b.failures {
+		return 0
+	}
+	if wait := time.Until(b.openUntil); wait > 0 {
+		return wait
+	}
+	// only one request probes the auth server after the cooldown
+	if b.probing {
+		return minRetryAfter
+	}
+	b.probing = true
+	return 0
+}
+
+func (b *breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failed = 0
+		return
+	}
+	b.failed++
+	if b.failures > 0 && b.failed >= b.failures {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// retryAfter is how long a client should wait before trying again.
+func (b *breaker) retryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if wait := time.Until(b.openUntil); wait > minRetryAfter {
+		return wait
+	}
+	return minRetryAfter
+}
+
+// breakerTransport counts failed round trips and server errors against the
+// breaker, and fails fast while it is open.
+type breakerTransport struct {
+	breaker *breaker
+	next    http.RoundTripper
+}
+
+func (bt *breakerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if wait := bt.breaker.allow(); wait > 0 {
+		return nil, &UnavailableError{RetryAfter: wait, Err: errBreakerOpen}
+	}
+
+	response, err := bt.next.RoundTrip(request)
+	bt.breaker.record(err == nil && response.StatusCode < http.StatusInternalServerError)
+	return response, err
+}
+
+// UnavailableError is returned when the auth server could not be reached,
+// RetryAfter is when it is worth trying again.
+type UnavailableError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UnavailableError) Error() string {
+	return "auth server is unavailable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+const (
+	// minKeyRotationInterval bounds how often tokens signed with an unknown
+	// key can make the cache fetch the keys again.
+	minKeyRotationInterval = 10 * time.Second
+)
+
+// keyCache keeps the signing keys of the realm and refreshes them in the
+// background, a token with an unknown kid means the keys were rotated.
+type keyCache struct {
+	url         string
+	autoRefresh *jwk.AutoRefresh
+
+	mu          sync.Mutex
+	lastRotated time.Time
+}
+
+func newKeyCache(ctx context.Context, url string, refreshInterval time.Duration, client *http.Client) *keyCache {
+	autoRefresh := jwk.NewAutoRefresh(ctx)
+	autoRefresh.Configure(url,
+		jwk.WithRefreshInterval(refreshInterval),
+		jwk.WithHTTPClient(client),
+	)
+
+	return &keyCache{
+		url:         url,
+		autoRefresh: autoRefresh,
+	}
+}
+
+// keySet returns the cached keys, fetching them again first when none of
+// them has the id kid.
+func (kc *keyCache) keySet(ctx context.Context, kid string) (jwk.Set, error) {
+	set, err := kc.autoRefresh.Fetch(ctx, kc.url)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := set.LookupKeyID(kid); ok || kid == "" {
+		return set, nil
+	}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	// another request may have fetched the rotated keys while this one waited
+	set, err = kc.autoRefresh.Fetch(ctx, kc.url)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := set.LookupKeyID(kid); ok || time.Since(kc.lastRotated) < minKeyRotationInterval {
+		return set, nil
+	}
+	kc.lastRotated = time.Now()
+	return kc.autoRefresh.Refresh(ctx, kc.url)
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// jwtIssuer is the issuer of the tokens of JWTAuthenticator, tokens of any
+// other issuer are rejected even when signed with the same secret.
+const jwtIssuer = "gotasks"
+
+// StaticUser is a user of JWTAuthenticator.
+type StaticUser struct {
+	Password string
+	Roles    []string
+}
+
+// JWTAuthenticator issues HMAC signed tokens to a fixed list of users, for
+// local development without an identity provider.
+type JWTAuthenticator struct {
+	secret []byte
+	users  map[string]StaticUser
+	ttl    time.Duration
+}
+
+func NewJWTAuthenticator(secret []byte, users map[string]StaticUser, ttl time.Duration) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		secret: secret,
+		users:  users,
+		ttl:    ttl,
+	}
+}
+
+func (ja *JWTAuthenticator) Authenticate(request *http.Request) (*Claims, error) {
+	strToken, err := bearerToken(request)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse([]byte(strToken),
+		jwt.WithVerify(jwa.HS256, ja.secret),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(jwtIssuer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	return &Claims{
+		UserId:   token.Subject(),
+		Username: token.Subject(),
+		Roles:    appendRoles([]string{}, token.PrivateClaims()["roles"]),
+	}, nil
+}
+
+func (ja *JWTAuthenticator) Login(ctx context.Context, username, password string) (*Token, error) {
+	user, ok := ja.users[username]
+	if !ok || subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	token := jwt.New()
+	token.Set(jwt.IssuerKey, jwtIssuer)
+	token.Set(jwt.SubjectKey, username)
+	token.Set(jwt.IssuedAtKey, time.Now())
+	token.Set(jwt.ExpirationKey, time.Now().Add(ja.ttl))
+	token.Set("roles", user.Roles)
+
+	signed, err := jwt.Sign(token, jwa.HS256, ja.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		AccessToken: string(signed),
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ja.ttl.Seconds()),
+	}, nil
+}
+
+// Refresh is not possible, the tokens can't be revoked so they are only
+// handed out by logging in again.
+func (ja *JWTAuthenticator) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	return nil, ErrLoginUnsupported
+}
+
+func (ja *JWTAuthenticator) Logout(ctx context.Context, refreshToken string) error {
+	return ErrLoginUnsupported
+}
+
+// ParseUsers reads users written as "name:password[:role|role]" and
+// separated by commas.
+func ParseUsers(value string) (map[string]StaticUser, error) {
+	users := map[string]StaticUser{}
+	for entry := range strings.SplitSeq(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("user '%s' should be written as name:password[:role|role]", parts[0])
+		}
+		user := StaticUser{Password: parts[1], Roles: []string{}}
+		if len(parts) == 3 {
+			user.Roles = parseRoles(parts[2])
+		}
+		users[parts[0]] = user
+	}
+	return users, nil
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testSecret = "a-secret-of-at-least-32-characters"
+
+func TestJWTAuthenticator(t *testing.T) {
+	users, err := ParseUsers("alice:secret:admin|user, bob:hunter2")
+	if err != nil {
+		t.Fatalf("ParseUsers error: %v", err)
+	}
+
+	t.Run("login token authenticates the user", func(t *testing.T) {
+		authenticator := NewJWTAuthenticator([]byte(testSecret), users, time.Hour)
+
+		token, err := authenticator.Login(context.Background(), "alice", "secret")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		request, _ := http.NewRequest("GET", "/tasks", nil)
+		request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		claims, err := authenticator.Authenticate(request)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if claims.UserId != "alice" || !slices.Equal(claims.Roles, []string{"admin", "user"}) {
+			t.Errorf("expected alice with roles [admin user], but got %v", claims)
+		}
+		if token.ExpiresIn != 3600 {
+			t.Errorf("expected token to expire in 3600s, but got %d", token.ExpiresIn)
+		}
+	})
+	t.Run("login with wrong password fail", func(t *testing.T) {
+		authenticator := NewJWTAuthenticator([]byte(testSecret), users, time.Hour)
+
+		for _, credential := range [][2]string{{"alice", "hunter2"}, {"carol", "secret"}} {
+			if _, err := authenticator.Login(context.Background(), credential[0], credential[1]); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("expected invalid credentials for %s, but got %v", credential[0], err)
+			}
+		}
+	})
+	t.Run("token of another secret or expired fail", func(t *testing.T) {
+		authenticator := NewJWTAuthenticator([]byte(testSecret), users, time.Hour)
+		other, _ := NewJWTAuthenticator([]byte("another-secret-of-32-characters!!"), users, time.Hour).Login(context.Background(), "bob", "hunter2")
+		expired, _ := NewJWTAuthenticator([]byte(testSecret), users, -time.Minute).Login(context.Background(), "bob", "hunter2")
+
+		for name, token := range map[string]string{"other secret": other.AccessToken, "expired": expired.AccessToken} {
+			request, _ := http.NewRequest("GET", "/tasks", nil)
+			request.Header.Set("Authorization", "Bearer "+token)
+			if _, err := authenticator.Authenticate(request); !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("expected %s token to be unauthenticated, but got %v", name, err)
+			}
+		}
+	})
+	t.Run("malformed user list fail", func(t *testing.T) {
+		if _, err := ParseUsers("alice"); err == nil {
+			t.Errorf("expected user without password to fail")
+		}
+	})
+}
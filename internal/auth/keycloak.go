@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jws"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// tokenSkew is the clock difference with Keycloak tolerated on exp, iat
+// and nbf.
+const tokenSkew = 30 * time.Second
+
+type KeycloakConfig struct {
+	ServerUrl    string
+	Realm        string
+	ClientId     string
+	ClientSecret string
+	Issuer       string
+	// Audience is matched against 'aud' and 'azp' of the tokens.
+	Audience string
+	// UserInfo asks Keycloak for the user of every request, which rejects
+	// tokens of ended sessions at the cost of a round trip.
+	UserInfo           bool
+	KeyRefreshInterval time.Duration
+	// Timeout bounds every request to Keycloak, after BreakerFailures
+	// failed requests in a row no request is sent for BreakerCooldown. Zero
+	// turns either off.
+	Timeout         time.Duration
+	BreakerFailures int
+	BreakerCooldown time.Duration
+}
+
+// KeycloakAuthenticator validates access tokens against the cached realm
+// keys, so a request only reaches Keycloak when the keys rotate.
+type KeycloakAuthenticator struct {
+	config   KeycloakConfig
+	realmUrl string
+	keys     *keyCache
+	client   *http.Client
+	breaker  *breaker
+}
+
+func NewKeycloakAuthenticator(ctx context.Context, config KeycloakConfig) *KeycloakAuthenticator {
+	realmUrl := fmt.Sprintf("%s/realms/%s", config.ServerUrl, config.Realm)
+	breaker := newBreaker(config.BreakerFailures, config.BreakerCooldown)
+	client := &http.Client{
+		Timeout:   config.Timeout,
+		Transport: &breakerTransport{breaker: breaker, next: http.DefaultTransport},
+	}
+
+	return &KeycloakAuthenticator{
+		config:   config,
+		realmUrl: realmUrl,
+		keys:     newKeyCache(ctx, realmUrl+"/protocol/openid-connect/certs", config.KeyRefreshInterval, client),
+		client:   client,
+		breaker:  breaker,
+	}
+}
+
+func (ka *KeycloakAuthenticator) Authenticate(request *http.Request) (*Claims, error) {
+	token, err := bearerToken(request)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := ka.verify(request.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	if ka.config.UserInfo {
+		username, err := ka.fetchUserInfo(request.Context(), token, claims.UserId)
+		if err != nil {
+			return nil, err
+		}
+		claims.Username = username
+	}
+
+	return claims, nil
+}
+
+func (ka *KeycloakAuthenticator) Login(ctx context.Context, username, password string) (*Token, error) {
+	formValues := url.Values{}
+	formValues.Set("grant_type", "password")
+	formValues.Set("username", username)
+	formValues.Set("password", password)
+	formValues.Set("scope", "openid")
+
+	token, err := ka.requestToken(ctx, formValues)
+	if errors.Is(err, errRejected) {
+		log.Printf("login error: %v", err)
+		return nil, ErrInvalidCredentials
+	}
+	return token, err
+}
+
+func (ka *KeycloakAuthenticator) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	formValues := url.Values{}
+	formValues.Set("grant_type", "refresh_token")
+	formValues.Set("refresh_token", refreshToken)
+
+	token, err := ka.requestToken(ctx, formValues)
+	if errors.Is(err, errRejected) {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return token, err
+}
+
+// Logout ends the Keycloak session of the refresh token, which revokes the
+// refresh token and every other token of the session.
+func (ka *KeycloakAuthenticator) Logout(ctx context.Context, refreshToken string) error {
+	formValues := url.Values{}
+	formValues.Set("refresh_token", refreshToken)
+
+	response, err := ka.postForm(ctx, "/protocol/openid-connect/logout", formValues)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: keycloak logout response error: got response with status %d", ErrUnauthenticated, response.StatusCode)
+	}
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: keycloak logout response error: got response with status %d", ErrBadGateway, response.StatusCode)
+	}
+
+	return nil
+}
+
+// errRejected is returned by requestToken when Keycloak refuses the grant,
+// as opposed to failing to answer.
+var errRejected = errors.New("keycloak rejected the grant")
+
+func (ka *KeycloakAuthenticator) requestToken(ctx context.Context, formValues url.Values) (*Token, error) {
+	response, err := ka.postForm(ctx, "/protocol/openid-connect/token", formValues)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("%w: got response with status %d", errRejected, response.StatusCode)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: keycloak token response error: got response with status %d", ErrBadGateway, response.StatusCode)
+	}
+
+	var token Token
+	if err = json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: keycloak token response encoding error: %v", ErrBadGateway, err)
+	}
+
+	return &token, nil
+}
+
+// postForm posts formValues with the client credentials to the realm path.
+func (ka *KeycloakAuthenticator) postForm(ctx context.Context, path string, formValues url.Values) (*http.Response, error) {
+	formValues.Set("client_id", ka.config.ClientId)
+	formValues.Set("client_secret", ka.config.ClientSecret)
+
+	request, err := http.NewRequestWithContext(ctx, "POST", ka.realmUrl+path, strings.NewReader(formValues.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	return ka.do(request)
+}
+
+// do sends request to Keycloak, failing to get an answer is returned as an
+// UnavailableError.
+func (ka *KeycloakAuthenticator) do(request *http.Request) (*http.Response, error) {
+	response, err := ka.client.Do(request)
+	if err != nil {
+		return nil, ka.unavailable(err)
+	}
+	return response, nil
+}
+
+func (ka *KeycloakAuthenticator) unavailable(err error) error {
+	var unavailable *UnavailableError
+	if errors.As(err, &unavailable) {
+		return unavailable
+	}
+	return &UnavailableError{RetryAfter: ka.breaker.retryAfter(), Err: err}
+}
+
+func (ka *KeycloakAuthenticator) verify(ctx context.Context, strToken string) (*Claims, error) {
+	message, err := jws.Parse([]byte(strToken))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	var kid string
+	if signatures := message.Signatures(); len(signatures) > 0 {
+		kid = signatures[0].ProtectedHeaders().KeyID()
+	}
+
+	keys, err := ka.keys.keySet(ctx, kid)
+	if err != nil {
+		return nil, ka.unavailable(fmt.Errorf("fetch signing keys: %w", err))
+	}
+
+	token, err := jwt.Parse([]byte(strToken),
+		jwt.WithKeySet(keys),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(ka.config.Issuer),
+		jwt.WithAcceptableSkew(tokenSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	// Keycloak only puts the client in 'aud' with an audience mapper, 'azp'
+	// names the client the token was issued to
+	azp, _ := token.PrivateClaims()["azp"].(string)
+	if !slices.Contains(token.Audience(), ka.config.Audience) && azp != ka.config.Audience {
+		return nil, fmt.Errorf("%w: token is not issued for audience %s", ErrUnauthenticated, ka.config.Audience)
+	}
+	if token.Subject() == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+
+	username, _ := token.PrivateClaims()["preferred_username"].(string)
+	return &Claims{
+		UserId:   token.Subject(),
+		Username: username,
+		Roles:    keycloakRoles(token, ka.config.ClientId),
+	}, nil
+}
+
+// keycloakRoles collects the realm roles and the roles of the client from
+// the Keycloak role claims.
+func keycloakRoles(token jwt.Token, clientId string) []string {
+	roles := []string{}
+	claims := token.PrivateClaims()
+
+	if realmAccess, ok := claims["realm_access"].(map[string]any); ok {
+		roles = appendRoles(roles, realmAccess["roles"])
+	}
+	if resourceAccess, ok := claims["resource_access"].(map[string]any); ok {
+		if clientAccess, ok := resourceAccess[clientId].(map[string]any); ok {
+			roles = appendRoles(roles, clientAccess["roles"])
+		}
+	}
+
+	return roles
+}
+
+func appendRoles(roles []string, claim any) []string {
+	values, _ := claim.([]any)
+	for _, value := range values {
+		if role, ok := value.(string); ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// fetchUserInfo asks Keycloak for the user of the token, which also rejects
+// tokens of ended sessions, and returns the username.
+func (ka *KeycloakAuthenticator) fetchUserInfo(ctx context.Context, token, userId string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", ka.realmUrl+"/protocol/openid-connect/userinfo", nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	response, err := ka.do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("%w: keycloak rejected the token", ErrUnauthenticated)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: keycloak userInfo response error: got response with status %d", ErrBadGateway, response.StatusCode)
+	}
+
+	var userInfo struct {
+		UserId   string `json:"sub"`
+		Username string `json:"preferred_username"`
+	}
+	if err = json.NewDecoder(response.Body).Decode(&userInfo); err != nil {
+		return "", fmt.Errorf("%w: keycloak userInfo response encoding error: %v", ErrBadGateway, err)
+	}
+	if userInfo.UserId != userId {
+		return "", fmt.Errorf("%w: keycloak userInfo is for user %s, not %s", ErrUnauthenticated, userInfo.UserId, userId)
+	}
+
+	return userInfo.Username, nil
+}
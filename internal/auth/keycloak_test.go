@@ -0,0 +1,500 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+const (
+	testIssuer       = "http://keycloak/realms/tasks"
+	testClientId     = "api"
+	testClientSecret = "api-secret"
+	testPassword     = "secret"
+)
+
+// testRealm serves the public keys of a realm and signs tokens with them,
+// and stands in for the token and logout endpoints of Keycloak.
+type testRealm struct {
+	mu            sync.Mutex
+	keys          map[string]jwk.Key
+	served        []string
+	fetches       int
+	sessions      map[string]bool
+	issued        int
+	server        *httptest.Server
+	authenticator *KeycloakAuthenticator
+}
+
+func newTestRealm(t testing.TB, kids ...string) *testRealm {
+	t.Helper()
+
+	realm := &testRealm{keys: map[string]jwk.Key{}, sessions: map[string]bool{}}
+	for _, kid := range kids {
+		realm.addKey(t, kid)
+	}
+	realm.served = kids
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /realms/tasks/protocol/openid-connect/certs", func(w http.ResponseWriter, r *http.Request) {
+		realm.mu.Lock()
+		defer realm.mu.Unlock()
+
+		realm.fetches++
+		set := jwk.NewSet()
+		for _, kid := range realm.served {
+			public, err := realm.keys[kid].PublicKey()
+			if err != nil {
+				t.Errorf("public key error: %v", err)
+			}
+			set.Add(public)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("POST /realms/tasks/protocol/openid-connect/token", realm.serveToken)
+	mux.HandleFunc("POST /realms/tasks/protocol/openid-connect/logout", realm.serveLogout)
+	realm.server = httptest.NewServer(mux)
+	t.Cleanup(realm.server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	realm.authenticator = NewKeycloakAuthenticator(ctx, KeycloakConfig{
+		ServerUrl:          realm.server.URL,
+		Realm:              "tasks",
+		ClientId:           testClientId,
+		ClientSecret:       testClientSecret,
+		Issuer:             testIssuer,
+		Audience:           testClientId,
+		KeyRefreshInterval: time.Hour,
+	})
+
+	return realm
+}
+
+func (realm *testRealm) addKey(t testing.TB, kid string) {
+	t.Helper()
+
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey error: %v", err)
+	}
+	key, err := jwk.New(raw)
+	if err != nil {
+		t.Fatalf("jwk.New error: %v", err)
+	}
+	key.Set(jwk.KeyIDKey, kid)
+	key.Set(jwk.AlgorithmKey, jwa.RS256)
+	realm.keys[kid] = key
+}
+
+// rotate makes the realm serve only the key kid.
+func (realm *testRealm) rotate(t testing.TB, kid string) {
+	realm.mu.Lock()
+	defer realm.mu.Unlock()
+
+	realm.addKey(t, kid)
+	realm.served = []string{kid}
+}
+
+func (realm *testRealm) keyFetches() int {
+	realm.mu.Lock()
+	defer realm.mu.Unlock()
+
+	return realm.fetches
+}
+
+// serveToken grants the password of alice and the refresh tokens of open
+// sessions, a refresh hands out a new refresh token like Keycloak does.
+func (realm *testRealm) serveToken(w http.ResponseWriter, r *http.Request) {
+	realm.mu.Lock()
+	defer realm.mu.Unlock()
+
+	if r.PostFormValue("client_id") != testClientId || r.PostFormValue("client_secret") != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "password":
+		if r.PostFormValue("username") != "alice" || r.PostFormValue("password") != testPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	case "refresh_token":
+		refreshToken := r.PostFormValue("refresh_token")
+		if !realm.sessions[refreshToken] {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		delete(realm.sessions, refreshToken)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	realm.issued++
+	refreshToken := fmt.Sprintf("refresh-%d", realm.issued)
+	realm.sessions[refreshToken] = true
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token":       fmt.Sprintf("access-%d", realm.issued),
+		"token_type":         "Bearer",
+		"expires_in":         300,
+		"refresh_token":      refreshToken,
+		"refresh_expires_in": 1800,
+	})
+}
+
+func (realm *testRealm) serveLogout(w http.ResponseWriter, r *http.Request) {
+	realm.mu.Lock()
+	defer realm.mu.Unlock()
+
+	refreshToken := r.PostFormValue("refresh_token")
+	if r.PostFormValue("client_id") != testClientId || !realm.sessions[refreshToken] {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	delete(realm.sessions, refreshToken)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (realm *testRealm) sign(t testing.TB, kid string, claims map[string]any) string {
+	t.Helper()
+
+	token := jwt.New()
+	token.Set(jwt.IssuerKey, testIssuer)
+	token.Set(jwt.SubjectKey, "user-1")
+	token.Set(jwt.ExpirationKey, time.Now().Add(time.Minute))
+	token.Set("azp", testClientId)
+	token.Set("preferred_username", "alice")
+	for name, value := range claims {
+		token.Set(name, value)
+	}
+
+	realm.mu.Lock()
+	key := realm.keys[kid]
+	realm.mu.Unlock()
+
+	signed, err := jwt.Sign(token, jwa.RS256, key)
+	if err != nil {
+		t.Fatalf("jwt.Sign error: %v", err)
+	}
+	return string(signed)
+}
+
+func TestKeycloakAuthenticator(t *testing.T) {
+	t.Run("valid token gives its claims", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		token := realm.sign(t, "k1", map[string]any{
+			"realm_access":    map[string]any{"roles": []string{"user"}},
+			"resource_access": map[string]any{testClientId: map[string]any{"roles": []string{"admin", "user"}}},
+		})
+
+		request, _ := http.NewRequest("GET", "/tasks", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		claims, err := realm.authenticator.Authenticate(request)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if claims.UserId != "user-1" || claims.Username != "alice" {
+			t.Errorf("expected user-1 alice, but got %s %s", claims.UserId, claims.Username)
+		}
+		if !slices.Equal(claims.Roles, []string{"user", "admin"}) {
+			t.Errorf("expected roles [user admin], but got %v", claims.Roles)
+		}
+	})
+	t.Run("keys are fetched once", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+
+		for range 3 {
+			if _, err := realm.authenticator.verify(context.Background(), realm.sign(t, "k1", nil)); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+
+		if fetches := realm.keyFetches(); fetches != 1 {
+			t.Errorf("expected 1 key fetch, but got %d", fetches)
+		}
+	})
+	t.Run("rotated key is fetched on unknown kid", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		realm.authenticator.verify(context.Background(), realm.sign(t, "k1", nil))
+		realm.rotate(t, "k2")
+
+		_, err := realm.authenticator.verify(context.Background(), realm.sign(t, "k2", nil))
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if fetches := realm.keyFetches(); fetches != 2 {
+			t.Errorf("expected 2 key fetches, but got %d", fetches)
+		}
+	})
+	t.Run("invalid token fail", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		other := newTestRealm(t, "k1")
+		tokens := map[string]string{
+			"expired":        realm.sign(t, "k1", map[string]any{jwt.ExpirationKey: time.Now().Add(-time.Hour)}),
+			"wrong issuer":   realm.sign(t, "k1", map[string]any{jwt.IssuerKey: "http://other/realms/tasks"}),
+			"wrong audience": realm.sign(t, "k1", map[string]any{"azp": "other-client"}),
+			"wrong key":      other.sign(t, "k1", nil),
+			"malformed":      "not-a-token",
+		}
+
+		for name, token := range tokens {
+			if _, err := realm.authenticator.verify(context.Background(), token); !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("expected %s token to be unauthenticated, but got %v", name, err)
+			}
+		}
+	})
+}
+
+func TestKeycloakSession(t *testing.T) {
+	t.Run("login gives the token set", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+
+		token, err := realm.authenticator.Login(context.Background(), "alice", testPassword)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if token.AccessToken != "access-1" || token.RefreshToken != "refresh-1" {
+			t.Errorf("expected access-1 and refresh-1, but got %s and %s", token.AccessToken, token.RefreshToken)
+		}
+		if token.ExpiresIn != 300 || token.RefreshExpiresIn != 1800 || token.TokenType != "Bearer" {
+			t.Errorf("expected Bearer token expiring in 300 and 1800, but got %+v", token)
+		}
+	})
+	t.Run("login with wrong password fail", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+
+		_, err := realm.authenticator.Login(context.Background(), "alice", "wrong")
+
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, but got %v", err)
+		}
+	})
+	t.Run("refresh rotates the refresh token", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		login, _ := realm.authenticator.Login(context.Background(), "alice", testPassword)
+
+		token, err := realm.authenticator.Refresh(context.Background(), login.RefreshToken)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		_, reuseErr := realm.authenticator.Refresh(context.Background(), login.RefreshToken)
+
+		if token.AccessToken != "access-2" || token.RefreshToken != "refresh-2" {
+			t.Errorf("expected access-2 and refresh-2, but got %s and %s", token.AccessToken, token.RefreshToken)
+		}
+		if !errors.Is(reuseErr, ErrUnauthenticated) {
+			t.Errorf("expected used refresh token to be unauthenticated, but got %v", reuseErr)
+		}
+	})
+	t.Run("logout ends the session", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		login, _ := realm.authenticator.Login(context.Background(), "alice", testPassword)
+
+		err := realm.authenticator.Logout(context.Background(), login.RefreshToken)
+		_, refreshErr := realm.authenticator.Refresh(context.Background(), login.RefreshToken)
+		logoutErr := realm.authenticator.Logout(context.Background(), login.RefreshToken)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !errors.Is(refreshErr, ErrUnauthenticated) {
+			t.Errorf("expected refresh after logout to be unauthenticated, but got %v", refreshErr)
+		}
+		if !errors.Is(logoutErr, ErrUnauthenticated) {
+			t.Errorf("expected second logout to be unauthenticated, but got %v", logoutErr)
+		}
+	})
+	t.Run("keycloak failure is not a rejection", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		realm.server.Close()
+
+		_, err := realm.authenticator.Refresh(context.Background(), "refresh-1")
+
+		if err == nil || errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected a request error, but got %v", err)
+		}
+	})
+}
+
+// flakyServer stands in for a Keycloak that hangs until healthy is set, and
+// then answers every request with status.
+type flakyServer struct {
+	mu      sync.Mutex
+	healthy bool
+	status  int
+	hits    int
+	done    chan struct{}
+	server  *httptest.Server
+}
+
+func newFlakyServer(t testing.TB) *flakyServer {
+	t.Helper()
+
+	flaky := &flakyServer{status: http.StatusOK, done: make(chan struct{})}
+	flaky.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flaky.mu.Lock()
+		flaky.hits++
+		healthy, status := flaky.healthy, flaky.status
+		flaky.mu.Unlock()
+
+		if !healthy {
+			// the request is only cancelled once its body is read
+			io.Copy(io.Discard, r.Body)
+			select {
+			case <-r.Context().Done():
+			case <-flaky.done:
+			}
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"access_token": "access", "expires_in": 300}`))
+	}))
+	t.Cleanup(flaky.server.Close)
+	t.Cleanup(func() { close(flaky.done) })
+
+	return flaky
+}
+
+func (flaky *flakyServer) set(healthy bool, status int) {
+	flaky.mu.Lock()
+	defer flaky.mu.Unlock()
+
+	flaky.healthy, flaky.status = healthy, status
+}
+
+func (flaky *flakyServer) requests() int {
+	flaky.mu.Lock()
+	defer flaky.mu.Unlock()
+
+	return flaky.hits
+}
+
+func (flaky *flakyServer) authenticator(t testing.TB, cooldown time.Duration) *KeycloakAuthenticator {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return NewKeycloakAuthenticator(ctx, KeycloakConfig{
+		ServerUrl:          flaky.server.URL,
+		Realm:              "tasks",
+		ClientId:           testClientId,
+		Issuer:             testIssuer,
+		Audience:           testClientId,
+		KeyRefreshInterval: time.Hour,
+		Timeout:            50 * time.Millisecond,
+		BreakerFailures:    2,
+		BreakerCooldown:    cooldown,
+	})
+}
+
+func TestKeycloakOutage(t *testing.T) {
+	t.Run("timeout is unavailable", func(t *testing.T) {
+		flaky := newFlakyServer(t)
+		authenticator := flaky.authenticator(t, time.Minute)
+
+		_, err := authenticator.Login(context.Background(), "alice", testPassword)
+
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) {
+			t.Errorf("expected UnavailableError, but got %v", err)
+			return
+		}
+		if unavailable.RetryAfter < minRetryAfter {
+			t.Errorf("expected retry after at least %v, but got %v", minRetryAfter, unavailable.RetryAfter)
+		}
+	})
+	t.Run("breaker opens after failures in a row", func(t *testing.T) {
+		flaky := newFlakyServer(t)
+		authenticator := flaky.authenticator(t, time.Minute)
+		authenticator.Refresh(context.Background(), "refresh")
+		authenticator.Refresh(context.Background(), "refresh")
+
+		start := time.Now()
+		_, err := authenticator.Refresh(context.Background(), "refresh")
+
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) || !errors.Is(err, errBreakerOpen) {
+			t.Errorf("expected open breaker, but got %v", err)
+			return
+		}
+		if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+			t.Errorf("expected open breaker to fail fast, but took %v", elapsed)
+		}
+		if requests := flaky.requests(); requests != 2 {
+			t.Errorf("expected 2 requests to reach the server, but got %d", requests)
+		}
+		if unavailable.RetryAfter <= 50*time.Second {
+			t.Errorf("expected retry after the cooldown, but got %v", unavailable.RetryAfter)
+		}
+	})
+	t.Run("breaker closes when the probe succeeds", func(t *testing.T) {
+		flaky := newFlakyServer(t)
+		authenticator := flaky.authenticator(t, 20*time.Millisecond)
+		authenticator.Login(context.Background(), "alice", testPassword)
+		authenticator.Login(context.Background(), "alice", testPassword)
+		flaky.set(true, http.StatusOK)
+		time.Sleep(30 * time.Millisecond)
+
+		probe, probeErr := authenticator.Login(context.Background(), "alice", testPassword)
+		_, err := authenticator.Login(context.Background(), "alice", testPassword)
+
+		if probeErr != nil || err != nil {
+			t.Errorf("unexpected errors: %v, %v", probeErr, err)
+			return
+		}
+		if probe.AccessToken != "access" {
+			t.Errorf("expected access token, but got %s", probe.AccessToken)
+		}
+	})
+	t.Run("server error is a bad gateway", func(t *testing.T) {
+		flaky := newFlakyServer(t)
+		flaky.set(true, http.StatusInternalServerError)
+		authenticator := flaky.authenticator(t, time.Minute)
+
+		_, err := authenticator.Login(context.Background(), "alice", testPassword)
+
+		if !errors.Is(err, ErrBadGateway) {
+			t.Errorf("expected ErrBadGateway, but got %v", err)
+		}
+	})
+	t.Run("unreachable keys are unavailable", func(t *testing.T) {
+		realm := newTestRealm(t, "k1")
+		token := realm.sign(t, "k1", nil)
+		flaky := newFlakyServer(t)
+		authenticator := flaky.authenticator(t, time.Minute)
+		request, _ := http.NewRequest("GET", "/tasks", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+
+		_, err := authenticator.Authenticate(request)
+
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) {
+			t.Errorf("expected UnavailableError, but got %v", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/* Mock up of an authenticator for tests */
+
+// MockAuthenticator accepts the bearer tokens it knows, as the user the
+// token is mapped to. When Err is set, every call fails with it.
+type MockAuthenticator struct {
+	Tokens map[string]Claims
+	Err    error
+}
+
+func (ma *MockAuthenticator) Authenticate(request *http.Request) (*Claims, error) {
+	if ma.Err != nil {
+		return nil, ma.Err
+	}
+
+	token, err := bearerToken(request)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := ma.Tokens[token]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown token", ErrUnauthenticated)
+	}
+	return &claims, nil
+}
+
+// Login hands out the token of the user named username, any password goes.
+func (ma *MockAuthenticator) Login(ctx context.Context, username, password string) (*Token, error) {
+	if ma.Err != nil {
+		return nil, ma.Err
+	}
+
+	for token, claims := range ma.Tokens {
+		if claims.Username == username {
+			return &Token{AccessToken: token, TokenType: "Bearer", RefreshToken: mockRefreshPrefix + token}, nil
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// mockRefreshPrefix makes the refresh token of an access token.
+const mockRefreshPrefix = "refresh-"
+
+// Refresh hands out the access token again if the refresh token is of a
+// known token.
+func (ma *MockAuthenticator) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	token, ok := ma.accessToken(refreshToken)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown refresh token", ErrUnauthenticated)
+	}
+	return &Token{AccessToken: token, TokenType: "Bearer", RefreshToken: refreshToken}, nil
+}
+
+func (ma *MockAuthenticator) Logout(ctx context.Context, refreshToken string) error {
+	if _, ok := ma.accessToken(refreshToken); !ok {
+		return fmt.Errorf("%w: unknown refresh token", ErrUnauthenticated)
+	}
+	return nil
+}
+
+func (ma *MockAuthenticator) accessToken(refreshToken string) (string, bool) {
+	if ma.Err != nil {
+		return "", false
+	}
+
+	token, ok := strings.CutPrefix(refreshToken, mockRefreshPrefix)
+	if !ok {
+		return "", false
+	}
+	_, ok = ma.Tokens[token]
+	return token, ok
+}
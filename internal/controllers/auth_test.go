@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	httpController "github.com/Arup3201/gotasks/internal/controllers/http"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// secured endpoints need a token the authenticator accepts
+func TestRequestWithoutValidTokenFail(t *testing.T) {
+	// act
+	invalidResponse := makeRequestWithHeaders("GET", "/tasks", nil, map[string]string{"Authorization": "Bearer unknown-token"})
+	missingResponse := makeRequestWithHeaders("GET", "/tags", nil, map[string]string{"Authorization": ""})
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, invalidResponse.Code)
+	assert.Equal(t, http.StatusUnauthorized, missingResponse.Code)
+	assert.Equal(t, "Bearer token68", invalidResponse.Header().Get("WWW-Authenticate"))
+}
+
+// login hands out the token of the user from the authenticator
+func TestLoginSuccess(t *testing.T) {
+	// act
+	response := makeRequest("POST", "/login", map[string]string{"username": "tester", "password": "any"})
+	wrongResponse := makeRequest("POST", "/login", map[string]string{"username": "unknown-user", "password": "any"})
+
+	// assert
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, http.StatusBadRequest, wrongResponse.Code)
+
+	var token auth.Token
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, testToken, token.AccessToken)
+	assert.NotEmpty(t, token.RefreshToken)
+}
+
+// refresh hands out a new token set for the refresh token of a login
+func TestRefreshTokenSuccess(t *testing.T) {
+	// prepare
+	login := makeRequest("POST", "/login", map[string]string{"username": "tester", "password": "any"})
+	var loginToken auth.Token
+	if err := json.NewDecoder(login.Body).Decode(&loginToken); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	// act
+	response := makeRequest("POST", "/token/refresh", map[string]string{"refresh_token": loginToken.RefreshToken})
+	invalidResponse := makeRequest("POST", "/token/refresh", map[string]string{"refresh_token": "unknown-refresh-token"})
+	missingResponse := makeRequest("POST", "/token/refresh", map[string]string{})
+
+	// assert
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, http.StatusUnauthorized, invalidResponse.Code)
+	assert.Equal(t, http.StatusBadRequest, missingResponse.Code)
+
+	var token auth.Token
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, testToken, token.AccessToken)
+}
+
+// logout ends the session of a refresh token
+func TestLogoutSuccess(t *testing.T) {
+	// prepare
+	login := makeRequest("POST", "/login", map[string]string{"username": "tester", "password": "any"})
+	var loginToken auth.Token
+	if err := json.NewDecoder(login.Body).Decode(&loginToken); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	// act
+	response := makeRequest("POST", "/logout", map[string]string{"refresh_token": loginToken.RefreshToken})
+	invalidResponse := makeRequest("POST", "/logout", map[string]string{"refresh_token": "unknown-refresh-token"})
+
+	// assert
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	assert.Equal(t, http.StatusUnauthorized, invalidResponse.Code)
+}
+
+// /me gives the claims of the caller
+func TestMeSuccess(t *testing.T) {
+	// act
+	response := makeRequest("GET", "/me", nil)
+	missingResponse := makeRequestWithHeaders("GET", "/me", nil, map[string]string{"Authorization": ""})
+
+	// assert
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, http.StatusUnauthorized, missingResponse.Code)
+
+	var me httpController.Me
+	if err := json.NewDecoder(response.Body).Decode(&me); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, ownerId, me.UserId)
+	assert.Equal(t, "tester", me.Username)
+	assert.Equal(t, []string{auth.RoleRead, auth.RoleWrite}, me.Roles)
+}
+
+// routes need the role they are registered with
+func TestRequestWithoutRoleFail(t *testing.T) {
+	// prepare
+	reader := map[string]string{"Authorization": "Bearer " + readerToken}
+	noRole := map[string]string{"Authorization": "Bearer " + noRoleToken}
+
+	// act
+	readResponse := makeRequestWithHeaders("GET", "/tasks", nil, reader)
+	writeResponse := makeRequestWithHeaders("POST", "/tasks", map[string]string{"title": "Title", "description": "Description"}, reader)
+	noRoleResponse := makeRequestWithHeaders("GET", "/tasks", nil, noRole)
+
+	// assert
+	assert.Equal(t, http.StatusOK, readResponse.Code)
+	assert.Equal(t, http.StatusForbidden, writeResponse.Code)
+	assert.Equal(t, http.StatusForbidden, noRoleResponse.Code)
+
+	var responseBody httperrors.HttpError
+	if err := json.NewDecoder(writeResponse.Body).Decode(&responseBody); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, httperrors.FORBIDDEN, responseBody.Id)
+	assert.Equal(t, "403-01", responseBody.Code)
+}
+
+// admins read the tasks of other owners with the 'owner' param, other users can't
+func TestAdminReadOtherOwnerSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(2)
+	admin := map[string]string{"Authorization": "Bearer " + adminToken}
+	reader := map[string]string{"Authorization": "Bearer " + readerToken}
+
+	// act
+	ownResponse := makeRequestWithHeaders("GET", "/tasks", nil, admin)
+	listResponse := makeRequestWithHeaders("GET", "/tasks?owner="+ownerId, nil, admin)
+	getResponse := makeRequestWithHeaders("GET", fmt.Sprintf("/tasks/%s?owner=%s", tasks[0].Id, ownerId), nil, admin)
+	readerResponse := makeRequestWithHeaders("GET", "/tasks?owner="+ownerId, nil, reader)
+
+	// assert
+	assert.Equal(t, http.StatusOK, listResponse.Code)
+	assert.Equal(t, http.StatusOK, getResponse.Code)
+	assert.Equal(t, http.StatusForbidden, readerResponse.Code)
+
+	var own, other services.TaskPage
+	if err := json.NewDecoder(ownResponse.Body).Decode(&own); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	if err := json.NewDecoder(listResponse.Body).Decode(&other); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Len(t, own.Tasks, 0)
+	assert.Len(t, other.Tasks, 2)
+	cleanDB()
+}
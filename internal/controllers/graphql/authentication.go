@@ -0,0 +1,50 @@
+package graphqlController
+
+import (
+	"context"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+type claimsKey struct{}
+
+// operationRoles is the role each operation needs, a query reads tasks like
+// the GET routes of the HTTP API and a mutation writes them.
+var operationRoles = map[string]string{
+	ast.OperationTypeQuery:    auth.RoleRead,
+	ast.OperationTypeMutation: auth.RoleWrite,
+}
+
+// WithClaims returns ctx with the claims of the caller, the HTTP API sets
+// them after authenticating the request.
+func WithClaims(ctx context.Context, claims *auth.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func callerClaims(ctx context.Context) *auth.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*auth.Claims)
+	if claims == nil {
+		return &auth.Claims{}
+	}
+	return claims
+}
+
+// callerId is the user the request was authenticated as.
+func callerId(ctx context.Context) string {
+	return callerClaims(ctx).UserId
+}
+
+// readOwner is the owner whose tasks a query is about, the caller itself
+// unless an admin names another owner.
+func readOwner(ctx context.Context, owner string) (string, error) {
+	claims := callerClaims(ctx)
+	if owner == "" || owner == claims.UserId {
+		return claims.UserId, nil
+	}
+	if !claims.HasRole(auth.RoleAdmin) {
+		return "", fromHttpError(httperrors.ForbiddenError())
+	}
+	return owner, nil
+}
@@ -0,0 +1,71 @@
+package graphqlController
+
+import (
+	"log"
+	"net/http"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/location"
+)
+
+// resolverError is an error response of the HTTP API reported in the errors
+// of a GraphQL response, its code, status and fields are the extensions of
+// the error.
+type resolverError struct {
+	httpError *httperrors.HttpError
+}
+
+func (e *resolverError) Error() string {
+	return e.httpError.Detail
+}
+
+func (e *resolverError) Extensions() map[string]any {
+	extensions := map[string]any{
+		"code":   e.httpError.Id,
+		"status": e.httpError.Status,
+	}
+	if len(e.httpError.Errors) > 0 {
+		extensions["fields"] = e.httpError.Errors
+	}
+	return extensions
+}
+
+// queryError maps an error of the service handler for a query, invalid
+// input is reported against the arguments like the params of the HTTP API.
+func queryError(err error) error {
+	appError, ok := err.(*errors.AppError)
+	if ok {
+		return fromHttpError(httperrors.FromAppParamError(appError))
+	}
+	return fromHttpError(httperrors.InternalServerError(err))
+}
+
+// mutationError maps an error of the service handler for a mutation, the
+// input of a mutation is the body of the HTTP API.
+func mutationError(err error) error {
+	appError, ok := err.(*errors.AppError)
+	if ok {
+		return fromHttpError(httperrors.FromAppError(appError))
+	}
+	return fromHttpError(httperrors.InternalServerError(err))
+}
+
+func fromHttpError(httpError *httperrors.HttpError) error {
+	if httpError.Status >= http.StatusInternalServerError {
+		log.Printf("Internal server error: %v", httpError)
+	}
+	return &resolverError{httpError}
+}
+
+// requestError is the error of a request that is refused before any field
+// is resolved.
+func requestError(httpError *httperrors.HttpError) []gqlerrors.FormattedError {
+	err := &resolverError{httpError}
+	return []gqlerrors.FormattedError{{
+		Message:    err.Error(),
+		Locations:  []location.SourceLocation{},
+		Extensions: err.Extensions(),
+	}}
+}
@@ -0,0 +1,105 @@
+package graphqlController
+
+import (
+	"context"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/Arup3201/gotasks/internal/utils"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Request is a GraphQL request, in the body of a POST or the params of a GET.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	// ReadOnly refuses mutations, a GET must not change anything.
+	ReadOnly bool `json:"-"`
+}
+
+type Executor struct {
+	schema        graphql.Schema
+	maxDepth      int
+	maxComplexity int
+}
+
+// NewExecutor returns an executor of the queries and mutations of the
+// schema, resolved through serviceHandler.
+func NewExecutor(serviceHandler services.ServiceHandler) (*Executor, error) {
+	schema, err := newSchema(&resolver{serviceHandler: serviceHandler})
+	if err != nil {
+		return nil, err
+	}
+	return &Executor{
+		schema:        schema,
+		maxDepth:      utils.Config.GraphQLMaxDepth,
+		maxComplexity: utils.Config.GraphQLMaxComplexity,
+	}, nil
+}
+
+// Execute runs the operation of a request as the caller of ctx, set with
+// WithClaims. An operation is refused as a whole when the caller lacks its
+// role or it is over the limits, the errors of the fields it resolves are
+// reported next to the data of the others.
+func (executor *Executor) Execute(ctx context.Context, request Request) *graphql.Result {
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	validation := graphql.ValidateDocument(&executor.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	// an unknown operation is reported by graphql.Execute
+	if operation := findOperation(document, request.OperationName); operation != nil {
+		if request.ReadOnly && operation.Operation != ast.OperationTypeQuery {
+			return &graphql.Result{Errors: requestError(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "query",
+				Reason: "A mutation has to be sent with POST",
+			}))}
+		}
+		role, ok := operationRoles[operation.Operation]
+		if !ok || !callerClaims(ctx).HasRole(role) {
+			return &graphql.Result{Errors: requestError(httperrors.ForbiddenError())}
+		}
+
+		limiter := newLimiter(&executor.schema, document, operation, request.Variables)
+		if httpError := limiter.check(operation, executor.maxDepth, executor.maxComplexity); httpError != nil {
+			return &graphql.Result{Errors: requestError(httpError)}
+		}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        executor.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+}
+
+// findOperation is the operation of a document a request runs, the one it
+// names or the only one of the document.
+func findOperation(document *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
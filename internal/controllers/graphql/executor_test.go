@@ -0,0 +1,266 @@
+package graphqlController
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	"github.com/Arup3201/gotasks/internal/services/domain/task"
+	"github.com/Arup3201/gotasks/internal/storages"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	writer = &auth.Claims{UserId: "test-user", Roles: []string{auth.RoleRead, auth.RoleWrite}}
+	reader = &auth.Claims{UserId: "reader", Roles: []string{auth.RoleRead}}
+	admin  = &auth.Claims{UserId: "admin", Roles: []string{auth.RoleAdmin}}
+)
+
+// response is a GraphQL response read back from its JSON.
+type response struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// newTestExecutor resolves through a fresh in-memory service.
+func newTestExecutor(t *testing.T) *Executor {
+	t.Helper()
+
+	storage, err := storages.New(storages.InMemory)
+	if err != nil {
+		t.Fatalf("storage create error: %v", err)
+	}
+	serviceHandler, err := task.NewTaskService(storage)
+	if err != nil {
+		t.Fatalf("service create error: %v", err)
+	}
+	executor, err := NewExecutor(serviceHandler)
+	if err != nil {
+		t.Fatalf("executor create error: %v", err)
+	}
+	executor.maxDepth = 10
+	executor.maxComplexity = 2000
+	return executor
+}
+
+func execute(t *testing.T, executor *Executor, claims *auth.Claims, request Request) response {
+	t.Helper()
+
+	result := executor.Execute(WithClaims(context.Background(), claims), request)
+	body, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("result marshal error: %v", err)
+	}
+	var decoded response
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("result unmarshal error: %v", err)
+	}
+	return decoded
+}
+
+// createTask returns the ID of a new task of the writer.
+func createTask(t *testing.T, executor *Executor, input map[string]any) string {
+	t.Helper()
+
+	result := execute(t, executor, writer, Request{
+		Query:     `mutation($input: CreateTaskInput!) { createTask(input: $input) { id } }`,
+		Variables: map[string]any{"input": input},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("createTask failed: %v", result.Errors)
+	}
+	return result.Data["createTask"].(map[string]any)["id"].(string)
+}
+
+func TestGraphQLTasks(t *testing.T) {
+	t.Run("resolves the subresources of a task in one query", func(t *testing.T) {
+		executor := newTestExecutor(t)
+		parentId := createTask(t, executor, map[string]any{"title": "Parent", "description": "Parent task"})
+		childId := createTask(t, executor, map[string]any{"title": "Child", "description": "Child task", "parentId": parentId})
+
+		result := execute(t, executor, writer, Request{
+			Query: `query($id: ID!) {
+				task(id: $id) {
+					title
+					subtasks { tasks { id parent { id } } nextCursor }
+					blockers { id }
+					history { events { action } }
+				}
+			}`,
+			Variables: map[string]any{"id": parentId},
+		})
+
+		assert.Empty(t, result.Errors)
+		parent := result.Data["task"].(map[string]any)
+		assert.Equal(t, "Parent", parent["title"])
+		subtasks := parent["subtasks"].(map[string]any)["tasks"].([]any)
+		if assert.Len(t, subtasks, 1) {
+			child := subtasks[0].(map[string]any)
+			assert.Equal(t, childId, child["id"])
+			assert.Equal(t, parentId, child["parent"].(map[string]any)["id"])
+		}
+		assert.Empty(t, parent["blockers"])
+		assert.NotEmpty(t, parent["history"].(map[string]any)["events"])
+	})
+	t.Run("lists and searches tasks with filters", func(t *testing.T) {
+		executor := newTestExecutor(t)
+		createTask(t, executor, map[string]any{"title": "Buy milk", "description": "From the store"})
+		doneId := createTask(t, executor, map[string]any{"title": "Write report", "description": "Quarterly", "status": "done"})
+
+		result := execute(t, executor, writer, Request{
+			Query: `{
+				tasks(isCompleted: true) { tasks { id isCompleted } }
+				search(query: "milk") { results { task { title } highlight } }
+			}`,
+		})
+
+		assert.Empty(t, result.Errors)
+		tasks := result.Data["tasks"].(map[string]any)["tasks"].([]any)
+		if assert.Len(t, tasks, 1) {
+			assert.Equal(t, doneId, tasks[0].(map[string]any)["id"])
+		}
+		results := result.Data["search"].(map[string]any)["results"].([]any)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, "Buy milk", results[0].(map[string]any)["task"].(map[string]any)["title"])
+		}
+	})
+	t.Run("updates and deletes a task", func(t *testing.T) {
+		executor := newTestExecutor(t)
+		taskId := createTask(t, executor, map[string]any{"title": "Title", "description": "Description"})
+
+		update := execute(t, executor, writer, Request{
+			Query:     `mutation($id: ID!) { updateTask(id: $id, version: 1, input: {status: "done"}) { status isCompleted version } }`,
+			Variables: map[string]any{"id": taskId},
+		})
+		remove := execute(t, executor, writer, Request{
+			Query:     `mutation($id: ID!) { deleteTask(id: $id) }`,
+			Variables: map[string]any{"id": taskId},
+		})
+		get := execute(t, executor, writer, Request{
+			Query:     `query($id: ID!) { task(id: $id) { id } }`,
+			Variables: map[string]any{"id": taskId},
+		})
+
+		assert.Empty(t, update.Errors)
+		assert.Equal(t, map[string]any{"status": "done", "isCompleted": true, "version": float64(2)}, update.Data["updateTask"])
+		assert.Empty(t, remove.Errors)
+		assert.Equal(t, taskId, remove.Data["deleteTask"])
+		assert.Nil(t, get.Data["task"])
+		if assert.Len(t, get.Errors, 1) {
+			assert.Equal(t, "NOT_FOUND", get.Errors[0].Extensions["code"])
+			assert.Equal(t, float64(404), get.Errors[0].Extensions["status"])
+			assert.Equal(t, []any{"task"}, get.Errors[0].Path)
+		}
+	})
+	t.Run("clears the due date of a task", func(t *testing.T) {
+		executor := newTestExecutor(t)
+		taskId := createTask(t, executor, map[string]any{"title": "Title", "description": "Description", "dueAt": "2030-01-02T15:04:05Z"})
+
+		update := execute(t, executor, writer, Request{
+			Query:     `mutation($id: ID!) { updateTask(id: $id, input: {clearDueAt: true}) { dueAt } }`,
+			Variables: map[string]any{"id": taskId},
+		})
+
+		assert.Empty(t, update.Errors)
+		assert.Equal(t, map[string]any{"dueAt": nil}, update.Data["updateTask"])
+	})
+	t.Run("maps invalid input to the fields of the error", func(t *testing.T) {
+		executor := newTestExecutor(t)
+		taskId := createTask(t, executor, map[string]any{"title": "Title", "description": "Description"})
+
+		create := execute(t, executor, writer, Request{
+			Query: `mutation { createTask(input: {title: "Title", description: "Description", priority: "critical"}) { id } }`,
+		})
+		stale := execute(t, executor, writer, Request{
+			Query:     `mutation($id: ID!) { updateTask(id: $id, version: 7, input: {title: "New"}) { id } }`,
+			Variables: map[string]any{"id": taskId},
+		})
+		list := execute(t, executor, writer, Request{
+			Query: `{ tasks(limit: 500) { nextCursor } }`,
+		})
+
+		if assert.Len(t, create.Errors, 1) {
+			assert.Equal(t, "INVALID_BODY_PROPERTY", create.Errors[0].Extensions["code"])
+			assert.Equal(t, "priority", create.Errors[0].Extensions["fields"].([]any)[0].(map[string]any)["field"])
+		}
+		if assert.Len(t, stale.Errors, 1) {
+			assert.Equal(t, "PRECONDITION_FAILED", stale.Errors[0].Extensions["code"])
+		}
+		if assert.Len(t, list.Errors, 1) {
+			assert.Equal(t, "INVALID_PARAMETER_VALUE", list.Errors[0].Extensions["code"])
+		}
+	})
+}
+
+func TestGraphQLAuthorization(t *testing.T) {
+	executor := newTestExecutor(t)
+	taskId := createTask(t, executor, map[string]any{"title": "Title", "description": "Description"})
+
+	mutation := execute(t, executor, reader, Request{
+		Query: `mutation { createTask(input: {title: "Title", description: "Description"}) { id } }`,
+	})
+	owner := execute(t, executor, reader, Request{
+		Query: `{ tasks(owner: "test-user") { tasks { id } } }`,
+	})
+	readOnly := execute(t, executor, writer, Request{
+		Query:    `mutation { createTask(input: {title: "Title", description: "Description"}) { id } }`,
+		ReadOnly: true,
+	})
+	asAdmin := execute(t, executor, admin, Request{
+		Query:     `query($id: ID!) { task(id: $id, owner: "test-user") { id } }`,
+		Variables: map[string]any{"id": taskId},
+	})
+
+	if assert.Len(t, mutation.Errors, 1) {
+		assert.Equal(t, "FORBIDDEN", mutation.Errors[0].Extensions["code"])
+	}
+	assert.Nil(t, mutation.Data)
+	if assert.Len(t, owner.Errors, 1) {
+		assert.Equal(t, "FORBIDDEN", owner.Errors[0].Extensions["code"])
+	}
+	if assert.Len(t, readOnly.Errors, 1) {
+		assert.Equal(t, "INVALID_PARAMETER_VALUE", readOnly.Errors[0].Extensions["code"])
+	}
+	assert.Empty(t, asAdmin.Errors)
+	assert.Equal(t, taskId, asAdmin.Data["task"].(map[string]any)["id"])
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	executor := newTestExecutor(t)
+	executor.maxDepth = 5
+	executor.maxComplexity = 500
+
+	deep := execute(t, executor, writer, Request{
+		Query: `{ tasks(limit: 1) { tasks { parent { parent { parent { id } } } } } }`,
+	})
+	complex := execute(t, executor, writer, Request{
+		Query: `{ tasks(limit: 50) { tasks { id subtasks { tasks { id } } } } }`,
+	})
+	variable := execute(t, executor, writer, Request{
+		Query:     `query($limit: Int) { tasks(limit: $limit) { tasks { id blockers { id title } } } }`,
+		Variables: map[string]any{"limit": float64(100)},
+	})
+	fragment := execute(t, executor, writer, Request{
+		Query: `{ tasks { ...page } }
+			fragment page on TaskPage { tasks { subtasks { tasks { parent { id } } } } }`,
+	})
+	allowed := execute(t, executor, writer, Request{
+		Query: `{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } }
+			tasks(limit: 5) { tasks { id title blockers { id } } } }`,
+	})
+
+	for _, refused := range []response{deep, complex, variable, fragment} {
+		assert.Nil(t, refused.Data)
+		if assert.Len(t, refused.Errors, 1) {
+			assert.Equal(t, "QUERY_LIMIT_EXCEEDED", refused.Errors[0].Extensions["code"])
+			assert.Equal(t, float64(400), refused.Errors[0].Extensions["status"])
+		}
+	}
+	assert.Empty(t, allowed.Errors)
+	assert.NotNil(t, allowed.Data["tasks"])
+}
@@ -0,0 +1,189 @@
+package graphqlController
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultLimit is the size of a page whose limit is not set, the default
+// page of the service.
+const defaultLimit = 20
+
+// unpagedLists return every task they hold, they cost as much as a page
+// with the default limit.
+var unpagedLists = map[string]bool{
+	"Task.blockers": true,
+	"Task.blocking": true,
+}
+
+// limiter measures an operation before it runs. Its depth is how deeply the
+// fields are nested, and its complexity how many fields it resolves: every
+// field costs 1, and the selection of a list costs once per item the list
+// may hold.
+type limiter struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+func newLimiter(schema *graphql.Schema, document *ast.Document, operation *ast.OperationDefinition, variables map[string]any) *limiter {
+	l := &limiter{
+		schema:    schema,
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: map[string]any{},
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			l.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			l.variables[definition.Variable.Name.Value] = definition.DefaultValue.GetValue()
+		}
+	}
+	for name, value := range variables {
+		l.variables[name] = value
+	}
+	return l
+}
+
+// check refuses an operation that is deeper or more complex than allowed.
+func (l *limiter) check(operation *ast.OperationDefinition, maxDepth, maxComplexity int) *httperrors.HttpError {
+	root := l.schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = l.schema.MutationType()
+	}
+
+	depth, complexity := l.measure(operation.SelectionSet, root)
+	if depth > maxDepth {
+		return httperrors.QueryLimitError(httperrors.ErrorField{
+			Field:  "query",
+			Reason: fmt.Sprintf("The query nests %d fields deep, at most %d are allowed", depth, maxDepth),
+		})
+	}
+	if complexity > maxComplexity {
+		return httperrors.QueryLimitError(httperrors.ErrorField{
+			Field:  "query",
+			Reason: fmt.Sprintf("The query may resolve %d fields, at most %d are allowed", complexity, maxComplexity),
+		})
+	}
+	return nil
+}
+
+// measure returns the depth and the complexity of a selection on parent. The
+// introspection fields are left out, their size is bound by the schema.
+func (l *limiter) measure(selectionSet *ast.SelectionSet, parent *graphql.Object) (int, int) {
+	if selectionSet == nil || parent == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	for _, selection := range selectionSet.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			field, ok := parent.Fields()[name]
+			if !ok {
+				continue
+			}
+			childDepth, childComplexity := l.measure(selection.SelectionSet, objectOf(field.Type))
+			depth = max(depth, childDepth+1)
+			complexity += 1 + l.size(parent, field, selection)*childComplexity
+		case *ast.InlineFragment:
+			on := parent
+			if selection.TypeCondition != nil {
+				on, _ = l.schema.Type(selection.TypeCondition.Name.Value).(*graphql.Object)
+			}
+			childDepth, childComplexity := l.measure(selection.SelectionSet, on)
+			depth = max(depth, childDepth)
+			complexity += childComplexity
+		case *ast.FragmentSpread:
+			// fragments can't spread themselves, the validation refuses
+			// cycles before the limits are checked
+			fragment, ok := l.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			on, _ := l.schema.Type(fragment.TypeCondition.Name.Value).(*graphql.Object)
+			childDepth, childComplexity := l.measure(fragment.SelectionSet, on)
+			depth = max(depth, childDepth)
+			complexity += childComplexity
+		}
+	}
+	return depth, complexity
+}
+
+// size is how many items field may resolve, the limit of a page and 1 for a
+// field that is not a list.
+func (l *limiter) size(parent *graphql.Object, field *graphql.FieldDefinition, selection *ast.Field) int {
+	if unpagedLists[parent.Name()+"."+field.Name] {
+		return defaultLimit
+	}
+
+	paged := false
+	for _, argument := range field.Args {
+		if argument.Name() == "limit" {
+			paged = true
+		}
+	}
+	if !paged {
+		return 1
+	}
+
+	for _, argument := range selection.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		if limit := l.intValue(argument.Value); limit > 0 {
+			return limit
+		}
+	}
+	return defaultLimit
+}
+
+// intValue is the value of an Int literal or variable, 0 when it is not set.
+func (l *limiter) intValue(value ast.Value) int {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		parsed, _ := strconv.Atoi(value.Value)
+		return parsed
+	case *ast.Variable:
+		switch variable := l.variables[value.Name.Value].(type) {
+		case int:
+			return variable
+		case float64:
+			return int(variable)
+		case string:
+			// default values of the operation are kept as their literal
+			parsed, _ := strconv.Atoi(variable)
+			return parsed
+		}
+	}
+	return 0
+}
+
+// objectOf is the object a field resolves to, through lists and non-nulls,
+// nil for a scalar.
+func objectOf(typ graphql.Type) *graphql.Object {
+	for {
+		switch wrapped := typ.(type) {
+		case *graphql.NonNull:
+			typ = wrapped.OfType
+		case *graphql.List:
+			typ = wrapped.OfType
+		case *graphql.Object:
+			return wrapped
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package graphqlController
+
+import (
+	"time"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/graphql-go/graphql"
+)
+
+type resolver struct {
+	serviceHandler services.ServiceHandler
+}
+
+// writer is the service handler for the writes of a mutation, they are
+// recorded as made by the caller.
+func (r *resolver) writer(p graphql.ResolveParams) services.ServiceHandler {
+	return r.serviceHandler.WithActor(callerId(p.Context))
+}
+
+// sourceTask is the task whose field is resolved, tasks of a list are values
+// and the others pointers.
+func sourceTask(source any) *task.Task {
+	switch source := source.(type) {
+	case *task.Task:
+		return source
+	case task.Task:
+		return &source
+	}
+	return nil
+}
+
+func stringArg(args map[string]any, name string) *string {
+	value, ok := args[name].(string)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func boolArg(args map[string]any, name string) *bool {
+	value, ok := args[name].(bool)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func timeArg(args map[string]any, name string) *time.Time {
+	value, ok := args[name].(time.Time)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// version is the expected version of a write, nil when it is not checked.
+func version(args map[string]any) *int {
+	value, ok := args["version"].(int)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// listQuery reads the filters shared by the task listings.
+func listQuery(args map[string]any) services.ListTasksQuery {
+	query := services.ListTasksQuery{
+		IsCompleted:  boolArg(args, "isCompleted"),
+		CreatedAfter: timeArg(args, "createdAfter"),
+	}
+	query.Limit, _ = args["limit"].(int)
+	query.Cursor, _ = args["cursor"].(string)
+	query.SortBy, _ = args["sort"].(string)
+	query.Order, _ = args["order"].(string)
+	query.TagMatch, _ = args["tagMatch"].(string)
+	if tags, ok := args["tags"].([]any); ok {
+		query.Tags = []string{}
+		for _, tag := range tags {
+			query.Tags = append(query.Tags, tag.(string))
+		}
+	}
+	return query
+}
+
+func (r *resolver) task(p graphql.ResolveParams) (any, error) {
+	owner, _ := p.Args["owner"].(string)
+	ownerId, err := readOwner(p.Context, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := r.serviceHandler.GetTask(ownerId, p.Args["id"].(string))
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return task, nil
+}
+
+func (r *resolver) tasks(p graphql.ResolveParams) (any, error) {
+	owner, _ := p.Args["owner"].(string)
+	ownerId, err := readOwner(p.Context, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := r.serviceHandler.GetAllTasks(ownerId, listQuery(p.Args))
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return page, nil
+}
+
+func (r *resolver) search(p graphql.ResolveParams) (any, error) {
+	owner, _ := p.Args["owner"].(string)
+	ownerId, err := readOwner(p.Context, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	query := services.SearchTasksQuery{Query: p.Args["query"].(string)}
+	query.Limit, _ = p.Args["limit"].(int)
+	query.Cursor, _ = p.Args["cursor"].(string)
+	page, err := r.serviceHandler.SearchTasks(ownerId, query)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return page, nil
+}
+
+func (r *resolver) searchResults(p graphql.ResolveParams) (any, error) {
+	return p.Source.(*services.SearchPage).Tasks, nil
+}
+
+func (r *resolver) tags(p graphql.ResolveParams) (any, error) {
+	tags := sourceTask(p.Source).Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+func (r *resolver) changes(p graphql.ResolveParams) (any, error) {
+	changes := p.Source.(task.Event).Changes
+	if changes == nil {
+		changes = []task.Change{}
+	}
+	return changes, nil
+}
+
+// The subresources of a task are read as its owner, the caller was allowed
+// to read the task itself.
+
+func (r *resolver) parent(p graphql.ResolveParams) (any, error) {
+	source := sourceTask(p.Source)
+	if source.ParentId == nil {
+		return nil, nil
+	}
+
+	parent, err := r.serviceHandler.GetTask(source.OwnerId, *source.ParentId)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return parent, nil
+}
+
+func (r *resolver) subtasks(p graphql.ResolveParams) (any, error) {
+	source := sourceTask(p.Source)
+
+	page, err := r.serviceHandler.GetSubtasks(source.OwnerId, source.Id, listQuery(p.Args))
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return page, nil
+}
+
+func (r *resolver) blockers(p graphql.ResolveParams) (any, error) {
+	source := sourceTask(p.Source)
+
+	list, err := r.serviceHandler.GetBlockers(source.OwnerId, source.Id)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return list.Tasks, nil
+}
+
+func (r *resolver) blocking(p graphql.ResolveParams) (any, error) {
+	source := sourceTask(p.Source)
+
+	list, err := r.serviceHandler.GetBlocking(source.OwnerId, source.Id)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return list.Tasks, nil
+}
+
+func (r *resolver) history(p graphql.ResolveParams) (any, error) {
+	source := sourceTask(p.Source)
+
+	query := services.EventsQuery{}
+	query.Limit, _ = p.Args["limit"].(int)
+	query.Cursor, _ = p.Args["cursor"].(string)
+	page, err := r.serviceHandler.GetTaskHistory(source.OwnerId, source.Id, query)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return page, nil
+}
+
+func (r *resolver) createTask(p graphql.ResolveParams) (any, error) {
+	input := p.Args["input"].(map[string]any)
+
+	task, err := r.writer(p).CreateTask(callerId(p.Context), services.CreateTaskData{
+		Title:       stringArg(input, "title"),
+		Description: stringArg(input, "description"),
+		Status:      stringArg(input, "status"),
+		Priority:    stringArg(input, "priority"),
+		DueAt:       timeArg(input, "dueAt"),
+		ParentId:    stringArg(input, "parentId"),
+		Recurrence:  stringArg(input, "recurrence"),
+	})
+	if err != nil {
+		return nil, mutationError(err)
+	}
+	return task, nil
+}
+
+// updateTask fails when none of the fields is set, like the HTTP API answers
+// with no content.
+func (r *resolver) updateTask(p graphql.ResolveParams) (any, error) {
+	input := p.Args["input"].(map[string]any)
+	data := services.UpdateTaskData{
+		Title:       stringArg(input, "title"),
+		Description: stringArg(input, "description"),
+		Status:      stringArg(input, "status"),
+		Priority:    stringArg(input, "priority"),
+		DueAt:       timeArg(input, "dueAt"),
+		ParentId:    stringArg(input, "parentId"),
+		Recurrence:  stringArg(input, "recurrence"),
+		IsCompleted: boolArg(input, "isCompleted"),
+	}
+	if clear, _ := input["clearDueAt"].(bool); clear {
+		data.ClearDueAt = true
+	}
+	if data.Title == nil && data.Description == nil && data.Status == nil &&
+		data.Priority == nil && data.DueAt == nil && !data.ClearDueAt && data.ParentId == nil && data.IsCompleted == nil && data.Recurrence == nil {
+		return nil, fromHttpError(httperrors.NoOpError())
+	}
+
+	writer := r.writer(p)
+	update := writer.UpdateTask
+	if series, _ := p.Args["series"].(bool); series {
+		update = writer.UpdateSeries
+	}
+	task, err := update(callerId(p.Context), p.Args["id"].(string), version(p.Args), data)
+	if err != nil {
+		return nil, mutationError(err)
+	}
+	return task, nil
+}
+
+func (r *resolver) deleteTask(p graphql.ResolveParams) (any, error) {
+	writer := r.writer(p)
+	remove := writer.DeleteTask
+	if series, _ := p.Args["series"].(bool); series {
+		remove = writer.DeleteSeries
+	}
+	taskId, err := remove(callerId(p.Context), p.Args["id"].(string), version(p.Args))
+	if err != nil {
+		return nil, mutationError(err)
+	}
+	return *taskId, nil
+}
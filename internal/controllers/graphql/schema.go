@@ -0,0 +1,256 @@
+package graphqlController
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// jsonScalar is any JSON value, the changed fields of an event hold the
+// values of any field of a task.
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Any JSON value, the old or new value of a changed field.",
+	Serialize: func(value any) any {
+		return value
+	},
+})
+
+func nonNull(typ graphql.Type) *graphql.NonNull {
+	return graphql.NewNonNull(typ)
+}
+
+func listOf(typ graphql.Type) *graphql.NonNull {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(typ)))
+}
+
+// pageArgs are the arguments of every paged list.
+func pageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"limit":  {Type: graphql.Int, Description: "How many items a page holds, 20 when not set."},
+		"cursor": {Type: graphql.String, Description: "The nextCursor of the previous page."},
+	}
+}
+
+// listArgs are the filters shared by the task listings.
+func listArgs() graphql.FieldConfigArgument {
+	args := pageArgs()
+	args["sort"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "created_at, updated_at, due_at or priority."}
+	args["order"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "asc or desc."}
+	args["isCompleted"] = &graphql.ArgumentConfig{Type: graphql.Boolean}
+	args["createdAfter"] = &graphql.ArgumentConfig{Type: graphql.DateTime}
+	args["tags"] = &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))}
+	args["tagMatch"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "any or all of the tags, any when not set."}
+	return args
+}
+
+// newSchema describes the tasks of the service handler. A task resolves its
+// parent, subtasks, dependencies and history on demand, so a client picks
+// the subresources it needs in one request.
+func newSchema(resolver *resolver) (graphql.Schema, error) {
+	changeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Change",
+		Fields: graphql.Fields{
+			"field": {Type: nonNull(graphql.String)},
+			"from":  {Type: jsonScalar},
+			"to":    {Type: jsonScalar},
+		},
+	})
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Event",
+		Description: "A write to a task in its change history.",
+		Fields: graphql.Fields{
+			"id":        {Type: nonNull(graphql.ID)},
+			"taskId":    {Type: nonNull(graphql.ID)},
+			"ownerId":   {Type: nonNull(graphql.String)},
+			"actorId":   {Type: nonNull(graphql.String)},
+			"action":    {Type: nonNull(graphql.String)},
+			"changes":   {Type: listOf(changeType), Resolve: resolver.changes},
+			"createdAt": {Type: nonNull(graphql.DateTime)},
+		},
+	})
+	eventPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "EventPage",
+		Fields: graphql.Fields{
+			"events":     {Type: listOf(eventType)},
+			"nextCursor": {Type: graphql.String},
+		},
+	})
+
+	var taskType *graphql.Object
+	taskPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaskPage",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"tasks":      {Type: listOf(taskType)},
+				"nextCursor": {Type: graphql.String},
+			}
+		}),
+	})
+	taskType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          {Type: nonNull(graphql.ID)},
+				"ownerId":     {Type: nonNull(graphql.String)},
+				"title":       {Type: nonNull(graphql.String)},
+				"description": {Type: nonNull(graphql.String)},
+				"status":      {Type: nonNull(graphql.String)},
+				"priority":    {Type: nonNull(graphql.String)},
+				"dueAt":       {Type: graphql.DateTime},
+				"tags":        {Type: listOf(graphql.String), Resolve: resolver.tags},
+				"parentId":    {Type: graphql.ID},
+				"recurrence":  {Type: graphql.String},
+				"seriesId":    {Type: graphql.ID},
+				"isCompleted": {Type: nonNull(graphql.Boolean)},
+				"version":     {Type: nonNull(graphql.Int)},
+				"createdAt":   {Type: nonNull(graphql.DateTime)},
+				"updatedAt":   {Type: nonNull(graphql.DateTime)},
+				"deletedAt":   {Type: graphql.DateTime},
+				"parent": {
+					Type:        taskType,
+					Description: "The task this one is a subtask of, null for a top level task.",
+					Resolve:     resolver.parent,
+				},
+				"subtasks": {
+					Type:        nonNull(taskPageType),
+					Description: "The direct subtasks of the task.",
+					Args:        listArgs(),
+					Resolve:     resolver.subtasks,
+				},
+				"blockers": {
+					Type:        listOf(taskType),
+					Description: "The tasks that have to be done before this one.",
+					Resolve:     resolver.blockers,
+				},
+				"blocking": {
+					Type:        listOf(taskType),
+					Description: "The tasks this one has to be done before.",
+					Resolve:     resolver.blocking,
+				},
+				"history": {
+					Type:        nonNull(eventPageType),
+					Description: "The changes of the task, oldest first.",
+					Args:        pageArgs(),
+					Resolve:     resolver.history,
+				},
+			}
+		}),
+	})
+
+	searchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchResult",
+		Fields: graphql.Fields{
+			"task":      {Type: nonNull(taskType)},
+			"rank":      {Type: nonNull(graphql.Float)},
+			"highlight": {Type: nonNull(graphql.String)},
+			"snippet":   {Type: nonNull(graphql.String)},
+		},
+	})
+	searchPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchPage",
+		Fields: graphql.Fields{
+			"results":    {Type: listOf(searchResultType), Resolve: resolver.searchResults},
+			"nextCursor": {Type: graphql.String},
+		},
+	})
+
+	owner := &graphql.ArgumentConfig{
+		Type:        graphql.String,
+		Description: "The owner of the tasks, only admins may name another user than themselves.",
+	}
+	tasksArgs := listArgs()
+	tasksArgs["owner"] = owner
+	searchArgs := pageArgs()
+	searchArgs["query"] = &graphql.ArgumentConfig{Type: nonNull(graphql.String)}
+	searchArgs["owner"] = owner
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"task": {
+				Type: taskType,
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: nonNull(graphql.ID)},
+					"owner": owner,
+				},
+				Resolve: resolver.task,
+			},
+			"tasks": {
+				Type:    nonNull(taskPageType),
+				Args:    tasksArgs,
+				Resolve: resolver.tasks,
+			},
+			"search": {
+				Type:        nonNull(searchPageType),
+				Description: "The tasks whose title or description match every term of the query, best match first.",
+				Args:        searchArgs,
+				Resolve:     resolver.search,
+			},
+		},
+	})
+
+	createInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateTaskInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       {Type: nonNull(graphql.String)},
+			"description": {Type: nonNull(graphql.String)},
+			"status":      {Type: graphql.String},
+			"priority":    {Type: graphql.String},
+			"dueAt":       {Type: graphql.DateTime},
+			"parentId":    {Type: graphql.ID},
+			"recurrence":  {Type: graphql.String},
+		},
+	})
+	updateInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateTaskInput",
+		Description: "The fields to change, an empty parentId moves the task to the top level, an empty recurrence stops the series and clearDueAt removes the due date.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":       {Type: graphql.String},
+			"description": {Type: graphql.String},
+			"status":      {Type: graphql.String},
+			"priority":    {Type: graphql.String},
+			"dueAt":       {Type: graphql.DateTime},
+			"parentId":    {Type: graphql.ID},
+			"recurrence":  {Type: graphql.String},
+			"isCompleted": {Type: graphql.Boolean},
+			"clearDueAt":  {Type: graphql.Boolean},
+		},
+	})
+	writeArgs := func() graphql.FieldConfigArgument {
+		return graphql.FieldConfigArgument{
+			"id":      {Type: nonNull(graphql.ID)},
+			"version": {Type: graphql.Int, Description: "The version the task is expected to have, the write fails when it changed since."},
+			"series":  {Type: graphql.Boolean, Description: "Applies to this and the later occurrences of a recurring task."},
+		}
+	}
+	updateArgs := writeArgs()
+	updateArgs["input"] = &graphql.ArgumentConfig{Type: nonNull(updateInput)}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": {
+				Type: nonNull(taskType),
+				Args: graphql.FieldConfigArgument{
+					"input": {Type: nonNull(createInput)},
+				},
+				Resolve: resolver.createTask,
+			},
+			"updateTask": {
+				Type:    nonNull(taskType),
+				Args:    updateArgs,
+				Resolve: resolver.updateTask,
+			},
+			"deleteTask": {
+				Type:        nonNull(graphql.ID),
+				Description: "Moves the task to the trash and returns its ID.",
+				Args:        writeArgs(),
+				Resolve:     resolver.deleteTask,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
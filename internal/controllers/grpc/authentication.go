@@ -0,0 +1,107 @@
+package grpcController
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type claimsKey struct{}
+
+// UnaryAuthenticate checks the credential in the metadata of a call with the
+// authenticator of the HTTP API, and the role the method needs.
+func UnaryAuthenticate(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthenticate is UnaryAuthenticate for the streaming methods.
+func StreamAuthenticate(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{stream, ctx})
+	}
+}
+
+// authenticatedStream carries the claims of the caller in its context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+// authenticate returns the context with the claims of the caller. The
+// authenticators read HTTP requests, so the metadata is handed to them as
+// the headers of one.
+func authenticate(ctx context.Context, authenticator auth.Authenticator, method string) (context.Context, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, method, nil)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	claims, err := authenticator.Authenticate(request)
+	if errors.Is(err, auth.ErrUnauthenticated) {
+		log.Printf("authentication error: %v", err)
+		return nil, fromHttpError(httperrors.UnauthorizedError())
+	}
+	if err != nil {
+		return nil, fromHttpError(middlewares.AuthServerError(err))
+	}
+
+	role, ok := methodRoles[method]
+	if !ok || !claims.HasRole(role) {
+		return nil, fromHttpError(httperrors.ForbiddenError())
+	}
+
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+func callerClaims(ctx context.Context) *auth.Claims {
+	claims, _ := ctx.Value(claimsKey{}).(*auth.Claims)
+	if claims == nil {
+		return &auth.Claims{}
+	}
+	return claims
+}
+
+// callerId is the user the call was authenticated as.
+func callerId(ctx context.Context) string {
+	return callerClaims(ctx).UserId
+}
+
+// readOwner is the owner whose tasks a read call is about, the caller itself
+// unless an admin names another owner.
+func readOwner(ctx context.Context, owner string) (string, error) {
+	claims := callerClaims(ctx)
+	if owner == "" || owner == claims.UserId {
+		return claims.UserId, nil
+	}
+	if !claims.HasRole(auth.RoleAdmin) {
+		return "", fromHttpError(httperrors.ForbiddenError())
+	}
+	return owner, nil
+}
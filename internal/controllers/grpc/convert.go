@@ -0,0 +1,190 @@
+package grpcController
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/controllers/grpc/pb"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/events"
+	"github.com/Arup3201/gotasks/internal/services"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// timeField reads the timestamp of a request field, nil when it is not set.
+func timeField(ts *timestamppb.Timestamp, field string) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	if err := ts.CheckValid(); err != nil {
+		return nil, fromHttpError(httperrors.InvalidBodyError(httperrors.ErrorField{
+			Field:  field,
+			Reason: "'" + field + "' must be a valid timestamp",
+		}))
+	}
+	t := ts.AsTime()
+	return &t, nil
+}
+
+func version(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	converted := int(*v)
+	return &converted
+}
+
+func toTask(t *task.Task) *pb.Task {
+	if t == nil {
+		return nil
+	}
+	return &pb.Task{
+		Id:          t.Id,
+		OwnerId:     t.OwnerId,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueAt:       timestamp(t.DueAt),
+		Tags:        t.Tags,
+		ParentId:    t.ParentId,
+		Recurrence:  t.Recurrence,
+		SeriesId:    t.SeriesId,
+		IsCompleted: t.IsCompleted,
+		Version:     int32(t.Version),
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+		DeletedAt:   timestamp(t.DeletedAt),
+	}
+}
+
+func toTasks(tasks []task.Task) []*pb.Task {
+	converted := []*pb.Task{}
+	for i := range tasks {
+		converted = append(converted, toTask(&tasks[i]))
+	}
+	return converted
+}
+
+func toTaskPage(page *services.TaskPage) *pb.TaskPage {
+	return &pb.TaskPage{
+		Tasks:      toTasks(page.Tasks),
+		NextCursor: page.NextCursor,
+	}
+}
+
+func toTaskList(list *services.TaskList) *pb.TaskList {
+	return &pb.TaskList{
+		Tasks: toTasks(list.Tasks),
+	}
+}
+
+func toTaskTree(tree *services.TaskTree) *pb.TaskTree {
+	converted := &pb.TaskTree{
+		Task: toTask(&tree.Task),
+		Progress: &pb.TaskProgress{
+			Done:  int32(tree.Progress.Done),
+			Total: int32(tree.Progress.Total),
+		},
+	}
+	for i := range tree.Subtasks {
+		converted.Subtasks = append(converted.Subtasks, toTaskTree(&tree.Subtasks[i]))
+	}
+	return converted
+}
+
+// toValue converts the value of a changed field, the values of the history
+// are the ones that JSON holds.
+func toValue(value any) (*structpb.Value, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	converted := &structpb.Value{}
+	if err := converted.UnmarshalJSON(encoded); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+func toEventPage(page *services.EventPage) (*pb.EventPage, error) {
+	converted := &pb.EventPage{
+		Events:     []*pb.Event{},
+		NextCursor: page.NextCursor,
+	}
+	for _, event := range page.Events {
+		changes := []*pb.Change{}
+		for _, change := range event.Changes {
+			from, err := toValue(change.From)
+			if err != nil {
+				return nil, err
+			}
+			to, err := toValue(change.To)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, &pb.Change{Field: change.Field, From: from, To: to})
+		}
+		converted.Events = append(converted.Events, &pb.Event{
+			Id:        event.Id,
+			TaskId:    event.TaskId,
+			OwnerId:   event.OwnerId,
+			ActorId:   event.ActorId,
+			Action:    event.Action,
+			Changes:   changes,
+			CreatedAt: timestamppb.New(event.CreatedAt),
+		})
+	}
+	return converted, nil
+}
+
+func toTaskChange(change events.Change) *pb.TaskChange {
+	return &pb.TaskChange{
+		Id:      change.Id,
+		Type:    change.Type,
+		OwnerId: change.OwnerId,
+		TaskId:  change.TaskId,
+		Task:    toTask(change.Task),
+	}
+}
+
+func toWebhook(webhook *task.Webhook) *pb.Webhook {
+	return &pb.Webhook{
+		Id:        webhook.Id,
+		OwnerId:   webhook.OwnerId,
+		Url:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: timestamppb.New(webhook.CreatedAt),
+	}
+}
+
+func toDelivery(delivery *task.Delivery) *pb.Delivery {
+	converted := &pb.Delivery{
+		Id:            delivery.Id,
+		WebhookId:     delivery.WebhookId,
+		OwnerId:       delivery.OwnerId,
+		Event:         delivery.Event,
+		TaskId:        delivery.TaskId,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      int32(delivery.Attempts),
+		NextAttemptAt: timestamppb.New(delivery.NextAttemptAt),
+		LastError:     delivery.LastError,
+		CreatedAt:     timestamppb.New(delivery.CreatedAt),
+		DeliveredAt:   timestamp(delivery.DeliveredAt),
+	}
+	if delivery.ResponseStatus != nil {
+		responseStatus := int32(*delivery.ResponseStatus)
+		converted.ResponseStatus = &responseStatus
+	}
+	return converted
+}
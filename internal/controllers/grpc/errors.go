@@ -0,0 +1,81 @@
+package grpcController
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// statusCodes are the gRPC codes of the statuses of the HTTP API, any other
+// status is an internal error.
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusUnauthorized:       codes.Unauthenticated,
+	http.StatusForbidden:          codes.PermissionDenied,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusPreconditionFailed: codes.FailedPrecondition,
+	// an update without any field is refused instead of answered with no
+	// content
+	http.StatusNoContent:          codes.InvalidArgument,
+	http.StatusFailedDependency:   codes.Aborted,
+	http.StatusBadGateway:         codes.Unavailable,
+	http.StatusServiceUnavailable: codes.Unavailable,
+}
+
+// statusError maps an error of the service handler to a gRPC status, an
+// AppError the same way httperrors.FromAppError does for the HTTP API.
+func statusError(err error) error {
+	appError, ok := err.(*errors.AppError)
+	if ok {
+		return fromHttpError(httperrors.FromAppError(appError))
+	}
+	return fromHttpError(httperrors.InternalServerError(err))
+}
+
+// fromHttpError is the status of an error response of the HTTP API, its
+// fields are reported as a BadRequest and its code as an ErrorInfo.
+func fromHttpError(httpError *httperrors.HttpError) error {
+	code, ok := statusCodes[httpError.Status]
+	if !ok {
+		code = codes.Internal
+	}
+	if code == codes.Internal {
+		log.Printf("Internal server error: %v", httpError)
+	}
+
+	st := status.New(code, httpError.Detail)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   httpError.Id,
+		Domain:   "gotasks",
+		Metadata: map[string]string{"code": httpError.Code},
+	}}
+	if len(httpError.Errors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range httpError.Errors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Reason,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	if retryAfter, err := strconv.Atoi(httpError.Headers["Retry-After"]); err == nil {
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(retryAfter) * time.Second),
+		})
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,546 @@
+package grpcController
+
+import (
+	"context"
+
+	"github.com/Arup3201/gotasks/internal/controllers/grpc/pb"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/events"
+	"github.com/Arup3201/gotasks/internal/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// listBatch is how many tasks ListTasks reads at once, the largest page of
+// the service.
+const listBatch = 100
+
+type taskServer struct {
+	pb.UnimplementedTasksServer
+	serviceHandler services.ServiceHandler
+}
+
+// writer is the service handler for the writes of a call, they are recorded
+// as made by the caller.
+func (server *taskServer) writer(ctx context.Context) services.ServiceHandler {
+	return server.serviceHandler.WithActor(callerId(ctx))
+}
+
+// listQuery reads the filters shared by the task listings.
+func listQuery(sort, order string, isCompleted *bool, createdAfter *timestamppb.Timestamp, tags []string, tagMatch string) (services.ListTasksQuery, error) {
+	after, err := timeField(createdAfter, "created_after")
+	if err != nil {
+		return services.ListTasksQuery{}, err
+	}
+	return services.ListTasksQuery{
+		SortBy:       sort,
+		Order:        order,
+		IsCompleted:  isCompleted,
+		CreatedAfter: after,
+		Tags:         tags,
+		TagMatch:     tagMatch,
+	}, nil
+}
+
+func (server *taskServer) ListTasks(req *pb.ListTasksRequest, stream grpc.ServerStreamingServer[pb.Task]) error {
+	ownerId, err := readOwner(stream.Context(), req.Owner)
+	if err != nil {
+		return err
+	}
+	query, err := listQuery(req.Sort, req.Order, req.IsCompleted, req.CreatedAfter, req.Tags, req.TagMatch)
+	if err != nil {
+		return err
+	}
+	query.Limit = listBatch
+
+	for {
+		page, err := server.serviceHandler.GetAllTasks(ownerId, query)
+		if err != nil {
+			return statusError(err)
+		}
+		for i := range page.Tasks {
+			if err := stream.Send(toTask(&page.Tasks[i])); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == nil {
+			return nil
+		}
+		query.Cursor = *page.NextCursor
+	}
+}
+
+func (server *taskServer) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Task, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := server.serviceHandler.GetTask(ownerId, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTask(task), nil
+}
+
+func (server *taskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.Task, error) {
+	dueAt, err := timeField(req.DueAt, "due_at")
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := server.writer(ctx).CreateTask(callerId(ctx), services.CreateTaskData{
+		Title:       &req.Title,
+		Description: &req.Description,
+		Status:      req.Status,
+		Priority:    req.Priority,
+		DueAt:       dueAt,
+		ParentId:    req.ParentId,
+		Recurrence:  req.Recurrence,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTask(task), nil
+}
+
+// updateData reads the fields of an update, it fails when none of them is
+// set like the HTTP API answers with no content.
+func updateData(req *pb.UpdateTaskRequest) (services.UpdateTaskData, error) {
+	if req == nil {
+		return services.UpdateTaskData{}, nil
+	}
+	dueAt, err := timeField(req.DueAt, "due_at")
+	if err != nil {
+		return services.UpdateTaskData{}, err
+	}
+	return services.UpdateTaskData{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      req.Status,
+		Priority:    req.Priority,
+		DueAt:       dueAt,
+		ParentId:    req.ParentId,
+		Recurrence:  req.Recurrence,
+		IsCompleted: req.IsCompleted,
+		ClearDueAt:  req.ClearDueAt,
+	}, nil
+}
+
+func (server *taskServer) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.Task, error) {
+	data, err := updateData(req)
+	if err != nil {
+		return nil, err
+	}
+	if data.Title == nil && data.Description == nil && data.Status == nil &&
+		data.Priority == nil && data.DueAt == nil && !data.ClearDueAt && data.ParentId == nil && data.IsCompleted == nil && data.Recurrence == nil {
+		return nil, fromHttpError(httperrors.NoOpError())
+	}
+
+	writer := server.writer(ctx)
+	update := writer.UpdateTask
+	if req.Series {
+		update = writer.UpdateSeries
+	}
+	task, err := update(callerId(ctx), req.Id, version(req.Version), data)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTask(task), nil
+}
+
+func (server *taskServer) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.DeleteTaskResponse, error) {
+	writer := server.writer(ctx)
+	remove := writer.DeleteTask
+	if req.Series {
+		remove = writer.DeleteSeries
+	}
+	taskId, err := remove(callerId(ctx), req.Id, version(req.Version))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.DeleteTaskResponse{Id: *taskId}, nil
+}
+
+func (server *taskServer) RestoreTask(ctx context.Context, req *pb.RestoreTaskRequest) (*pb.Task, error) {
+	task, err := server.writer(ctx).RestoreTask(callerId(ctx), req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTask(task), nil
+}
+
+func (server *taskServer) SetTaskTags(ctx context.Context, req *pb.SetTaskTagsRequest) (*pb.Task, error) {
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	task, err := server.writer(ctx).SetTaskTags(callerId(ctx), req.Id, version(req.Version), tags)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTask(task), nil
+}
+
+// BatchTasks answers with the code every operation would have had as a call
+// of its own, the operations of a failed atomic batch that were undone get
+// ABORTED.
+func (server *taskServer) BatchTasks(ctx context.Context, req *pb.BatchTasksRequest) (*pb.BatchTasksResponse, error) {
+	batch := services.Batch{Atomic: req.Atomic}
+	for _, operation := range req.Operations {
+		data, err := updateData(operation.Data)
+		if err != nil {
+			return nil, err
+		}
+		batch.Operations = append(batch.Operations, services.BatchOperation{
+			Op:      operation.Op,
+			TaskId:  operation.Id,
+			Version: version(operation.Version),
+			Data:    data,
+		})
+	}
+
+	results, err := server.writer(ctx).RunBatch(callerId(ctx), batch)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &pb.BatchTasksResponse{Applied: true, Results: []*pb.BatchResult{}}
+	for _, result := range results {
+		item := &pb.BatchResult{
+			Op:   result.Op,
+			Id:   result.TaskId,
+			Task: toTask(result.Task),
+		}
+		var st *status.Status
+		switch {
+		case result.RolledBack:
+			st = status.Convert(fromHttpError(httperrors.FailedDependencyError()))
+		case result.Err != nil:
+			st = status.Convert(statusError(result.Err))
+		}
+		if st != nil {
+			response.Applied = false
+			item.Code, item.Message = int32(st.Code()), st.Message()
+		}
+		response.Results = append(response.Results, item)
+	}
+	return response, nil
+}
+
+func (server *taskServer) ListTrash(ctx context.Context, req *pb.ListPageRequest) (*pb.TaskPage, error) {
+	return server.listPage(ctx, req, func(ownerId string, query services.ListTasksQuery) (*services.TaskPage, error) {
+		return server.serviceHandler.GetTrash(ownerId, query)
+	})
+}
+
+func (server *taskServer) ListSubtasks(ctx context.Context, req *pb.ListPageRequest) (*pb.TaskPage, error) {
+	return server.listPage(ctx, req, func(ownerId string, query services.ListTasksQuery) (*services.TaskPage, error) {
+		return server.serviceHandler.GetSubtasks(ownerId, req.Id, query)
+	})
+}
+
+func (server *taskServer) listPage(ctx context.Context, req *pb.ListPageRequest, list func(ownerId string, query services.ListTasksQuery) (*services.TaskPage, error)) (*pb.TaskPage, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+	query, err := listQuery(req.Sort, req.Order, req.IsCompleted, req.CreatedAfter, req.Tags, req.TagMatch)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit, query.Cursor = int(req.Limit), req.Cursor
+
+	page, err := list(ownerId, query)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTaskPage(page), nil
+}
+
+func (server *taskServer) GetTaskTree(ctx context.Context, req *pb.GetTaskRequest) (*pb.TaskTree, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := server.serviceHandler.GetTaskTree(ownerId, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTaskTree(tree), nil
+}
+
+func (server *taskServer) SearchTasks(ctx context.Context, req *pb.SearchTasksRequest) (*pb.SearchPage, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+	if req.Query == "" {
+		return nil, fromHttpError(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+			Field:  "query",
+			Reason: "'query' is required",
+		}))
+	}
+
+	page, err := server.serviceHandler.SearchTasks(ownerId, services.SearchTasksQuery{
+		Query:  req.Query,
+		Limit:  int(req.Limit),
+		Cursor: req.Cursor,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	converted := &pb.SearchPage{
+		Tasks:      []*pb.SearchResult{},
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Tasks {
+		result := &page.Tasks[i]
+		converted.Tasks = append(converted.Tasks, &pb.SearchResult{
+			Task:      toTask(&result.Task),
+			Rank:      result.Rank,
+			Highlight: result.Highlight,
+			Snippet:   result.Snippet,
+		})
+	}
+	return converted, nil
+}
+
+func (server *taskServer) ListTags(ctx context.Context, req *pb.ListTagsRequest) (*pb.TagList, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := server.serviceHandler.GetTags(ownerId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	converted := &pb.TagList{Tags: []*pb.TagCount{}}
+	for _, tag := range tags.Tags {
+		converted.Tags = append(converted.Tags, &pb.TagCount{Name: tag.Name, Count: int32(tag.Count)})
+	}
+	return converted, nil
+}
+
+func (server *taskServer) AddDependency(ctx context.Context, req *pb.DependencyRequest) (*pb.TaskList, error) {
+	if req.BlockerId == "" {
+		return nil, fromHttpError(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "blocker_id",
+			Reason: "Task 'blocker_id' is required",
+		}))
+	}
+
+	blockers, err := server.serviceHandler.AddDependency(callerId(ctx), req.Id, req.BlockerId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTaskList(blockers), nil
+}
+
+func (server *taskServer) RemoveDependency(ctx context.Context, req *pb.DependencyRequest) (*pb.TaskList, error) {
+	blockers, err := server.serviceHandler.RemoveDependency(callerId(ctx), req.Id, req.BlockerId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTaskList(blockers), nil
+}
+
+func (server *taskServer) ListBlockers(ctx context.Context, req *pb.GetTaskRequest) (*pb.TaskList, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	blockers, err := server.serviceHandler.GetBlockers(ownerId, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTaskList(blockers), nil
+}
+
+func (server *taskServer) ListBlocking(ctx context.Context, req *pb.GetTaskRequest) (*pb.TaskList, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	blocking, err := server.serviceHandler.GetBlocking(ownerId, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toTaskList(blocking), nil
+}
+
+func (server *taskServer) GetPlan(ctx context.Context, req *pb.GetPlanRequest) (*pb.TaskPlan, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := server.serviceHandler.GetPlan(ownerId)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	converted := &pb.TaskPlan{Tasks: []*pb.PlannedTask{}}
+	for i := range plan.Tasks {
+		converted.Tasks = append(converted.Tasks, &pb.PlannedTask{
+			Task:      toTask(&plan.Tasks[i].Task),
+			BlockedBy: plan.Tasks[i].BlockedBy,
+		})
+	}
+	return converted, nil
+}
+
+func eventsQuery(req *pb.EventsRequest) (services.EventsQuery, error) {
+	from, err := timeField(req.From, "from")
+	if err != nil {
+		return services.EventsQuery{}, err
+	}
+	to, err := timeField(req.To, "to")
+	if err != nil {
+		return services.EventsQuery{}, err
+	}
+	return services.EventsQuery{
+		ActorId: req.Actor,
+		From:    from,
+		To:      to,
+		Limit:   int(req.Limit),
+		Cursor:  req.Cursor,
+	}, nil
+}
+
+func (server *taskServer) GetTaskHistory(ctx context.Context, req *pb.EventsRequest) (*pb.EventPage, error) {
+	ownerId, err := readOwner(ctx, req.Owner)
+	if err != nil {
+		return nil, err
+	}
+	query, err := eventsQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := server.serviceHandler.GetTaskHistory(ownerId, req.Id, query)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	converted, err := toEventPage(page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return converted, nil
+}
+
+func (server *taskServer) GetAuditLog(ctx context.Context, req *pb.EventsRequest) (*pb.EventPage, error) {
+	query, err := eventsQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := server.serviceHandler.GetAuditLog(query)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	converted, err := toEventPage(page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return converted, nil
+}
+
+// WatchTasks sends the changes of the tasks of the caller until the client
+// cancels, the headers are sent once it is subscribed. When the stream falls behind it ends with UNAVAILABLE, and the
+// client resumes from the last change it got.
+func (server *taskServer) WatchTasks(req *pb.WatchTasksRequest, stream grpc.ServerStreamingServer[pb.TaskChange]) error {
+	subscription := server.serviceHandler.SubscribeTaskChanges(callerId(stream.Context()), req.LastEventId)
+	defer subscription.Close()
+
+	// the headers tell the client that the changes from now on are sent
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	if subscription.Missed {
+		if err := stream.Send(toTaskChange(events.Change{Type: events.Reset})); err != nil {
+			return err
+		}
+	}
+	for _, change := range subscription.Replay {
+		if err := stream.Send(toTaskChange(change)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				return status.Error(codes.Unavailable, "The stream fell behind the changes, resume from the last change")
+			}
+			if err := stream.Send(toTaskChange(change)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (server *taskServer) CreateWebhook(ctx context.Context, req *pb.CreateWebhookRequest) (*pb.Webhook, error) {
+	webhook, err := server.serviceHandler.CreateWebhook(callerId(ctx), services.CreateWebhookData{
+		URL:    &req.Url,
+		Events: req.Events,
+		Secret: &req.Secret,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toWebhook(webhook), nil
+}
+
+func (server *taskServer) ListWebhooks(ctx context.Context, req *pb.ListWebhooksRequest) (*pb.WebhookList, error) {
+	webhooks, err := server.serviceHandler.GetWebhooks(callerId(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	converted := &pb.WebhookList{Webhooks: []*pb.Webhook{}}
+	for i := range webhooks.Webhooks {
+		converted.Webhooks = append(converted.Webhooks, toWebhook(&webhooks.Webhooks[i]))
+	}
+	return converted, nil
+}
+
+func (server *taskServer) DeleteWebhook(ctx context.Context, req *pb.DeleteWebhookRequest) (*pb.DeleteWebhookResponse, error) {
+	webhookId, err := server.serviceHandler.DeleteWebhook(callerId(ctx), req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.DeleteWebhookResponse{Id: *webhookId}, nil
+}
+
+func (server *taskServer) ListDeliveries(ctx context.Context, req *pb.ListDeliveriesRequest) (*pb.DeliveryPage, error) {
+	page, err := server.serviceHandler.GetDeliveries(callerId(ctx), req.Id, services.DeliveriesQuery{
+		Status: req.Status,
+		Limit:  int(req.Limit),
+		Cursor: req.Cursor,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	converted := &pb.DeliveryPage{
+		Deliveries: []*pb.Delivery{},
+		NextCursor: page.NextCursor,
+	}
+	for i := range page.Deliveries {
+		converted.Deliveries = append(converted.Deliveries, toDelivery(&page.Deliveries[i]))
+	}
+	return converted, nil
+}
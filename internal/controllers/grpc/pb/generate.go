@@ -0,0 +1,5 @@
+// Package pb holds the messages and the service of the gRPC API, generated
+// from tasks.proto.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative tasks.proto
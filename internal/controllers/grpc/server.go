@@ -0,0 +1,85 @@
+package grpcController
+
+import (
+	"net"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	"github.com/Arup3201/gotasks/internal/controllers/grpc/pb"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/Arup3201/gotasks/internal/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+type GrpcServer struct {
+	server *grpc.Server
+}
+
+var Server = &GrpcServer{}
+
+// methodRoles is the role each method needs, like the routes of the HTTP
+// API. A method that is not listed is refused.
+var methodRoles = map[string]string{
+	pb.Tasks_ListTasks_FullMethodName:        auth.RoleRead,
+	pb.Tasks_GetTask_FullMethodName:          auth.RoleRead,
+	pb.Tasks_CreateTask_FullMethodName:       auth.RoleWrite,
+	pb.Tasks_UpdateTask_FullMethodName:       auth.RoleWrite,
+	pb.Tasks_DeleteTask_FullMethodName:       auth.RoleWrite,
+	pb.Tasks_RestoreTask_FullMethodName:      auth.RoleWrite,
+	pb.Tasks_SetTaskTags_FullMethodName:      auth.RoleWrite,
+	pb.Tasks_BatchTasks_FullMethodName:       auth.RoleWrite,
+	pb.Tasks_ListTrash_FullMethodName:        auth.RoleRead,
+	pb.Tasks_ListSubtasks_FullMethodName:     auth.RoleRead,
+	pb.Tasks_GetTaskTree_FullMethodName:      auth.RoleRead,
+	pb.Tasks_SearchTasks_FullMethodName:      auth.RoleRead,
+	pb.Tasks_ListTags_FullMethodName:         auth.RoleRead,
+	pb.Tasks_AddDependency_FullMethodName:    auth.RoleWrite,
+	pb.Tasks_RemoveDependency_FullMethodName: auth.RoleWrite,
+	pb.Tasks_ListBlockers_FullMethodName:     auth.RoleRead,
+	pb.Tasks_ListBlocking_FullMethodName:     auth.RoleRead,
+	pb.Tasks_GetPlan_FullMethodName:          auth.RoleRead,
+	pb.Tasks_GetTaskHistory_FullMethodName:   auth.RoleRead,
+	pb.Tasks_GetAuditLog_FullMethodName:      auth.RoleAdmin,
+	pb.Tasks_WatchTasks_FullMethodName:       auth.RoleRead,
+	pb.Tasks_CreateWebhook_FullMethodName:    auth.RoleWrite,
+	pb.Tasks_ListWebhooks_FullMethodName:     auth.RoleRead,
+	pb.Tasks_DeleteWebhook_FullMethodName:    auth.RoleWrite,
+	pb.Tasks_ListDeliveries_FullMethodName:   auth.RoleRead,
+}
+
+// InitServer serves the operations of the service handler, shared with the
+// HTTP API so that both see the same task changes.
+func InitServer(serviceHandler services.ServiceHandler, authenticator auth.Authenticator) error {
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryAuthenticate(authenticator)),
+		grpc.ChainStreamInterceptor(StreamAuthenticate(authenticator)),
+	}
+	// idle streams of changes are pinged like the HTTP ones get heartbeats
+	if utils.Config.EventHeartbeat > 0 {
+		options = append(options, grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time: utils.Config.EventHeartbeat,
+		}))
+	}
+
+	Server.server = grpc.NewServer(options...)
+	pb.RegisterTasksServer(Server.server, &taskServer{serviceHandler: serviceHandler})
+
+	return nil
+}
+
+// Serve answers the calls that come in on listener until Stop.
+func (server *GrpcServer) Serve(listener net.Listener) error {
+	return server.server.Serve(listener)
+}
+
+func (server *GrpcServer) Run(host string) error {
+	listener, err := net.Listen("tcp", host+":"+utils.Config.GrpcPort)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+func (server *GrpcServer) Stop() {
+	server.server.Stop()
+}
@@ -0,0 +1,201 @@
+package grpcController
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	"github.com/Arup3201/gotasks/internal/controllers/grpc/pb"
+	"github.com/Arup3201/gotasks/internal/services/domain/task"
+	"github.com/Arup3201/gotasks/internal/storages"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	testToken   = "test-token"
+	readerToken = "reader-token"
+	adminToken  = "admin-token"
+)
+
+// newClient serves a fresh in-memory service and returns a client of it.
+func newClient(t *testing.T) pb.TasksClient {
+	t.Helper()
+
+	storage, err := storages.New(storages.InMemory)
+	if err != nil {
+		t.Fatalf("storage create error: %v", err)
+	}
+	serviceHandler, err := task.NewTaskService(storage)
+	if err != nil {
+		t.Fatalf("service create error: %v", err)
+	}
+	InitServer(serviceHandler, &auth.MockAuthenticator{
+		Tokens: map[string]auth.Claims{
+			testToken:   {UserId: "test-user", Roles: []string{auth.RoleRead, auth.RoleWrite}},
+			readerToken: {UserId: "reader", Roles: []string{auth.RoleRead}},
+			adminToken:  {UserId: "admin", Roles: []string{auth.RoleAdmin}},
+		},
+	})
+
+	listener := bufconn.Listen(1 << 20)
+	go Server.Serve(listener)
+	t.Cleanup(Server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("client create error: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewTasksClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestGrpcAuthentication(t *testing.T) {
+	client := newClient(t)
+
+	_, anonymous := client.GetTask(context.Background(), &pb.GetTaskRequest{Id: "task-1"})
+	_, reader := client.CreateTask(withToken(readerToken), &pb.CreateTaskRequest{Title: "Title", Description: "Description"})
+	_, audit := client.GetAuditLog(withToken(testToken), &pb.EventsRequest{})
+	_, owner := client.GetTask(withToken(testToken), &pb.GetTaskRequest{Id: "task-1", Owner: "reader"})
+	stream, _ := client.ListTasks(withToken(readerToken), &pb.ListTasksRequest{})
+	_, listed := stream.Recv()
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(anonymous))
+	assert.Equal(t, codes.PermissionDenied, status.Code(reader))
+	assert.Equal(t, codes.PermissionDenied, status.Code(audit))
+	assert.Equal(t, codes.PermissionDenied, status.Code(owner))
+	assert.Equal(t, io.EOF, listed)
+}
+
+func TestGrpcTasks(t *testing.T) {
+	client := newClient(t)
+	ctx := withToken(testToken)
+
+	created, err := client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "Title", Description: "Description", Priority: proto.String("high")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated, updateErr := client.UpdateTask(ctx, &pb.UpdateTaskRequest{Id: created.Id, Version: proto.Int32(created.Version), Status: proto.String("done")})
+	_, staleErr := client.UpdateTask(ctx, &pb.UpdateTaskRequest{Id: created.Id, Version: proto.Int32(created.Version), Title: proto.String("Stale")})
+	_, emptyErr := client.UpdateTask(ctx, &pb.UpdateTaskRequest{Id: created.Id})
+	_, invalidErr := client.CreateTask(ctx, &pb.CreateTaskRequest{Description: "Description"})
+	deleted, deleteErr := client.DeleteTask(ctx, &pb.DeleteTaskRequest{Id: created.Id})
+	_, missingErr := client.GetTask(ctx, &pb.GetTaskRequest{Id: created.Id})
+	history, historyErr := client.GetTaskHistory(ctx, &pb.EventsRequest{Id: created.Id})
+
+	assert.Equal(t, "high", created.Priority)
+	assert.NoError(t, updateErr)
+	assert.True(t, updated.IsCompleted)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(staleErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(emptyErr))
+	assert.Equal(t, codes.InvalidArgument, status.Code(invalidErr))
+	assert.NoError(t, deleteErr)
+	assert.Equal(t, created.Id, deleted.Id)
+	assert.Equal(t, codes.NotFound, status.Code(missingErr))
+	assert.NoError(t, historyErr)
+	assert.Len(t, history.Events, 3)
+	assert.Equal(t, "done", history.Events[1].Changes[0].To.GetStringValue())
+
+	var fields []string
+	for _, detail := range status.Convert(invalidErr).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	assert.Equal(t, []string{"title"}, fields)
+}
+
+func TestGrpcClearDueAt(t *testing.T) {
+	client := newClient(t)
+	ctx := withToken(testToken)
+	created, err := client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "Title", Description: "Description", DueAt: timestamppb.New(time.Now().Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, bothErr := client.UpdateTask(ctx, &pb.UpdateTaskRequest{Id: created.Id, DueAt: timestamppb.Now(), ClearDueAt: true})
+	updated, updateErr := client.UpdateTask(ctx, &pb.UpdateTaskRequest{Id: created.Id, ClearDueAt: true})
+
+	assert.NotNil(t, created.DueAt)
+	assert.Equal(t, codes.InvalidArgument, status.Code(bothErr))
+	assert.NoError(t, updateErr)
+	assert.Nil(t, updated.DueAt)
+}
+
+func TestGrpcListTasks(t *testing.T) {
+	client := newClient(t)
+	ctx := withToken(testToken)
+	// more than one page of the service
+	for i := range listBatch + 5 {
+		client.CreateTask(ctx, &pb.CreateTaskRequest{Title: fmt.Sprintf("Task %d", i), Description: "Description"})
+	}
+
+	stream, err := client.ListTasks(ctx, &pb.ListTasksRequest{Sort: "created_at", Order: "asc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	titles := []string{}
+	for {
+		task, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		titles = append(titles, task.Title)
+	}
+
+	assert.Len(t, titles, listBatch+5)
+	assert.Equal(t, "Task 0", titles[0])
+	assert.Equal(t, fmt.Sprintf("Task %d", listBatch+4), titles[len(titles)-1])
+}
+
+func TestGrpcWatchTasks(t *testing.T) {
+	client := newClient(t)
+	ctx, cancel := context.WithTimeout(withToken(testToken), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchTasks(ctx, &pb.WatchTasksRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the headers come once the stream is subscribed
+	stream.Header()
+	created, _ := client.CreateTask(ctx, &pb.CreateTaskRequest{Title: "Title", Description: "Description"})
+	change, err := stream.Recv()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "created", change.Type)
+	assert.Equal(t, created.Id, change.Task.Id)
+
+	resumed, _ := client.WatchTasks(ctx, &pb.WatchTasksRequest{LastEventId: change.Id + 100})
+	reset, err := resumed.Recv()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "reset", reset.Type)
+}
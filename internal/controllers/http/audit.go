@@ -0,0 +1,102 @@
+package httpController
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// eventsQuery parses the query params of the change history listings. It
+// reports false after recording the error when a param is malformed.
+func eventsQuery(c *gin.Context) (services.EventsQuery, bool) {
+	query := services.EventsQuery{
+		ActorId: c.Query("actor"),
+		Cursor:  c.Query("cursor"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "limit",
+				Reason: "query param 'limit' must be an integer",
+			}))
+			return query, false
+		}
+		query.Limit = parsed
+	}
+	for _, param := range []struct {
+		name string
+		time **time.Time
+	}{
+		{"from", &query.From},
+		{"to", &query.To},
+	} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  param.name,
+				Reason: "query param '" + param.name + "' must be an RFC 3339 timestamp",
+			}))
+			return query, false
+		}
+		*param.time = &parsed
+	}
+
+	return query, true
+}
+
+// GetTaskHistory lists the changes made to a task, the oldest first.
+func (handler *routeHandler) GetTaskHistory(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	query, ok := eventsQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := handler.serviceHandler.GetTaskHistory(ownerId, c.Param("id"), query)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppParamError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
+}
+
+// GetAuditLog lists the changes made to the tasks of every owner, filtered
+// by 'actor' and the 'from' and 'to' times.
+func (handler *routeHandler) GetAuditLog(c *gin.Context) {
+	query, ok := eventsQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := handler.serviceHandler.GetAuditLog(query)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppParamError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
+}
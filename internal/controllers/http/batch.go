@@ -0,0 +1,106 @@
+package httpController
+
+import (
+	"net/http"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
+	entities "github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchItem is the outcome of one operation of a batch, with the status the
+// operation would have had as a request of its own.
+type BatchItem struct {
+	Op     string                `json:"op"`
+	Id     string                `json:"id,omitempty"`
+	Status int                   `json:"status"`
+	Task   *entities.Task        `json:"task,omitempty"`
+	Error  *httperrors.HttpError `json:"error,omitempty"`
+}
+
+// BatchResponse tells whether every operation of the batch was applied.
+type BatchResponse struct {
+	Applied bool        `json:"applied"`
+	Results []BatchItem `json:"results"`
+}
+
+// TaskMethod dispatches the custom methods of the task collection, like
+// /tasks:batch. Gin only routes a literal colon after Run, so the method
+// comes in as a param with its colon.
+func (handler *routeHandler) TaskMethod(c *gin.Context) {
+	switch c.Param("method") {
+	case ":batch":
+		handler.BatchTasks(c)
+	default:
+		c.Error(httperrors.NotFoundError())
+	}
+}
+
+func (handler *routeHandler) BatchTasks(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	var payload services.Batch
+	if err := c.BindJSON(&payload); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	results, err := handler.writer(c).RunBatch(ownerId, payload)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+
+	// a failed atomic batch answers with the status of the failed operation
+	status := http.StatusOK
+	response := BatchResponse{Applied: true, Results: []BatchItem{}}
+	for _, result := range results {
+		item := batchItem(result)
+		if item.Error != nil {
+			response.Applied = false
+			if payload.Atomic && !result.RolledBack {
+				status = item.Status
+			}
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	c.IndentedJSON(status, response)
+}
+
+func batchItem(result services.BatchResult) BatchItem {
+	item := BatchItem{
+		Op:   result.Op,
+		Id:   result.TaskId,
+		Task: result.Task,
+	}
+
+	switch {
+	case result.RolledBack:
+		item.Error = httperrors.FailedDependencyError()
+	case result.Err != nil:
+		appError, ok := result.Err.(*errors.AppError)
+		if ok {
+			item.Error = httperrors.FromAppError(appError)
+		} else {
+			item.Error = httperrors.InternalServerError(result.Err)
+		}
+	case result.Op == services.BatchCreate:
+		item.Status = http.StatusCreated
+	default:
+		item.Status = http.StatusOK
+	}
+	if item.Error != nil {
+		item.Status = item.Error.Status
+	}
+
+	return item
+}
@@ -3,7 +3,10 @@ package httperrors
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Arup3201/gotasks/internal/errors"
 )
@@ -11,11 +14,17 @@ import (
 const (
 	INCORRECT_CREDENTIAL = "INCORRECT_CREDENTIALS"
 	UNAUTHORIZED         = "NOT_AUTHORIZED"
+	FORBIDDEN            = "FORBIDDEN"
 	NO_OP                = "NO_MODIFICATION"
 	MISSING_BODY         = "MISSING_BODY_PROPERTY"
 	INVALID_BODY         = "INVALID_BODY_PROPERTY"
 	INVALID_PARAM        = "INVALID_PARAMETER_VALUE"
+	QUERY_LIMIT          = "QUERY_LIMIT_EXCEEDED"
 	NOT_FOUND            = "NOT_FOUND"
+	PRECONDITION_FAILED  = "PRECONDITION_FAILED"
+	FAILED_DEPENDENCY    = "FAILED_DEPENDENCY"
+	BAD_GATEWAY          = "BAD_GATEWAY"
+	SERVICE_UNAVAILABLE  = "SERVICE_UNAVAILABLE"
 	SERVER_ERROR         = "SERVER_ERROR"
 )
 
@@ -37,6 +46,8 @@ type ErrorField struct {
 type HttpError struct {
 	BaseError
 	Errors []ErrorField `json:"errors"`
+	// Headers are sent along with the error response
+	Headers map[string]string `json:"-"`
 }
 
 func (e *HttpError) Error() string {
@@ -57,9 +68,13 @@ func (e *HttpError) ResponseBody() ([]byte, error) {
 }
 
 func (e *HttpError) ResponseHeader() (int, map[string]string) {
-	return e.Status, map[string]string{
+	headers := map[string]string{
 		"Content-Type": "application/problem+json; charset=utf-8",
 	}
+	for key, value := range e.Headers {
+		headers[key] = value
+	}
+	return e.Status, headers
 }
 
 func New(errId string, errType string, title string, detail string, status int, code string, cause error, fields ...ErrorField) *HttpError {
@@ -94,10 +109,30 @@ func FromAppError(appError *errors.AppError) *HttpError {
 	if appError.Type == errors.NO_OPERATION {
 		return NoOpError()
 	}
+	if appError.Type == errors.PRECONDITION {
+		return PreconditionFailedError()
+	}
 
 	return InternalServerError(appError.Cause)
 }
 
+// FromAppParamError is FromAppError for request parameters, invalid input is
+// reported against the query instead of the body.
+func FromAppParamError(appError *errors.AppError) *HttpError {
+	if appError.Type == errors.INVALID_INPUT {
+		fields := []ErrorField{}
+		for _, errorField := range appError.Errors {
+			fields = append(fields, ErrorField{
+				Field:  errorField.Field,
+				Reason: errorField.Reason,
+			})
+		}
+		return InvalidRequestParamError(fields...)
+	}
+
+	return FromAppError(appError)
+}
+
 func IncorrectCredentialError() *HttpError {
 	return New(
 		INCORRECT_CREDENTIAL,
@@ -122,6 +157,18 @@ func UnauthorizedError() *HttpError {
 	)
 }
 
+func ForbiddenError() *HttpError {
+	return New(
+		FORBIDDEN,
+		"https://problems-registry.smartbear.com/forbidden",
+		"Forbidden",
+		"The access token does not grant the role needed for the requested resource",
+		http.StatusForbidden,
+		"403-01",
+		nil,
+	)
+}
+
 func InvalidBodyError(fields ...ErrorField) *HttpError {
 	return New(
 		INVALID_BODY,
@@ -161,6 +208,21 @@ func InvalidRequestParamError(fields ...ErrorField) *HttpError {
 	)
 }
 
+// QueryLimitError refuses a GraphQL query that is nested deeper, or would
+// resolve more fields, than the server allows.
+func QueryLimitError(fields ...ErrorField) *HttpError {
+	return New(
+		QUERY_LIMIT,
+		"about:blank",
+		"Query limit exceeded",
+		"The query is nested too deeply or selects too many fields, split it into smaller queries",
+		http.StatusBadRequest,
+		"400-10",
+		nil,
+		fields...,
+	)
+}
+
 func NotFoundError() *HttpError {
 	return New(
 		NOT_FOUND,
@@ -173,6 +235,18 @@ func NotFoundError() *HttpError {
 	)
 }
 
+func PreconditionFailedError() *HttpError {
+	return New(
+		PRECONDITION_FAILED,
+		"about:blank",
+		"Precondition failed",
+		"The resource was modified since it was read, fetch it again and retry with its new ETag",
+		http.StatusPreconditionFailed,
+		"412-01",
+		nil,
+	)
+}
+
 func NoOpError() *HttpError {
 	return New(
 		NO_OP,
@@ -185,6 +259,47 @@ func NoOpError() *HttpError {
 	)
 }
 
+func FailedDependencyError() *HttpError {
+	return New(
+		FAILED_DEPENDENCY,
+		"about:blank",
+		"Failed dependency",
+		"The operation was not applied because another operation of the batch failed",
+		http.StatusFailedDependency,
+		"424-01",
+		nil,
+	)
+}
+
+func BadGatewayError(cause error) *HttpError {
+	return New(
+		BAD_GATEWAY,
+		"https://problems-registry.smartbear.com/bad-gateway",
+		"Bad gateway",
+		"The authentication server answered with an error",
+		http.StatusBadGateway,
+		"502-01",
+		cause,
+	)
+}
+
+// ServiceUnavailableError asks the client to retry after retryAfter, rounded
+// up to whole seconds.
+func ServiceUnavailableError(retryAfter time.Duration, cause error) *HttpError {
+	httpError := New(
+		SERVICE_UNAVAILABLE,
+		"https://problems-registry.smartbear.com/service-unavailable",
+		"Service unavailable",
+		"The authentication server is not reachable, please try again later",
+		http.StatusServiceUnavailable,
+		"503-01",
+		cause,
+	)
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	httpError.Headers = map[string]string{"Retry-After": strconv.Itoa(max(seconds, 1))}
+	return httpError
+}
+
 func InternalServerError(cause error) *HttpError {
 	return New(
 		SERVER_ERROR,
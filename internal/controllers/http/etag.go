@@ -0,0 +1,68 @@
+package httpController
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+)
+
+// etag builds the strong entity tag of a task from its version.
+func etag(t *task.Task) string {
+	return fmt.Sprintf("\"%d\"", t.Version)
+}
+
+// parseETags splits an If-Match / If-None-Match header into its entity tags.
+// Weak tags keep their W/ prefix so callers can pick the comparison they need.
+func parseETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// tagVersion extracts the task version from a strong entity tag.
+func tagVersion(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// ifMatchVersions returns the versions an If-Match header accepts. A nil slice
+// with ok set means any version ("*" or no header), ok unset means the header
+// can never match since it only holds weak or malformed tags.
+func ifMatchVersions(header string) (versions []int, ok bool) {
+	tags := parseETags(header)
+	if len(tags) == 0 || slices.Contains(tags, "*") {
+		return nil, true
+	}
+	for _, tag := range tags {
+		if version, ok := tagVersion(tag); ok {
+			versions = append(versions, version)
+		}
+	}
+	return versions, len(versions) > 0
+}
+
+// noneMatch reports whether an If-None-Match header does not match the task,
+// using the weak comparison RFC 9110 requires for this header.
+func noneMatch(header string, t *task.Task) bool {
+	current := etag(t)
+	for _, tag := range parseETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return false
+		}
+	}
+	return true
+}
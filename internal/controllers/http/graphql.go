@@ -0,0 +1,53 @@
+package httpController
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Arup3201/gotasks/internal/auth"
+	graphqlController "github.com/Arup3201/gotasks/internal/controllers/graphql"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQL runs the query of a POST body, or of the 'query' param of a GET
+// which can't run mutations. The response is sent with 200 like GraphQL
+// clients expect, the errors of the operation are in its body.
+func (handler *routeHandler) GraphQL(c *gin.Context) {
+	var request graphqlController.Request
+
+	if c.Request.Method == http.MethodGet {
+		request.Query = c.Query("query")
+		request.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+					Field:  "variables",
+					Reason: "query param 'variables' must be a JSON object",
+				}))
+				return
+			}
+		}
+		request.ReadOnly = true
+	} else if err := c.BindJSON(&request); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	if request.Query == "" {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "query",
+			Reason: "GraphQL 'query' is required",
+		}))
+		return
+	}
+
+	claims := &auth.Claims{
+		UserId:   c.GetString(middlewares.USER_ID),
+		Username: c.GetString(middlewares.USERNAME),
+		Roles:    c.GetStringSlice(middlewares.ROLES),
+	}
+	result := handler.graphql.Execute(graphqlController.WithClaims(c.Request.Context(), claims), request)
+	c.JSON(http.StatusOK, result)
+}
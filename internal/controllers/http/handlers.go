@@ -1,14 +1,17 @@
 package httpController
 
 import (
-	"encoding/json"
+	stderrors "errors"
 	"fmt"
-	"log"
 	"net/http"
-	"net/url"
-	"strings"
+	"slices"
+	"strconv"
+	"time"
 
+	"github.com/Arup3201/gotasks/internal/auth"
+	graphqlController "github.com/Arup3201/gotasks/internal/controllers/graphql"
 	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
 	"github.com/Arup3201/gotasks/internal/errors"
 	"github.com/Arup3201/gotasks/internal/services"
 	"github.com/Arup3201/gotasks/internal/utils"
@@ -16,17 +19,38 @@ import (
 )
 
 type CreateTask struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	Priority    *string    `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	ParentId    *string    `json:"parent_id"`
+	Recurrence  *string    `json:"recurrence"`
+}
+
+type AddDependency struct {
+	BlockerId *string `json:"blocker_id"`
+}
+
+type SetTags struct {
+	Tags *[]string `json:"tags"`
 }
 
 type routeHandler struct {
 	serviceHandler services.ServiceHandler
+	authenticator  auth.Authenticator
+	// heartbeat is how often an idle change stream gets a comment, none
+	// when it is 0.
+	heartbeat time.Duration
+	// graphql runs the requests of /graphql, set by InitServer.
+	graphql *graphqlController.Executor
 }
 
-func GetRouteHandler(handler services.ServiceHandler) *routeHandler {
+func GetRouteHandler(handler services.ServiceHandler, authenticator auth.Authenticator) *routeHandler {
 	return &routeHandler{
 		serviceHandler: handler,
+		authenticator:  authenticator,
+		heartbeat:      utils.Config.EventHeartbeat,
 	}
 }
 
@@ -41,47 +65,286 @@ func (handler *routeHandler) Login(c *gin.Context) {
 		return
 	}
 
-	formValues := url.Values{}
-	formValues.Set("grant_type", "password")
-	formValues.Set("client_id", utils.Config.KeycloakClientId)
-	formValues.Set("client_secret", utils.Config.KeycloakClientSecret)
-	formValues.Set("username", credential.Username)
-	formValues.Set("password", credential.Password)
-	formValues.Set("scope", "openid")
-
-	request, err := http.NewRequest("POST", fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", utils.Config.KeycloakServerUrl, utils.Config.KeycloakRealName), strings.NewReader(formValues.Encode()))
+	token, err := handler.authenticator.Login(c.Request.Context(), credential.Username, credential.Password)
 	if err != nil {
-		log.Fatalf("http new request error: %v", err)
+		authError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+func (handler *routeHandler) RefreshToken(c *gin.Context) {
+	refreshToken, ok := bindRefreshToken(c)
+	if !ok {
+		return
 	}
-	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{}
-	response, err := client.Do(request)
+	token, err := handler.authenticator.Refresh(c.Request.Context(), refreshToken)
 	if err != nil {
-		log.Fatalf("http client do error: %v", err)
+		authError(c, err)
+		return
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusOK {
-		log.Printf("login error: keycloak token response error: got response with status %d", response.StatusCode)
-		c.Error(httperrors.IncorrectCredentialError())
+	c.JSON(http.StatusOK, token)
+}
+
+func (handler *routeHandler) Logout(c *gin.Context) {
+	refreshToken, ok := bindRefreshToken(c)
+	if !ok {
+		return
+	}
+
+	if err := handler.authenticator.Logout(c.Request.Context(), refreshToken); err != nil {
+		authError(c, err)
 		return
 	}
 
-	var token struct {
-		AccessToken string `json:"access_token"`
+	c.Status(http.StatusNoContent)
+}
+
+// Me is the identity of the caller as the authenticator sees it.
+type Me struct {
+	UserId   string   `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+}
+
+func (handler *routeHandler) GetMe(c *gin.Context) {
+	roles := c.GetStringSlice(middlewares.ROLES)
+	if roles == nil {
+		roles = []string{}
+	}
+
+	c.IndentedJSON(http.StatusOK, Me{
+		UserId:   c.GetString(middlewares.USER_ID),
+		Username: c.GetString(middlewares.USERNAME),
+		Roles:    roles,
+	})
+}
+
+// bindRefreshToken reads the 'refresh_token' of the body. It reports false
+// after recording the error when there is none.
+func bindRefreshToken(c *gin.Context) (string, bool) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := c.BindJSON(&payload); err != nil {
+		c.Error(httperrors.InternalServerError(fmt.Errorf("c.BindJSON failed with error %v", err)))
+		return "", false
 	}
-	if err = json.NewDecoder(response.Body).Decode(&token); err != nil {
-		log.Printf("login error: keycloak token response encoding error: %v", err)
+	if payload.RefreshToken == "" {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "refresh_token",
+			Reason: "'refresh_token' is required",
+		}))
+		return "", false
+	}
+
+	return payload.RefreshToken, true
+}
+
+// authError records the error of a login, refresh or logout.
+func authError(c *gin.Context, err error) {
+	switch {
+	case stderrors.Is(err, auth.ErrInvalidCredentials):
 		c.Error(httperrors.IncorrectCredentialError())
+	case stderrors.Is(err, auth.ErrUnauthenticated):
+		c.Error(httperrors.UnauthorizedError())
+	case stderrors.Is(err, auth.ErrLoginUnsupported):
+		c.Error(httperrors.NotFoundError())
+	default:
+		c.Error(middlewares.AuthServerError(err))
+	}
+}
+
+func (handler *routeHandler) GetTasks(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, token)
+	query, ok := listTasksQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := handler.serviceHandler.GetAllTasks(ownerId, query)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppParamError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
 }
 
-func (handler *routeHandler) GetTasks(c *gin.Context) {
-	tasks, err := handler.serviceHandler.GetAllTasks()
+func (handler *routeHandler) GetTrash(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	query, ok := listTasksQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := handler.serviceHandler.GetTrash(ownerId, query)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppParamError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
+}
+
+// GetSubtasks lists the direct subtasks of a task, with the params of the
+// task listing.
+func (handler *routeHandler) GetSubtasks(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	query, ok := listTasksQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := handler.serviceHandler.GetSubtasks(ownerId, c.Param("id"), query)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppParamError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
+}
+
+func (handler *routeHandler) GetTaskTree(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	tree, err := handler.serviceHandler.GetTaskTree(ownerId, c.Param("id"))
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, tree)
+}
+
+// AddDependency blocks the task by the task in 'blocker_id', and responds
+// with every blocker of the task.
+func (handler *routeHandler) AddDependency(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	var payload AddDependency
+	if err := c.BindJSON(&payload); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+	if payload.BlockerId == nil || *payload.BlockerId == "" {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "blocker_id",
+			Reason: "Task 'blocker_id' is required",
+		}))
+		return
+	}
+
+	blockers, err := handler.serviceHandler.AddDependency(ownerId, c.Param("id"), *payload.BlockerId)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, blockers)
+}
+
+func (handler *routeHandler) RemoveDependency(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	blockers, err := handler.serviceHandler.RemoveDependency(ownerId, c.Param("id"), c.Param("blocker_id"))
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, blockers)
+}
+
+func (handler *routeHandler) GetDependencies(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	blockers, err := handler.serviceHandler.GetBlockers(ownerId, c.Param("id"))
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, blockers)
+}
+
+func (handler *routeHandler) GetBlocking(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	blocking, err := handler.serviceHandler.GetBlocking(ownerId, c.Param("id"))
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, blocking)
+}
+
+// GetPlan lists the open tasks, each after the tasks that block it.
+func (handler *routeHandler) GetPlan(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	plan, err := handler.serviceHandler.GetPlan(ownerId)
 	if err != nil {
 		appError, ok := err.(*errors.AppError)
 		if ok {
@@ -91,14 +354,87 @@ func (handler *routeHandler) GetTasks(c *gin.Context) {
 		}
 		return
 	}
-	c.IndentedJSON(http.StatusOK, tasks)
+	c.IndentedJSON(http.StatusOK, plan)
+}
+
+// readOwner is the owner whose tasks a read request is about, the user
+// itself unless an admin names another owner with the 'owner' param. It
+// reports false after recording the error when the user can't read them.
+func readOwner(c *gin.Context) (string, bool) {
+	userId := c.GetString(middlewares.USER_ID)
+	owner := c.Query("owner")
+	if owner == "" || owner == userId {
+		return userId, true
+	}
+	if !middlewares.HasRole(c, auth.RoleAdmin) {
+		c.Error(httperrors.ForbiddenError())
+		return "", false
+	}
+	return owner, true
+}
+
+// writer is the service handler for the writes of a request, they are
+// recorded as made by the authenticated user.
+func (handler *routeHandler) writer(c *gin.Context) services.ServiceHandler {
+	return handler.serviceHandler.WithActor(c.GetString(middlewares.USER_ID))
+}
+
+// listTasksQuery parses the query params shared by the task listings. It
+// reports false after recording the error when a param is malformed.
+func listTasksQuery(c *gin.Context) (services.ListTasksQuery, bool) {
+	query := services.ListTasksQuery{
+		Cursor: c.Query("cursor"),
+		SortBy: c.Query("sort"),
+		Order:  c.Query("order"),
+		// every 'tag' param is a tag, 'tag_match' picks any or all of them
+		Tags:     c.QueryArray("tag"),
+		TagMatch: c.Query("tag_match"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "limit",
+				Reason: "query param 'limit' must be an integer",
+			}))
+			return query, false
+		}
+		query.Limit = parsed
+	}
+	if isCompleted := c.Query("is_completed"); isCompleted != "" {
+		parsed, err := strconv.ParseBool(isCompleted)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "is_completed",
+				Reason: "query param 'is_completed' must be true or false",
+			}))
+			return query, false
+		}
+		query.IsCompleted = &parsed
+	}
+	if createdAfter := c.Query("created_after"); createdAfter != "" {
+		parsed, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "created_after",
+				Reason: "query param 'created_after' must be an RFC 3339 timestamp",
+			}))
+			return query, false
+		}
+		query.CreatedAfter = &parsed
+	}
+
+	return query, true
 }
 
 func (handler *routeHandler) AddTask(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
 	var payload CreateTask
 
 	if err := c.BindJSON(&payload); err != nil {
-		c.Error(httperrors.InternalServerError(fmt.Errorf("c.BindJSON failed with error %v", err)))
+		c.Error(bindError(err))
 		return
 	}
 
@@ -117,7 +453,15 @@ func (handler *routeHandler) AddTask(c *gin.Context) {
 		return
 	}
 
-	newTask, err := handler.serviceHandler.CreateTask(*payload.Title, *payload.Description)
+	newTask, err := handler.writer(c).CreateTask(ownerId, services.CreateTaskData{
+		Title:       payload.Title,
+		Description: payload.Description,
+		Status:      payload.Status,
+		Priority:    payload.Priority,
+		DueAt:       payload.DueAt,
+		ParentId:    payload.ParentId,
+		Recurrence:  payload.Recurrence,
+	})
 	if err != nil {
 		appError, ok := err.(*errors.AppError)
 		if ok {
@@ -128,13 +472,30 @@ func (handler *routeHandler) AddTask(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(newTask))
 	c.IndentedJSON(http.StatusCreated, newTask)
 }
 
+// bindError maps a task payload that can't be decoded, a malformed 'due_at'
+// is reported like any other invalid field.
+func bindError(err error) *httperrors.HttpError {
+	if _, ok := err.(*time.ParseError); ok {
+		return httperrors.InvalidBodyError(httperrors.ErrorField{
+			Field:  "due_at",
+			Reason: "Task 'due_at' must be an RFC 3339 timestamp",
+		})
+	}
+	return httperrors.InternalServerError(fmt.Errorf("c.BindJSON failed with error %v", err))
+}
+
 func (handler *routeHandler) GetTask(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
 
-	task, err := handler.serviceHandler.GetTask(id)
+	task, err := handler.serviceHandler.GetTask(ownerId, id)
 	if err != nil {
 		appError, ok := err.(*errors.AppError)
 		if ok {
@@ -145,24 +506,98 @@ func (handler *routeHandler) GetTask(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(task))
+	if inm := c.GetHeader("If-None-Match"); inm != "" && !noneMatch(inm, task) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
 	c.IndentedJSON(http.StatusOK, task)
 }
 
+// ifMatch resolves the If-Match header of a write request to the task
+// version the write must be conditioned on, nil meaning any version. It
+// reports false after recording the error when the request can't proceed.
+func (handler *routeHandler) ifMatch(c *gin.Context, ownerId, id string) (*int, bool) {
+	versions, ok := ifMatchVersions(c.GetHeader("If-Match"))
+	if !ok {
+		c.Error(httperrors.PreconditionFailedError())
+		return nil, false
+	}
+	if len(versions) == 0 {
+		return nil, true
+	}
+	if len(versions) == 1 {
+		return &versions[0], true
+	}
+
+	current, err := handler.serviceHandler.GetTask(ownerId, id)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return nil, false
+	}
+	if !slices.Contains(versions, current.Version) {
+		c.Error(httperrors.PreconditionFailedError())
+		return nil, false
+	}
+	return &current.Version, true
+}
+
+// seriesScope reads the 'scope' query param of a write to a task, it reports
+// whether the write applies to every occurrence of its series, and false
+// after recording the error when the param is invalid.
+func seriesScope(c *gin.Context) (bool, bool) {
+	switch c.Query("scope") {
+	case "", "this":
+		return false, true
+	case "series":
+		return true, true
+	default:
+		c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+			Field:  "scope",
+			Reason: "query param 'scope' can only be 'this' or 'series'",
+		}))
+		return false, false
+	}
+}
+
 func (handler *routeHandler) UpdateTask(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
 	id := c.Param("id")
 
 	var payload services.UpdateTaskData
 	if err := c.BindJSON(&payload); err != nil {
-		c.Error(httperrors.InternalServerError(fmt.Errorf("c.BindJSON failed with error %v", err)))
+		c.Error(bindError(err))
 		return
 	}
 
-	if payload.Title == nil && payload.Description == nil && payload.IsCompleted == nil {
+	if payload.Title == nil && payload.Description == nil && payload.Status == nil &&
+		payload.Priority == nil && payload.DueAt == nil && !payload.ClearDueAt && payload.ParentId == nil && payload.IsCompleted == nil && payload.Recurrence == nil {
 		c.Error(httperrors.NoOpError())
 		return
 	}
 
-	editedTask, err := handler.serviceHandler.UpdateTask(id, payload)
+	series, ok := seriesScope(c)
+	if !ok {
+		return
+	}
+
+	version, ok := handler.ifMatch(c, ownerId, id)
+	if !ok {
+		return
+	}
+
+	writer := handler.writer(c)
+	update := writer.UpdateTask
+	if series {
+		update = writer.UpdateSeries
+	}
+	editedTask, err := update(ownerId, id, version, payload)
 	if err != nil {
 		appError, ok := err.(*errors.AppError)
 		if ok {
@@ -173,13 +608,30 @@ func (handler *routeHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(editedTask))
 	c.IndentedJSON(http.StatusOK, editedTask)
 }
 
 func (handler *routeHandler) DeleteTask(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
 	id := c.Param("id")
 
-	taskId, err := handler.serviceHandler.DeleteTask(id)
+	series, ok := seriesScope(c)
+	if !ok {
+		return
+	}
+
+	version, ok := handler.ifMatch(c, ownerId, id)
+	if !ok {
+		return
+	}
+
+	writer := handler.writer(c)
+	remove := writer.DeleteTask
+	if series {
+		remove = writer.DeleteSeries
+	}
+	taskId, err := remove(ownerId, id, version)
 	if err != nil {
 		appError, ok := err.(*errors.AppError)
 		if ok {
@@ -193,7 +645,88 @@ func (handler *routeHandler) DeleteTask(c *gin.Context) {
 	c.IndentedJSON(http.StatusOK, taskId)
 }
 
+func (handler *routeHandler) RestoreTask(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+	id := c.Param("id")
+
+	restoredTask, err := handler.writer(c).RestoreTask(ownerId, id)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+
+	c.Header("ETag", etag(restoredTask))
+	c.IndentedJSON(http.StatusOK, restoredTask)
+}
+
+func (handler *routeHandler) SetTaskTags(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+	id := c.Param("id")
+
+	var payload SetTags
+	if err := c.BindJSON(&payload); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	if payload.Tags == nil {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "tags",
+			Reason: "Task 'tags' is required",
+		}))
+		return
+	}
+
+	version, ok := handler.ifMatch(c, ownerId, id)
+	if !ok {
+		return
+	}
+
+	taggedTask, err := handler.writer(c).SetTaskTags(ownerId, id, version, *payload.Tags)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+
+	c.Header("ETag", etag(taggedTask))
+	c.IndentedJSON(http.StatusOK, taggedTask)
+}
+
+func (handler *routeHandler) GetTags(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
+
+	tags, err := handler.serviceHandler.GetTags(ownerId)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, tags)
+}
+
 func (handler *routeHandler) SearchTasks(c *gin.Context) {
+	ownerId, ok := readOwner(c)
+	if !ok {
+		return
+	}
 	var query string = c.Query("q")
 	if query == "" {
 		c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
@@ -203,16 +736,32 @@ func (handler *routeHandler) SearchTasks(c *gin.Context) {
 		return
 	}
 
-	tasks, err := handler.serviceHandler.SearchTasks(query)
+	searchQuery := services.SearchTasksQuery{
+		Query:  query,
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "limit",
+				Reason: "query param 'limit' must be an integer",
+			}))
+			return
+		}
+		searchQuery.Limit = parsed
+	}
+
+	page, err := handler.serviceHandler.SearchTasks(ownerId, searchQuery)
 	if err != nil {
 		appError, ok := err.(*errors.AppError)
 		if ok {
-			c.Error(httperrors.FromAppError(appError))
+			c.Error(httperrors.FromAppParamError(appError))
 		} else {
 			c.Error(httperrors.InternalServerError(err))
 		}
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, tasks)
+	c.IndentedJSON(http.StatusOK, page)
 }
@@ -1,6 +1,7 @@
 package httpController
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Arup3201/gotasks/internal/auth"
 	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
 	entities "github.com/Arup3201/gotasks/internal/entities/task"
 	services "github.com/Arup3201/gotasks/internal/services/domain/task"
@@ -39,7 +41,10 @@ func generateTasks(num int, t testing.TB) []entities.Task {
 			Id:          id,
 			Title:       fmt.Sprintf("Task %d", i+1),
 			Description: "No description",
+			Status:      entities.StatusTodo,
+			Priority:    entities.PriorityMedium,
 			IsCompleted: false,
+			Version:     1,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		})
@@ -54,7 +59,7 @@ func TestGetTasks(t *testing.T) {
 			tasks: generateTasks(2, t),
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("GET", "/tasks", nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -62,14 +67,14 @@ func TestGetTasks(t *testing.T) {
 
 		engine.ServeHTTP(response, ctx.Request)
 
-		var got []entities.Task
+		var got struct{ Tasks []entities.Task }
 		want := 2
 		err := json.NewDecoder(response.Body).Decode(&got)
 		if err != nil {
 			log.Fatal("JSON decoding failed")
 		}
-		if len(got) != want {
-			t.Errorf("response is wrong, expected %d tasks but got %d tasks", want, len(got))
+		if len(got.Tasks) != want {
+			t.Errorf("response is wrong, expected %d tasks but got %d tasks", want, len(got.Tasks))
 		}
 	})
 	t.Run("get no tasks", func(t *testing.T) {
@@ -77,7 +82,7 @@ func TestGetTasks(t *testing.T) {
 			tasks: []entities.Task{},
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("GET", "/tasks", nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -87,14 +92,14 @@ func TestGetTasks(t *testing.T) {
 
 		routeHandler.GetTasks(ctx)
 
-		var got []entities.Task
+		var got struct{ Tasks []entities.Task }
 		want := 0
 		err := json.NewDecoder(response.Body).Decode(&got)
 		if err != nil {
 			log.Fatal("JSON decoding failed")
 		}
-		if len(got) != want {
-			t.Errorf("response is wrong, expected %d tasks but got %d tasks", want, len(got))
+		if len(got.Tasks) != want {
+			t.Errorf("response is wrong, expected %d tasks but got %d tasks", want, len(got.Tasks))
 		}
 	})
 }
@@ -105,7 +110,7 @@ func TestAddTask(t *testing.T) {
 			tasks: []entities.Task{},
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"title": "Test task", 
 			"description": "Test description"
@@ -141,7 +146,7 @@ func TestAddTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"title": "Test task", 
 			"description": "Test description"
@@ -162,14 +167,14 @@ func TestAddTask(t *testing.T) {
 		engine.GET("/tasks", routeHandler.GetTasks)
 		engine.ServeHTTP(response, ctx.Request)
 		routeHandler.GetTasks(ctx)
-		var allTasks []entities.Task
+		var allTasks struct{ Tasks []entities.Task }
 		want := 2
 		err = json.NewDecoder(response.Body).Decode(&allTasks)
 		if err != nil {
 			log.Fatal("JSON decoding failed")
 		}
-		if len(allTasks) != want {
-			t.Errorf("response is wrong, expected %d tasks but got %d tasks", want, len(allTasks))
+		if len(allTasks.Tasks) != want {
+			t.Errorf("response is wrong, expected %d tasks but got %d tasks", want, len(allTasks.Tasks))
 		}
 	})
 	t.Run("add task fail for missing title", func(t *testing.T) {
@@ -177,7 +182,7 @@ func TestAddTask(t *testing.T) {
 			tasks: []entities.Task{},
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"description": "Test description"
 		}`)
@@ -204,7 +209,7 @@ func TestGetTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s", tasks[1].Id), nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -228,7 +233,7 @@ func TestGetTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("GET", "/tasks/abcde109", nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -244,6 +249,64 @@ func TestGetTask(t *testing.T) {
 	})
 }
 
+func TestTaskOwnership(t *testing.T) {
+	t.Run("get task of another owner not found", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		tasks[0].OwnerId = "owner-1"
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s", tasks[0].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.Use(func(c *gin.Context) {
+			c.Set(middlewares.USER_ID, "owner-2")
+		})
+		engine.GET("/tasks/:id", routeHandler.GetTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusNotFound
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("should return NotFound error, expected status code %d but got %d", want, got)
+		}
+	})
+	t.Run("created task belongs to the authenticated user", func(t *testing.T) {
+		repo := &MockRepository{
+			tasks: []entities.Task{},
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{
+			"title": "Test task",
+			"description": "Test description"
+		}`)
+		request, _ := http.NewRequest("POST", "/tasks", payload)
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(func(c *gin.Context) {
+			c.Set(middlewares.USER_ID, "owner-1")
+		})
+		engine.POST("/tasks", routeHandler.AddTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		var got entities.Task
+		err := json.NewDecoder(response.Body).Decode(&got)
+		if err != nil {
+			log.Fatal("JSON decoding failed")
+		}
+		want := "owner-1"
+		if got.OwnerId != want {
+			t.Errorf("expected task owner %s but got %s", want, got.OwnerId)
+		}
+	})
+}
+
 func TestUpdateTask(t *testing.T) {
 	t.Run("update task with new title", func(t *testing.T) {
 		tasks := generateTasks(2, t)
@@ -251,7 +314,7 @@ func TestUpdateTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"title": "Test 2 (edited)"
 		}`)
@@ -278,7 +341,7 @@ func TestUpdateTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"description": "Test 2 description (edited)"
 		}`)
@@ -305,7 +368,7 @@ func TestUpdateTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"is_completed": true
 		}`)
@@ -332,7 +395,7 @@ func TestUpdateTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{
 			"title": "Test 3 (edited)"
 		}`)
@@ -355,7 +418,7 @@ func TestUpdateTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		payload := strings.NewReader(`{}`)
 		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), payload)
 		response := httptest.NewRecorder()
@@ -379,7 +442,7 @@ func TestDeleteTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("DELETE", fmt.Sprintf("/tasks/%s", tasks[1].Id), nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -402,7 +465,7 @@ func TestDeleteTask(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("DELETE", "/tasks/abcd109", nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -418,6 +481,410 @@ func TestDeleteTask(t *testing.T) {
 	})
 }
 
+func TestTaskPlanning(t *testing.T) {
+	t.Run("add a task with priority and due date", func(t *testing.T) {
+		repo := &MockRepository{
+			tasks: []entities.Task{},
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{
+			"title": "Test task",
+			"description": "Test description",
+			"priority": "high",
+			"due_at": "2030-01-02T15:04:05Z"
+		}`)
+		request, _ := http.NewRequest("POST", "/tasks", payload)
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.POST("/tasks", routeHandler.AddTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		var got entities.Task
+		err := json.NewDecoder(response.Body).Decode(&got)
+		if err != nil {
+			log.Fatal("JSON decoding failed")
+		}
+		if got.Priority != entities.PriorityHigh || got.Status != entities.StatusTodo {
+			t.Errorf("expected a high priority todo, but got %s of %s priority", got.Status, got.Priority)
+		}
+		want := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+		if got.DueAt == nil || !got.DueAt.Equal(want) {
+			t.Errorf("expected due date %v, but got %v", want, got.DueAt)
+		}
+	})
+	t.Run("add a task fail with malformed due date", func(t *testing.T) {
+		repo := &MockRepository{
+			tasks: []entities.Task{},
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{
+			"title": "Test task",
+			"description": "Test description",
+			"due_at": "tomorrow"
+		}`)
+		request, _ := http.NewRequest("POST", "/tasks", payload)
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.POST("/tasks", routeHandler.AddTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusBadRequest
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected status code %d but got %d", want, got)
+		}
+	})
+	t.Run("update task fail with invalid transition", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		tasks[0].SetStatus(entities.StatusBlocked)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{"status": "done"}`)
+		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), payload)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.PATCH("/tasks/:id", routeHandler.UpdateTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusBadRequest
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected status code %d but got %d", want, got)
+		}
+		if repo.tasks[0].Status != entities.StatusBlocked {
+			t.Errorf("task status should not change, but got %s", repo.tasks[0].Status)
+		}
+	})
+	t.Run("update task clears a null due date", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		dueAt := time.Now().Add(time.Hour)
+		tasks[0].DueAt = &dueAt
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{"due_at": null}`)
+		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), payload)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.PATCH("/tasks/:id", routeHandler.UpdateTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		if got := response.Result().StatusCode; got != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, got)
+		}
+		if repo.tasks[0].DueAt != nil {
+			t.Errorf("expected the due date to be cleared, but got %v", repo.tasks[0].DueAt)
+		}
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	t.Run("get task returns its etag", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s", tasks[0].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.GET("/tasks/:id", routeHandler.GetTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := `"1"`
+		if got := response.Header().Get("ETag"); got != want {
+			t.Errorf("expected ETag %s but got %s", want, got)
+		}
+	})
+	t.Run("get task not modified", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s", tasks[0].Id), nil)
+		request.Header.Set("If-None-Match", `W/"1"`)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.GET("/tasks/:id", routeHandler.GetTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusNotModified
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected status code %d but got %d", want, got)
+		}
+		if response.Body.Len() != 0 {
+			t.Errorf("expected empty body, but got %s", response.Body.String())
+		}
+	})
+	t.Run("update task with matching etag", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{"title": "Task 1 (edited)"}`)
+		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), payload)
+		request.Header.Set("If-Match", `"1"`)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.PATCH("/tasks/:id", routeHandler.UpdateTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		if got := response.Result().StatusCode; got != http.StatusOK {
+			t.Errorf("expected status code %d but got %d", http.StatusOK, got)
+		}
+		want := `"2"`
+		if got := response.Header().Get("ETag"); got != want {
+			t.Errorf("expected ETag %s but got %s", want, got)
+		}
+	})
+	t.Run("update task with stale etag fail", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		tasks[0].Version = 2
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{"title": "Task 1 (edited)"}`)
+		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), payload)
+		request.Header.Set("If-Match", `"1"`)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.PATCH("/tasks/:id", routeHandler.UpdateTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusPreconditionFailed
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected status code %d but got %d", want, got)
+		}
+	})
+	t.Run("update task with any of several etags", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		tasks[0].Version = 3
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		payload := strings.NewReader(`{"title": "Task 1 (edited)"}`)
+		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), payload)
+		request.Header.Set("If-Match", `"2", "3"`)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.PATCH("/tasks/:id", routeHandler.UpdateTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusOK
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected status code %d but got %d", want, got)
+		}
+	})
+	t.Run("delete task with weak etag fail", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("DELETE", fmt.Sprintf("/tasks/%s", tasks[0].Id), nil)
+		request.Header.Set("If-Match", `W/"1"`)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.DELETE("/tasks/:id", routeHandler.DeleteTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusPreconditionFailed
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected status code %d but got %d", want, got)
+		}
+		if len(repo.tasks) != 1 {
+			t.Errorf("task should not be deleted")
+		}
+	})
+}
+
+func TestTrash(t *testing.T) {
+	t.Run("deleted task is listed in the trash", func(t *testing.T) {
+		tasks := generateTasks(2, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		repo.Delete("", tasks[0].Id, nil)
+		request, _ := http.NewRequest("GET", "/tasks/trash", nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.GET("/tasks/trash", routeHandler.GetTrash)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		var got struct{ Tasks []entities.Task }
+		err := json.NewDecoder(response.Body).Decode(&got)
+		if err != nil {
+			log.Fatal("JSON decoding failed")
+		}
+		if len(got.Tasks) != 1 || got.Tasks[0].Id != tasks[0].Id {
+			t.Errorf("expected only the deleted task in the trash, but got %v", got.Tasks)
+		}
+	})
+	t.Run("restore task success", func(t *testing.T) {
+		tasks := generateTasks(2, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		repo.Delete("", tasks[1].Id, nil)
+		request, _ := http.NewRequest("POST", fmt.Sprintf("/tasks/%s/restore", tasks[1].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.POST("/tasks/:id/restore", routeHandler.RestoreTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusOK
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("restore failed, expected status code %d but got %d", want, got)
+		}
+		if _, err := repo.Get("", tasks[1].Id); err != nil {
+			t.Errorf("expected restored task to be found, but got %v", err)
+		}
+	})
+	t.Run("restore task not in trash fail", func(t *testing.T) {
+		tasks := generateTasks(2, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("POST", fmt.Sprintf("/tasks/%s/restore", tasks[0].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.POST("/tasks/:id/restore", routeHandler.RestoreTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusNotFound
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected NotFound error %d, but got %d", want, got)
+		}
+	})
+}
+
+func TestTaskTags(t *testing.T) {
+	t.Run("set task tags success", func(t *testing.T) {
+		tasks := generateTasks(2, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("PUT", fmt.Sprintf("/tasks/%s/tags", tasks[0].Id), strings.NewReader(`{"tags": ["Work", "home"]}`))
+		request.Header.Set("If-Match", `"1"`)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.PUT("/tasks/:id/tags", routeHandler.SetTaskTags)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusOK
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("set tags failed, expected status code %d but got %d", want, got)
+		}
+		if got := response.Header().Get("ETag"); got != `"2"` {
+			t.Errorf("expected ETag \"2\", but got %s", got)
+		}
+		var got entities.Task
+		json.NewDecoder(response.Body).Decode(&got)
+		if strings.Join(got.Tags, ",") != "home,work" {
+			t.Errorf("expected tags [home work], but got %v", got.Tags)
+		}
+	})
+	t.Run("set task tags without tags fail", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("PUT", fmt.Sprintf("/tasks/%s/tags", tasks[0].Id), strings.NewReader(`{}`))
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.PUT("/tasks/:id/tags", routeHandler.SetTaskTags)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusBadRequest
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected BadRequest error %d, but got %d", want, got)
+		}
+	})
+	t.Run("get tags and filter tasks by tag", func(t *testing.T) {
+		tasks := generateTasks(3, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		repo.SetTags("", tasks[0].Id, nil, []string{"home", "work"})
+		repo.SetTags("", tasks[1].Id, nil, []string{"work"})
+
+		request, _ := http.NewRequest("GET", "/tags", nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.GET("/tags", routeHandler.GetTags)
+		engine.GET("/tasks", routeHandler.GetTasks)
+		engine.ServeHTTP(response, ctx.Request)
+
+		var tags struct{ Tags []entities.TagCount }
+		json.NewDecoder(response.Body).Decode(&tags)
+		if len(tags.Tags) != 2 || tags.Tags[1] != (entities.TagCount{Name: "work", Count: 2}) {
+			t.Errorf("expected tags home(1) and work(2), but got %v", tags.Tags)
+		}
+
+		request, _ = http.NewRequest("GET", "/tasks?tag=home&tag=work&tag_match=all", nil)
+		response = httptest.NewRecorder()
+		engine.ServeHTTP(response, request)
+
+		var page struct{ Tasks []entities.Task }
+		json.NewDecoder(response.Body).Decode(&page)
+		if len(page.Tasks) != 1 || page.Tasks[0].Id != tasks[0].Id {
+			t.Errorf("expected only the first task with all of the tags, but got %v", page.Tasks)
+		}
+	})
+}
+
 func TestSearchTasks(t *testing.T) {
 	t.Run("search tasks success", func(t *testing.T) {
 		tasks := generateTasks(2, t)
@@ -425,7 +892,7 @@ func TestSearchTasks(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("GET", "/search/tasks?q=2", nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -433,14 +900,14 @@ func TestSearchTasks(t *testing.T) {
 		engine.GET("/search/tasks", routeHandler.SearchTasks)
 		engine.ServeHTTP(response, ctx.Request)
 
-		var got []entities.Task
+		var got struct{ Tasks []entities.Task }
 		err := json.NewDecoder(response.Body).Decode(&got)
 		if err != nil {
 			log.Fatal("JSON decoding failed")
 		}
 		want := 1
-		if len(got) != want {
-			t.Errorf("response is wrong, expected %d searched tasks but got %d tasks", want, len(got))
+		if len(got.Tasks) != want {
+			t.Errorf("response is wrong, expected %d searched tasks but got %d tasks", want, len(got.Tasks))
 		}
 	})
 	t.Run("search tasks no task found", func(t *testing.T) {
@@ -449,7 +916,7 @@ func TestSearchTasks(t *testing.T) {
 			tasks: tasks,
 		}
 		serviceHandler, _ := services.NewTaskService(repo)
-		routeHandler := GetRouteHandler(serviceHandler)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
 		request, _ := http.NewRequest("GET", "/search/tasks?q=3", nil)
 		response := httptest.NewRecorder()
 		ctx, engine := getTestContext(t, response, request)
@@ -457,14 +924,249 @@ func TestSearchTasks(t *testing.T) {
 		engine.GET("/search/tasks", routeHandler.SearchTasks)
 		engine.ServeHTTP(response, ctx.Request)
 
-		var got []entities.Task
+		var got struct{ Tasks []entities.Task }
 		err := json.NewDecoder(response.Body).Decode(&got)
 		if err != nil {
 			log.Fatal("JSON decoding failed")
 		}
 		want := 0
-		if len(got) != want {
-			t.Errorf("response is wrong, expected %d searched tasks but got %d tasks", want, len(got))
+		if len(got.Tasks) != want {
+			t.Errorf("response is wrong, expected %d searched tasks but got %d tasks", want, len(got.Tasks))
+		}
+	})
+}
+
+func TestAuthServerOutage(t *testing.T) {
+	t.Run("unreachable auth server gives 503 with retry after", func(t *testing.T) {
+		authenticator := &auth.MockAuthenticator{Err: &auth.UnavailableError{RetryAfter: 1500 * time.Millisecond, Err: context.DeadlineExceeded}}
+		routeHandler := GetRouteHandler(nil, authenticator)
+		request, _ := http.NewRequest("POST", "/login", strings.NewReader(`{"username": "alice", "password": "secret"}`))
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.POST("/login", routeHandler.Login)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		if response.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, but got %d", http.StatusServiceUnavailable, response.Code)
+		}
+		if retryAfter := response.Header().Get("Retry-After"); retryAfter != "2" {
+			t.Errorf("expected Retry-After 2, but got %q", retryAfter)
+		}
+	})
+	t.Run("auth server error gives 502", func(t *testing.T) {
+		authenticator := &auth.MockAuthenticator{Err: fmt.Errorf("%w: got response with status 500", auth.ErrBadGateway)}
+		request, _ := http.NewRequest("GET", "/tasks", nil)
+		request.Header.Set("Authorization", "Bearer token")
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.Use(middlewares.Authenticate(authenticator, []string{"/tasks"}))
+		engine.GET("/tasks", func(c *gin.Context) {
+			t.Error("request should not pass authentication")
+		})
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		if response.Code != http.StatusBadGateway {
+			t.Errorf("expected status %d, but got %d", http.StatusBadGateway, response.Code)
+		}
+	})
+}
+
+func TestSubtasks(t *testing.T) {
+	t.Run("task tree with progress", func(t *testing.T) {
+		tasks := generateTasks(3, t)
+		tasks[1].ParentId = &tasks[0].Id
+		tasks[2].ParentId = &tasks[0].Id
+		tasks[2].Status, tasks[2].IsCompleted = entities.StatusDone, true
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s/tree", tasks[0].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.GET("/tasks/:id/tree", routeHandler.GetTaskTree)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		var got struct {
+			Progress struct{ Done, Total int }
+			Subtasks []any
+		}
+		err := json.NewDecoder(response.Body).Decode(&got)
+		if err != nil {
+			log.Fatal("JSON decoding failed")
+		}
+		if got.Progress.Done != 1 || got.Progress.Total != 2 || len(got.Subtasks) != 2 {
+			t.Errorf("expected 1 of 2 subtasks done, but got %+v", got)
+		}
+	})
+	t.Run("subtasks of a missing task fail", func(t *testing.T) {
+		repo := &MockRepository{
+			tasks: generateTasks(1, t),
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", "/tasks/missing-task/subtasks", nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.GET("/tasks/:id/subtasks", routeHandler.GetSubtasks)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusNotFound
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected NotFound error %d, but got %d", want, got)
+		}
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	t.Run("add dependency success", func(t *testing.T) {
+		tasks := generateTasks(2, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		body := fmt.Sprintf(`{"blocker_id": %q}`, tasks[1].Id)
+		request, _ := http.NewRequest("POST", fmt.Sprintf("/tasks/%s/dependencies", tasks[0].Id), strings.NewReader(body))
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.POST("/tasks/:id/dependencies", routeHandler.AddDependency)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusCreated
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("add dependency failed, expected status code %d but got %d", want, got)
+		}
+		if blockers, _ := repo.Blockers("", tasks[0].Id); len(blockers) != 1 || blockers[0].Id != tasks[1].Id {
+			t.Errorf("expected blocker %s, but got %v", tasks[1].Id, blockers)
+		}
+	})
+	t.Run("add dependency without blocker fail", func(t *testing.T) {
+		tasks := generateTasks(1, t)
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("POST", fmt.Sprintf("/tasks/%s/dependencies", tasks[0].Id), strings.NewReader(`{}`))
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.POST("/tasks/:id/dependencies", routeHandler.AddDependency)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusBadRequest
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected BadRequest error %d, but got %d", want, got)
+		}
+	})
+}
+
+func TestRecurringTasks(t *testing.T) {
+	t.Run("series delete trashes the open occurrences", func(t *testing.T) {
+		tasks := generateTasks(3, t)
+		recurrence, dueAt := "FREQ=DAILY", time.Now().Add(time.Hour)
+		for i := range 2 {
+			tasks[i].Recurrence, tasks[i].SeriesId, tasks[i].DueAt = &recurrence, &tasks[0].Id, &dueAt
+		}
+		repo := &MockRepository{
+			tasks: tasks,
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("DELETE", fmt.Sprintf("/tasks/%s?scope=series", tasks[1].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.DELETE("/tasks/:id", routeHandler.DeleteTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		if got := response.Result().StatusCode; got != http.StatusOK {
+			t.Errorf("expected status %d, but got %d", http.StatusOK, got)
+		}
+		for i, task := range repo.tasks {
+			if deleted := task.DeletedAt != nil; deleted != (i < 2) {
+				t.Errorf("expected only the occurrences to be deleted, but task %d has deleted_at %v", i, task.DeletedAt)
+			}
+		}
+	})
+	t.Run("unknown scope fail", func(t *testing.T) {
+		repo := &MockRepository{
+			tasks: generateTasks(1, t),
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		body := strings.NewReader(`{"title": "Weekly sync"}`)
+		request, _ := http.NewRequest("PATCH", fmt.Sprintf("/tasks/%s?scope=all", repo.tasks[0].Id), body)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.PATCH("/tasks/:id", routeHandler.UpdateTask)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusBadRequest
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected BadRequest error %d, but got %d", want, got)
+		}
+	})
+}
+
+func TestTaskHistory(t *testing.T) {
+	t.Run("history of a task success", func(t *testing.T) {
+		repo := &MockRepository{
+			tasks: generateTasks(1, t),
+		}
+		repo.events = []entities.Event{
+			{Id: 1, TaskId: repo.tasks[0].Id, OwnerId: repo.tasks[0].OwnerId, ActorId: repo.tasks[0].OwnerId, Action: entities.ActionCreate, CreatedAt: time.Now()},
+		}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", fmt.Sprintf("/tasks/%s/history", repo.tasks[0].Id), nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.GET("/tasks/:id/history", routeHandler.GetTaskHistory)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		var got struct {
+			Events []entities.Event `json:"events"`
+		}
+		err := json.NewDecoder(response.Body).Decode(&got)
+		if err != nil {
+			log.Fatal("JSON decoding failed")
+		}
+		if len(got.Events) != 1 || got.Events[0].Action != entities.ActionCreate {
+			t.Errorf("expected the create event, but got %+v", got.Events)
+		}
+	})
+	t.Run("audit log with a malformed time fail", func(t *testing.T) {
+		repo := &MockRepository{}
+		serviceHandler, _ := services.NewTaskService(repo)
+		routeHandler := GetRouteHandler(serviceHandler, &auth.MockAuthenticator{})
+		request, _ := http.NewRequest("GET", "/audit?from=yesterday", nil)
+		response := httptest.NewRecorder()
+		ctx, engine := getTestContext(t, response, request)
+		engine.Use(middlewares.HttpErrorResponse())
+		engine.GET("/audit", routeHandler.GetAuditLog)
+
+		engine.ServeHTTP(response, ctx.Request)
+
+		want := http.StatusBadRequest
+		if got := response.Result().StatusCode; got != want {
+			t.Errorf("expected BadRequest error %d, but got %d", want, got)
 		}
 	})
 }
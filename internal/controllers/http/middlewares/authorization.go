@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"github.com/Arup3201/gotasks/internal/auth"
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize lets a request through when the token of the user grants role,
+// it has to run after Authenticate.
+func Authorize(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, role) {
+			c.Error(httperrors.ForbiddenError())
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasRole reports whether the authenticated user has role, admins have
+// every role.
+func HasRole(c *gin.Context, role string) bool {
+	claims := auth.Claims{Roles: c.GetStringSlice(ROLES)}
+	return claims.HasRole(role)
+}
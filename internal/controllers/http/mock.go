@@ -2,20 +2,28 @@ package httpController
 
 import (
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	entities "github.com/Arup3201/gotasks/internal/entities/task"
 	serverErrors "github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/storages"
 )
 
 type MockRepository struct {
-	tasks []entities.Task
+	tasks        []entities.Task
+	dependencies []entities.Dependency
+	events       []entities.Event
+	webhooks     []entities.Webhook
+	deliveries   []entities.Delivery
 }
 
-func (tr *MockRepository) Get(taskId string) (*entities.Task, error) {
+func (tr *MockRepository) Get(ownerId, taskId string) (*entities.Task, error) {
 	for _, task := range tr.tasks {
-		if task.Id == taskId {
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
 			return &task, nil
 		}
 	}
@@ -23,22 +31,34 @@ func (tr *MockRepository) Get(taskId string) (*entities.Task, error) {
 	return nil, serverErrors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 }
 
-func (tr *MockRepository) Insert(id string, title, description string) (*entities.Task, error) {
+func (tr *MockRepository) Insert(ownerId, id string, title, description string, details entities.Details) (*entities.Task, error) {
+	details = details.WithDefaults()
 	task := entities.Task{
 		Id:          id,
+		OwnerId:     ownerId,
 		Title:       title,
 		Description: description,
-		IsCompleted: false,
+		Tags:        []string{},
+		Priority:    details.Priority,
+		DueAt:       details.DueAt,
+		ParentId:    details.ParentId,
+		Recurrence:  details.Recurrence,
+		SeriesId:    details.SeriesId,
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	task.SetStatus(details.Status)
 	tr.tasks = append(tr.tasks, task)
 	return &task, nil
 }
 
-func (tr *MockRepository) Update(taskId string, data map[string]any) (*entities.Task, error) {
+func (tr *MockRepository) Update(ownerId, taskId string, version *int, data map[string]any) (*entities.Task, error) {
 	for i, task := range tr.tasks {
-		if task.Id == taskId {
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
+			if version != nil && task.Version != *version {
+				return nil, serverErrors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d", taskId, task.Version))
+			}
 			t := reflect.ValueOf(&task).Elem()
 			for f, v := range data {
 				field := t.FieldByName(f)
@@ -47,8 +67,12 @@ func (tr *MockRepository) Update(taskId string, data map[string]any) (*entities.
 					field.SetString(value.String())
 				} else if field.Kind() == reflect.Bool {
 					field.SetBool(value.Bool())
+				} else if field.Kind() == reflect.Pointer {
+					field.Set(value)
 				}
 			}
+			task.SetStatus(task.Status)
+			task.Version++
 			task.UpdatedAt = time.Now()
 			tr.tasks[i] = task
 			return &task, nil
@@ -58,10 +82,16 @@ func (tr *MockRepository) Update(taskId string, data map[string]any) (*entities.
 	return nil, serverErrors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 }
 
-func (tr *MockRepository) Delete(taskId string) (*string, error) {
+func (tr *MockRepository) Delete(ownerId, taskId string, version *int) (*string, error) {
 	for i, task := range tr.tasks {
-		if task.Id == taskId {
-			tr.tasks = append(tr.tasks[:i], tr.tasks[i+1:]...)
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
+			if version != nil && task.Version != *version {
+				return nil, serverErrors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d", taskId, task.Version))
+			}
+			deletedAt := time.Now()
+			task.DeletedAt = &deletedAt
+			task.Version++
+			tr.tasks[i] = task
 			return &task.Id, nil
 		}
 	}
@@ -69,8 +99,291 @@ func (tr *MockRepository) Delete(taskId string) (*string, error) {
 	return nil, serverErrors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 }
 
-func (tr *MockRepository) List() ([]entities.Task, error) {
-	return tr.tasks, nil
+func (tr *MockRepository) Restore(ownerId, taskId string) (*entities.Task, error) {
+	for i, task := range tr.tasks {
+		if task.Id == taskId && task.OwnerId == ownerId && task.IsDeleted() {
+			task.DeletedAt = nil
+			task.Version++
+			task.UpdatedAt = time.Now()
+			tr.tasks[i] = task
+			return &task, nil
+		}
+	}
+
+	return nil, serverErrors.NotFoundError(fmt.Sprintf("Task with ID %s not found in trash", taskId))
+}
+
+func (tr *MockRepository) Purge(deletedBefore time.Time) (int64, error) {
+	tasks := []entities.Task{}
+	for _, task := range tr.tasks {
+		if !task.IsDeleted() || !task.DeletedAt.Before(deletedBefore) {
+			tasks = append(tasks, task)
+		}
+	}
+	purged := int64(len(tr.tasks) - len(tasks))
+	tr.tasks = tasks
+	return purged, nil
+}
+
+func (tr *MockRepository) List(ownerId string, options entities.ListOptions) ([]entities.Task, error) {
+	tasks := []entities.Task{}
+	for _, task := range tr.tasks {
+		if task.OwnerId != ownerId || task.IsDeleted() != options.Deleted {
+			continue
+		}
+		if options.IsCompleted != nil && task.IsCompleted != *options.IsCompleted {
+			continue
+		}
+		if options.ParentId != nil && (task.ParentId == nil || *task.ParentId != *options.ParentId) {
+			continue
+		}
+		if options.SeriesId != nil && (task.SeriesId == nil || *task.SeriesId != *options.SeriesId) {
+			continue
+		}
+		if len(options.Tags) > 0 && !hasTags(task.Tags, options.Tags, options.AllTags) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if options.Limit > 0 && len(tasks) > options.Limit {
+		tasks = tasks[:options.Limit]
+	}
+	return tasks, nil
+}
+
+func (tr *MockRepository) Search(ownerId string, options entities.SearchOptions) ([]entities.SearchResult, error) {
+	results := []entities.SearchResult{}
+	for _, task := range tr.tasks {
+		if task.OwnerId != ownerId || task.IsDeleted() {
+			continue
+		}
+		text := strings.ToLower(task.Title + " " + task.Description)
+		matched := true
+		for _, term := range options.Terms {
+			if !strings.Contains(text, strings.Join(term.Words, " ")) {
+				matched = false
+			}
+		}
+		if matched {
+			results = append(results, entities.SearchResult{Task: task})
+		}
+	}
+	if options.Offset >= len(results) {
+		return []entities.SearchResult{}, nil
+	}
+	results = results[options.Offset:]
+	if options.Limit > 0 && len(results) > options.Limit {
+		results = results[:options.Limit]
+	}
+	return results, nil
+}
+
+func (tr *MockRepository) Subtree(ownerId, taskId string) ([]entities.Task, error) {
+	root, err := tr.Get(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []entities.Task{*root}
+	for i := 0; i < len(tasks); i++ {
+		for _, task := range tr.tasks {
+			if task.OwnerId == ownerId && !task.IsDeleted() && task.ParentId != nil && *task.ParentId == tasks[i].Id {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (tr *MockRepository) SetTags(ownerId, taskId string, version *int, tags []string) (*entities.Task, error) {
+	for i, task := range tr.tasks {
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
+			if version != nil && task.Version != *version {
+				return nil, serverErrors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d", taskId, task.Version))
+			}
+			task.Tags = slices.Sorted(slices.Values(tags))
+			task.Version++
+			task.UpdatedAt = time.Now()
+			tr.tasks[i] = task
+			return &task, nil
+		}
+	}
+
+	return nil, serverErrors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+}
+
+func (tr *MockRepository) ListTags(ownerId string) ([]entities.TagCount, error) {
+	counts := map[string]int{}
+	for _, task := range tr.tasks {
+		if task.OwnerId != ownerId || task.IsDeleted() {
+			continue
+		}
+		for _, name := range task.Tags {
+			counts[name]++
+		}
+	}
+	tags := []entities.TagCount{}
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		tags = append(tags, entities.TagCount{Name: name, Count: counts[name]})
+	}
+	return tags, nil
+}
+
+func hasTags(tags, wanted []string, all bool) bool {
+	for _, tag := range wanted {
+		if slices.Contains(tags, tag) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+func (tr *MockRepository) AddDependency(ownerId, taskId, blockerId string) error {
+	dependency := entities.Dependency{TaskId: taskId, BlockerId: blockerId}
+	if !slices.Contains(tr.dependencies, dependency) {
+		tr.dependencies = append(tr.dependencies, dependency)
+	}
+	return nil
+}
+
+func (tr *MockRepository) RemoveDependency(ownerId, taskId, blockerId string) error {
+	i := slices.Index(tr.dependencies, entities.Dependency{TaskId: taskId, BlockerId: blockerId})
+	if i < 0 {
+		return serverErrors.NotFoundError(fmt.Sprintf("Task with ID %s is not blocked by %s", taskId, blockerId))
+	}
+	tr.dependencies = slices.Delete(tr.dependencies, i, i+1)
+	return nil
+}
+
+func (tr *MockRepository) ListDependencies(ownerId string) ([]entities.Dependency, error) {
+	dependencies := []entities.Dependency{}
+	for _, dependency := range tr.dependencies {
+		_, taskErr := tr.Get(ownerId, dependency.TaskId)
+		_, blockerErr := tr.Get(ownerId, dependency.BlockerId)
+		if taskErr == nil && blockerErr == nil {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies, nil
+}
+
+func (tr *MockRepository) Blockers(ownerId, taskId string) ([]entities.Task, error) {
+	tasks := []entities.Task{}
+	for _, dependency := range tr.dependencies {
+		if task, err := tr.Get(ownerId, dependency.BlockerId); err == nil && dependency.TaskId == taskId {
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks, nil
+}
+
+func (tr *MockRepository) Blocking(ownerId, taskId string) ([]entities.Task, error) {
+	tasks := []entities.Task{}
+	for _, dependency := range tr.dependencies {
+		if task, err := tr.Get(ownerId, dependency.TaskId); err == nil && dependency.BlockerId == taskId {
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks, nil
+}
+
+func (tr *MockRepository) AddEvent(event entities.Event) error {
+	event.Id = int64(len(tr.events) + 1)
+	tr.events = append(tr.events, event)
+	return nil
+}
+
+func (tr *MockRepository) ListEvents(options entities.EventOptions) ([]entities.Event, error) {
+	events := []entities.Event{}
+	for _, event := range tr.events {
+		if options.Matches(event) && (options.Limit == 0 || len(events) < options.Limit) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (tr *MockRepository) AddWebhook(webhook entities.Webhook) error {
+	tr.webhooks = append(tr.webhooks, webhook)
+	return nil
+}
+
+func (tr *MockRepository) ListWebhooks(ownerId string) ([]entities.Webhook, error) {
+	webhooks := []entities.Webhook{}
+	for _, webhook := range tr.webhooks {
+		if webhook.OwnerId == ownerId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (tr *MockRepository) GetWebhook(ownerId, webhookId string) (*entities.Webhook, error) {
+	for _, webhook := range tr.webhooks {
+		if webhook.Id == webhookId && webhook.OwnerId == ownerId {
+			return &webhook, nil
+		}
+	}
+	return nil, serverErrors.NotFoundError(fmt.Sprintf("Webhook with ID %s not found", webhookId))
+}
+
+func (tr *MockRepository) DeleteWebhook(ownerId, webhookId string) error {
+	if _, err := tr.GetWebhook(ownerId, webhookId); err != nil {
+		return err
+	}
+	tr.webhooks = slices.DeleteFunc(tr.webhooks, func(webhook entities.Webhook) bool { return webhook.Id == webhookId })
+	tr.deliveries = slices.DeleteFunc(tr.deliveries, func(delivery entities.Delivery) bool { return delivery.WebhookId == webhookId })
+	return nil
+}
+
+func (tr *MockRepository) AddDelivery(delivery entities.Delivery) error {
+	delivery.Id = 1
+	if len(tr.deliveries) > 0 {
+		delivery.Id = tr.deliveries[len(tr.deliveries)-1].Id + 1
+	}
+	tr.deliveries = append(tr.deliveries, delivery)
+	return nil
+}
+
+func (tr *MockRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]entities.Delivery, error) {
+	deliveries := []entities.Delivery{}
+	for i, delivery := range tr.deliveries {
+		if delivery.Status == entities.DeliveryPending && !delivery.NextAttemptAt.After(now) && len(deliveries) < limit {
+			tr.deliveries[i].NextAttemptAt = now.Add(lease)
+			deliveries = append(deliveries, tr.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func (tr *MockRepository) UpdateDelivery(delivery entities.Delivery) error {
+	for i := range tr.deliveries {
+		if tr.deliveries[i].Id == delivery.Id {
+			tr.deliveries[i] = delivery
+		}
+	}
+	return nil
+}
+
+func (tr *MockRepository) ListDeliveries(ownerId, webhookId string, options entities.DeliveryOptions) ([]entities.Delivery, error) {
+	deliveries := []entities.Delivery{}
+	for _, delivery := range slices.Backward(tr.deliveries) {
+		if delivery.OwnerId == ownerId && delivery.WebhookId == webhookId && options.Matches(delivery) && (options.Limit == 0 || len(deliveries) < options.Limit) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// InTransaction puts the tasks back when fn fails.
+func (tr *MockRepository) InTransaction(fn func(repo storages.TaskRepository) error) error {
+	tasks, dependencies, events := slices.Clone(tr.tasks), slices.Clone(tr.dependencies), slices.Clone(tr.events)
+	webhooks, deliveries := slices.Clone(tr.webhooks), slices.Clone(tr.deliveries)
+	if err := fn(tr); err != nil {
+		tr.tasks, tr.dependencies, tr.events = tasks, dependencies, events
+		tr.webhooks, tr.deliveries = webhooks, deliveries
+		return err
+	}
+	return nil
 }
 
 func (tr *MockRepository) Close() error {
@@ -1,11 +1,13 @@
 package httpController
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/Arup3201/gotasks/internal/auth"
+	graphqlController "github.com/Arup3201/gotasks/internal/controllers/graphql"
 	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
-	"github.com/Arup3201/gotasks/internal/services/domain/task"
-	"github.com/Arup3201/gotasks/internal/storages"
+	"github.com/Arup3201/gotasks/internal/services"
 	"github.com/Arup3201/gotasks/internal/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -13,24 +15,29 @@ import (
 type HttpServer struct {
 	engine       *gin.Engine
 	routeHandler *routeHandler
+	server       *http.Server
 }
 
 var Server = &HttpServer{}
 
-func InitServer(storage storages.TaskRepository) error {
+// InitServer serves the operations of the service handler, shared with the
+// gRPC API so that both see the same task changes.
+func InitServer(serviceHandler services.ServiceHandler, authenticator auth.Authenticator) error {
 	engine := gin.New()
 	engine.Use(gin.Logger())
 	engine.Use(gin.Recovery())
 	engine.Use(middlewares.HttpErrorResponse())
-	engine.Use(middlewares.Authenticate([]string{"/tasks", "/search"}))
+	engine.Use(middlewares.Authenticate(authenticator, []string{"/tasks", "/tags", "/search", "/me", "/audit", "/ws", "/webhooks", "/graphql"}))
 
-	serviceHandler, err := task.NewTaskService(storage)
+	Server.engine = engine
+	Server.server = &http.Server{Handler: engine}
+	Server.routeHandler = GetRouteHandler(serviceHandler, authenticator)
+
+	executor, err := graphqlController.NewExecutor(serviceHandler)
 	if err != nil {
 		return err
 	}
-
-	Server.engine = engine
-	Server.routeHandler = GetRouteHandler(serviceHandler)
+	Server.routeHandler.graphql = executor
 
 	Server.AttachRoutes()
 
@@ -38,20 +45,59 @@ func InitServer(storage storages.TaskRepository) error {
 }
 
 func (server *HttpServer) AttachRoutes() {
+	read := middlewares.Authorize(auth.RoleRead)
+	write := middlewares.Authorize(auth.RoleWrite)
+	admin := middlewares.Authorize(auth.RoleAdmin)
+
 	server.engine.POST("/login", server.routeHandler.Login)
-	server.engine.GET("/tasks", server.routeHandler.GetTasks)
-	server.engine.POST("/tasks", server.routeHandler.AddTask)
-	server.engine.GET("/tasks/:id", server.routeHandler.GetTask)
-	server.engine.PATCH("/tasks/:id", server.routeHandler.UpdateTask)
-	server.engine.DELETE("/tasks/:id", server.routeHandler.DeleteTask)
-	server.engine.GET("/search/tasks", server.routeHandler.SearchTasks)
+	server.engine.POST("/token/refresh", server.routeHandler.RefreshToken)
+	server.engine.POST("/logout", server.routeHandler.Logout)
+	server.engine.GET("/me", server.routeHandler.GetMe)
+	server.engine.GET("/tasks", read, server.routeHandler.GetTasks)
+	server.engine.POST("/tasks", write, server.routeHandler.AddTask)
+	server.engine.POST("/tasks:method", write, server.routeHandler.TaskMethod)
+	server.engine.GET("/tasks/trash", read, server.routeHandler.GetTrash)
+	server.engine.GET("/tasks/plan", read, server.routeHandler.GetPlan)
+	server.engine.GET("/tasks/events", read, server.routeHandler.StreamTaskChanges)
+	server.engine.GET("/tasks/:id", read, server.routeHandler.GetTask)
+	server.engine.PATCH("/tasks/:id", write, server.routeHandler.UpdateTask)
+	server.engine.DELETE("/tasks/:id", write, server.routeHandler.DeleteTask)
+	server.engine.POST("/tasks/:id/restore", write, server.routeHandler.RestoreTask)
+	server.engine.PUT("/tasks/:id/tags", write, server.routeHandler.SetTaskTags)
+	server.engine.GET("/tasks/:id/subtasks", read, server.routeHandler.GetSubtasks)
+	server.engine.GET("/tasks/:id/tree", read, server.routeHandler.GetTaskTree)
+	server.engine.GET("/tasks/:id/dependencies", read, server.routeHandler.GetDependencies)
+	server.engine.POST("/tasks/:id/dependencies", write, server.routeHandler.AddDependency)
+	server.engine.DELETE("/tasks/:id/dependencies/:blocker_id", write, server.routeHandler.RemoveDependency)
+	server.engine.GET("/tasks/:id/blocking", read, server.routeHandler.GetBlocking)
+	server.engine.GET("/tasks/:id/history", read, server.routeHandler.GetTaskHistory)
+	server.engine.GET("/tags", read, server.routeHandler.GetTags)
+	server.engine.GET("/search/tasks", read, server.routeHandler.SearchTasks)
+	server.engine.GET("/audit", admin, server.routeHandler.GetAuditLog)
+	server.engine.GET("/ws", read, server.routeHandler.SyncTasks)
+	server.engine.GET("/webhooks", read, server.routeHandler.GetWebhooks)
+	server.engine.POST("/webhooks", write, server.routeHandler.CreateWebhook)
+	server.engine.DELETE("/webhooks/:id", write, server.routeHandler.DeleteWebhook)
+	server.engine.GET("/webhooks/:id/deliveries", read, server.routeHandler.GetWebhookDeliveries)
+	// the role of a GraphQL request depends on its operation, the executor
+	// checks it
+	server.engine.GET("/graphql", server.routeHandler.GraphQL)
+	server.engine.POST("/graphql", server.routeHandler.GraphQL)
 }
 
 func (server *HttpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	server.engine.ServeHTTP(w, r)
 }
 
+// Run serves the requests until Shutdown, it returns http.ErrServerClosed
+// then.
 func (server *HttpServer) Run(host string) error {
-	err := server.engine.Run(host + ":" + utils.Config.Port)
-	return err
+	server.server.Addr = host + ":" + utils.Config.Port
+	return server.server.ListenAndServe()
+}
+
+// Shutdown stops taking requests and waits for the ones in flight to be
+// answered, until ctx is done.
+func (server *HttpServer) Shutdown(ctx context.Context) error {
+	return server.server.Shutdown(ctx)
 }
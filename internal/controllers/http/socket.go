@@ -0,0 +1,87 @@
+package httpController
+
+import (
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
+	"github.com/Arup3201/gotasks/internal/events"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// SyncTasks sends the changes of the tasks of the caller over a WebSocket as
+// JSON messages, until either side closes it. A client that reconnects with
+// the 'last_event_id' query param gets the changes it missed first, or a
+// 'reset' message when they are not kept anymore.
+func (handler *routeHandler) SyncTasks(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	lastId, ok := lastEventId(c, c.Query("last_event_id"), "last_event_id")
+	if !ok {
+		return
+	}
+
+	// the token comes in a header and never in a cookie, so there is no
+	// origin to check
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			handler.syncTasks(conn, ownerId, lastId)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (handler *routeHandler) syncTasks(conn *websocket.Conn, ownerId string, lastId int64) {
+	defer conn.Close()
+
+	subscription := handler.serviceHandler.SubscribeTaskChanges(ownerId, lastId)
+	defer subscription.Close()
+
+	// the client sends nothing but control frames, reading handles them and
+	// tells when the client is gone
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var message []byte
+		for websocket.Message.Receive(conn, &message) == nil {
+		}
+	}()
+
+	if subscription.Missed {
+		if websocket.JSON.Send(conn, events.Change{Type: events.Reset}) != nil {
+			return
+		}
+	}
+	for _, change := range subscription.Replay {
+		if websocket.JSON.Send(conn, change) != nil {
+			return
+		}
+	}
+
+	var heartbeats <-chan time.Time
+	if handler.heartbeat > 0 {
+		ticker := time.NewTicker(handler.heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				// the socket fell behind, the client resumes from its last change
+				return
+			}
+			err = websocket.JSON.Send(conn, change)
+		case <-heartbeats:
+			conn.PayloadType = websocket.PingFrame
+			_, err = conn.Write(nil)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
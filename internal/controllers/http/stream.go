@@ -0,0 +1,92 @@
+package httpController
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
+	"github.com/Arup3201/gotasks/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+// lastEventId parses the ID of the last change a client got from the value
+// of the param, 0 when it is empty. It reports false after recording the
+// error when the value is malformed.
+func lastEventId(c *gin.Context, value, param string) (int64, bool) {
+	if value == "" {
+		return 0, true
+	}
+	lastId, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+			Field:  param,
+			Reason: "'" + param + "' must be the ID of a change of the stream",
+		}))
+		return 0, false
+	}
+	return lastId, true
+}
+
+// StreamTaskChanges sends the changes of the tasks of the caller as
+// Server-Sent Events until the client goes away. A client that reconnects
+// with the 'Last-Event-ID' header gets the changes it missed first, or a
+// 'reset' event when they are not kept anymore.
+func (handler *routeHandler) StreamTaskChanges(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	lastId, ok := lastEventId(c, c.GetHeader("Last-Event-ID"), "Last-Event-ID")
+	if !ok {
+		return
+	}
+
+	subscription := handler.serviceHandler.SubscribeTaskChanges(ownerId, lastId)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// proxies must not hold the events back
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if subscription.Missed {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", events.Reset)
+	}
+	for _, change := range subscription.Replay {
+		writeChange(c.Writer, change)
+	}
+	c.Writer.Flush()
+
+	var heartbeats <-chan time.Time
+	if handler.heartbeat > 0 {
+		ticker := time.NewTicker(handler.heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case change, ok := <-subscription.Changes():
+			if !ok {
+				// the stream fell behind, the client resumes from its last event
+				return
+			}
+			writeChange(c.Writer, change)
+		case <-heartbeats:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeChange(w io.Writer, change events.Change) {
+	data, _ := json.Marshal(change)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.Id, change.Type, data)
+}
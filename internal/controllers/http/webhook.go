@@ -0,0 +1,131 @@
+package httpController
+
+import (
+	"net/http"
+	"strconv"
+
+	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
+	"github.com/Arup3201/gotasks/internal/controllers/http/middlewares"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CreateWebhook struct {
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"`
+	Secret *string   `json:"secret"`
+}
+
+// CreateWebhook subscribes a URL to the changes of the tasks of the caller,
+// the secret signs every delivery and is never returned.
+func (handler *routeHandler) CreateWebhook(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	var payload CreateWebhook
+
+	if err := c.BindJSON(&payload); err != nil {
+		c.Error(bindError(err))
+		return
+	}
+
+	if payload.URL == nil {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "url",
+			Reason: "Webhook 'url' is required",
+		}))
+		return
+	}
+	if payload.Events == nil {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "events",
+			Reason: "Webhook 'events' is required",
+		}))
+		return
+	}
+	if payload.Secret == nil {
+		c.Error(httperrors.MissingBodyError(httperrors.ErrorField{
+			Field:  "secret",
+			Reason: "Webhook 'secret' is required",
+		}))
+		return
+	}
+
+	webhook, err := handler.serviceHandler.CreateWebhook(ownerId, services.CreateWebhookData{
+		URL:    payload.URL,
+		Events: *payload.Events,
+		Secret: payload.Secret,
+	})
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+
+	c.IndentedJSON(http.StatusCreated, webhook)
+}
+
+func (handler *routeHandler) GetWebhooks(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	webhooks, err := handler.serviceHandler.GetWebhooks(ownerId)
+	if err != nil {
+		c.Error(httperrors.InternalServerError(err))
+		return
+	}
+	c.IndentedJSON(http.StatusOK, webhooks)
+}
+
+func (handler *routeHandler) DeleteWebhook(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	webhookId, err := handler.serviceHandler.DeleteWebhook(ownerId, c.Param("id"))
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, webhookId)
+}
+
+// GetWebhookDeliveries lists the deliveries of a webhook, the latest first,
+// filtered by 'status'.
+func (handler *routeHandler) GetWebhookDeliveries(c *gin.Context) {
+	ownerId := c.GetString(middlewares.USER_ID)
+
+	query := services.DeliveriesQuery{
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			c.Error(httperrors.InvalidRequestParamError(httperrors.ErrorField{
+				Field:  "limit",
+				Reason: "query param 'limit' must be an integer",
+			}))
+			return
+		}
+		query.Limit = parsed
+	}
+
+	page, err := handler.serviceHandler.GetDeliveries(ownerId, c.Param("id"), query)
+	if err != nil {
+		appError, ok := err.(*errors.AppError)
+		if ok {
+			c.Error(httperrors.FromAppParamError(appError))
+		} else {
+			c.Error(httperrors.InternalServerError(err))
+		}
+		return
+	}
+	c.IndentedJSON(http.StatusOK, page)
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -12,17 +13,39 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Arup3201/gotasks/internal/auth"
 	controllers "github.com/Arup3201/gotasks/internal/controllers/http"
 	entities "github.com/Arup3201/gotasks/internal/entities/task"
+	taskService "github.com/Arup3201/gotasks/internal/services/domain/task"
 	"github.com/Arup3201/gotasks/internal/storages"
 	"github.com/Arup3201/gotasks/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+var storage storages.TaskRepository
+
+// requests carry the token of the test user unless they set their own
+// Authorization header, so every task belongs to the test user
+const (
+	ownerId   = "test-user"
+	testToken = "test-token"
+	// tokens of users with other roles than the test user
+	readerToken = "reader-token"
+	noRoleToken = "no-role-token"
+	adminToken  = "admin-token"
+)
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
+	// without explicit configuration the suite runs against the in-memory storage
+	if _, ok := os.LookupEnv(utils.STORAGE); !ok {
+		os.Setenv(utils.STORAGE, storages.InMemory)
+	}
+	// the webhook receivers of the suite listen on loopback
+	os.Setenv(utils.WEBHOOK_ALLOW_PRIVATE, "true")
+
 	tearDown := setUp()
 
 	exitCode := m.Run()
@@ -34,12 +57,27 @@ func TestMain(m *testing.M) {
 func setUp() func() {
 	utils.Config.Configure()
 
-	storage, err := storages.New(storages.Postgres)
+	var err error
+	storage, err = storages.New(utils.Config.Storage)
 	if err != nil {
 		log.Fatalf("storage create error: %v", err)
 	}
 
-	controllers.InitServer(storage)
+	serviceHandler, err := taskService.NewTaskService(storage)
+	if err != nil {
+		log.Fatalf("service create error: %v", err)
+	}
+	if err := serviceHandler.FollowChanges(context.Background()); err != nil {
+		log.Fatalf("task changes listen error: %v", err)
+	}
+	controllers.InitServer(serviceHandler, &auth.MockAuthenticator{
+		Tokens: map[string]auth.Claims{
+			testToken:   {UserId: ownerId, Username: "tester", Roles: []string{auth.RoleRead, auth.RoleWrite}},
+			readerToken: {UserId: "reader", Username: "reader", Roles: []string{auth.RoleRead}},
+			noRoleToken: {UserId: "nobody", Username: "nobody", Roles: []string{}},
+			adminToken:  {UserId: "admin", Username: "admin", Roles: []string{auth.RoleAdmin}},
+		},
+	})
 
 	return func() {
 		cleanDB()
@@ -48,36 +86,31 @@ func setUp() func() {
 }
 
 func prepareDBTasks(n int) []entities.Task {
-	storage, err := storages.New(storages.Postgres)
-	if err != nil {
-		log.Fatalf("storage create error: %v", err)
-	}
-
 	tasks := generateTasks(n)
 	for _, task := range tasks {
-		storage.Insert(task.Id, task.Title, task.Description)
+		storage.Insert(ownerId, task.Id, task.Title, task.Description, entities.Details{})
 	}
 
 	return tasks
 }
 
 func cleanDB() {
-	storage, err := storages.New(storages.Postgres)
-	if err != nil {
-		log.Fatalf("storage create error: %v", err)
-	}
-
-	tasks, err := storage.List()
+	tasks, err := storage.List(ownerId, entities.ListOptions{})
 	if err != nil {
 		log.Fatalf("tearDown() failed: %v", err)
 	}
 
 	for _, task := range tasks {
-		_, err = storage.Delete(task.Id)
+		_, err = storage.Delete(ownerId, task.Id, nil)
 		if err != nil {
 			log.Fatalf("tearDown() failed: %v", err)
 		}
 	}
+
+	// empty the trash as well, deleted tasks of earlier tests can't leak
+	if _, err := storage.Purge(time.Now().Add(time.Minute)); err != nil {
+		log.Fatalf("tearDown() failed: %v", err)
+	}
 }
 
 func generateTasks(n int) []entities.Task {
@@ -89,6 +122,8 @@ func generateTasks(n int) []entities.Task {
 			Id:          id,
 			Title:       fmt.Sprintf("title - %d", rand.Intn(9999)),
 			Description: fmt.Sprintf("description - %d", rand.Intn(9999)),
+			Status:      entities.StatusTodo,
+			Priority:    entities.PriorityMedium,
 			IsCompleted: false,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
@@ -100,8 +135,16 @@ func generateTasks(n int) []entities.Task {
 }
 
 func makeRequest(method, url string, body interface{}) *httptest.ResponseRecorder {
+	return makeRequestWithHeaders(method, url, body, nil)
+}
+
+func makeRequestWithHeaders(method, url string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
 	requestBody, _ := json.Marshal(body)
 	request, _ := http.NewRequest(method, url, bytes.NewBuffer(requestBody))
+	request.Header.Set("Authorization", "Bearer "+testToken)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
 	writer := httptest.NewRecorder()
 	controllers.Server.ServeHTTP(writer, request)
 	return writer
@@ -1,18 +1,30 @@
 package controller
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	httpController "github.com/Arup3201/gotasks/internal/controllers/http"
 	httperrors "github.com/Arup3201/gotasks/internal/controllers/http/errors"
 	entities "github.com/Arup3201/gotasks/internal/entities/task"
 	"github.com/Arup3201/gotasks/internal/services"
+	taskService "github.com/Arup3201/gotasks/internal/services/domain/task"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
 )
 
 func assertTask(t testing.TB, want, got entities.Task) {
@@ -37,12 +49,12 @@ func TestViewAllTasksSuccess(t *testing.T) {
 	// assert
 	assert.Equal(t, http.StatusOK, response.Code)
 
-	tasks := []entities.Task{}
-	if err := json.NewDecoder(response.Body).Decode(&tasks); err != nil {
+	var page services.TaskPage
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
 		t.Fail()
 		t.Logf("JSON decoder error: %v", err)
 	}
-	assert.Equal(t, expectedTasksNum, len(tasks))
+	assert.Equal(t, expectedTasksNum, len(page.Tasks))
 	cleanDB()
 }
 
@@ -74,12 +86,12 @@ func TestAddTaskCheckSuccess(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, response2.Code)
 
-	var responseTasks []entities.Task
-	if err := json.NewDecoder(response2.Body).Decode(&responseTasks); err != nil {
+	var responsePage services.TaskPage
+	if err := json.NewDecoder(response2.Body).Decode(&responsePage); err != nil {
 		t.Fail()
 		t.Logf("JSON decoder error: %v", err)
 	}
-	assert.Equal(t, expectedTasksNum, len(responseTasks))
+	assert.Equal(t, expectedTasksNum, len(responsePage.Tasks))
 
 	cleanDB()
 }
@@ -109,13 +121,13 @@ func TestAddAndViewTaskSuccess(t *testing.T) {
 	assert.Equal(t, task.Description, responseTask.Description)
 
 	assert.Equal(t, http.StatusOK, response2.Code)
-	var allTasks []entities.Task
+	var allTasks services.TaskPage
 	if err := json.NewDecoder(response2.Body).Decode(&allTasks); err != nil {
 		t.Fail()
 		t.Logf("JSON decoder error: %v", err)
 	}
 
-	assert.Equal(t, 3, len(allTasks))
+	assert.Equal(t, 3, len(allTasks.Tasks))
 	cleanDB()
 }
 
@@ -603,15 +615,15 @@ func TestSearchSingleWord(t *testing.T) {
 	// assert
 	assert.Equal(t, expectedCode, response.Code)
 
-	var responseTasks []entities.Task
-	if err := json.NewDecoder(response.Body).Decode(&responseTasks); err != nil {
+	var responsePage services.SearchPage
+	if err := json.NewDecoder(response.Body).Decode(&responsePage); err != nil {
 		t.Fail()
 		t.Logf("JSON decode error: %v", err)
 	}
-	assert.Equal(t, len(expectedMatches), len(responseTasks))
+	assert.Equal(t, len(expectedMatches), len(responsePage.Tasks))
 
 	var responseTaskTitles []string
-	for _, task := range responseTasks {
+	for _, task := range responsePage.Tasks {
 		responseTaskTitles = append(responseTaskTitles, task.Title)
 	}
 	assert.Equal(t, expectedMatches, responseTaskTitles)
@@ -662,15 +674,15 @@ func TestSearchMultiWord(t *testing.T) {
 	// assert
 	assert.Equal(t, expectedCode, response.Code)
 
-	var responseTasks []entities.Task
-	if err := json.NewDecoder(response.Body).Decode(&responseTasks); err != nil {
+	var responsePage services.SearchPage
+	if err := json.NewDecoder(response.Body).Decode(&responsePage); err != nil {
 		t.Fail()
 		t.Logf("JSON decode error: %v", err)
 	}
-	assert.Equal(t, len(expectedMatches), len(responseTasks))
+	assert.Equal(t, len(expectedMatches), len(responsePage.Tasks))
 
 	var responseTaskTitles []string
-	for _, task := range responseTasks {
+	for _, task := range responsePage.Tasks {
 		responseTaskTitles = append(responseTaskTitles, task.Title)
 	}
 	assert.Equal(t, expectedMatches, responseTaskTitles)
@@ -719,15 +731,15 @@ func TestSearchReturnEmpty(t *testing.T) {
 	// assert
 	assert.Equal(t, expectedCode, response.Code)
 
-	var responseTasks []entities.Task
-	if err := json.NewDecoder(response.Body).Decode(&responseTasks); err != nil {
+	var responsePage services.SearchPage
+	if err := json.NewDecoder(response.Body).Decode(&responsePage); err != nil {
 		t.Fail()
 		t.Logf("JSON decode error: %v", err)
 	}
-	assert.Equal(t, len(expectedMatches), len(responseTasks))
+	assert.Equal(t, len(expectedMatches), len(responsePage.Tasks))
 
 	responseTaskTitles := []string{}
-	for _, task := range responseTasks {
+	for _, task := range responsePage.Tasks {
 		responseTaskTitles = append(responseTaskTitles, task.Title)
 	}
 	assert.Equal(t, expectedMatches, responseTaskTitles)
@@ -779,3 +791,772 @@ func TestDeleteInvalidTaskFail(t *testing.T) {
 	assert.Equal(t, expectedBody["status"], responseError.Status)
 	cleanDB()
 }
+
+// walking the pages with next_cursor returns every task exactly once
+func TestViewTasksPaginationSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(5)
+	expectedIds := map[string]bool{}
+	for _, task := range tasks {
+		expectedIds[task.Id] = true
+	}
+
+	// act
+	gotIds := map[string]bool{}
+	url := "/tasks?limit=2&sort=title&order=desc"
+	pages := 0
+	for {
+		response := makeRequest("GET", url, nil)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		var page services.TaskPage
+		if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+			t.Fatalf("JSON decoder error: %v", err)
+		}
+		for _, task := range page.Tasks {
+			assert.False(t, gotIds[task.Id])
+			gotIds[task.Id] = true
+		}
+		pages++
+
+		if page.NextCursor == nil {
+			break
+		}
+		url = fmt.Sprintf("/tasks?limit=2&sort=title&order=desc&cursor=%s", *page.NextCursor)
+	}
+
+	// assert
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, expectedIds, gotIds)
+	cleanDB()
+}
+
+// filter tasks by completion
+func TestViewTasksFilterSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(3)
+	isCompleted := true
+	updatePayload := services.UpdateTaskData{
+		IsCompleted: &isCompleted,
+	}
+	makeRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), updatePayload)
+
+	// act
+	response := makeRequest("GET", "/tasks?is_completed=true", nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var page services.TaskPage
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		t.Fail()
+		t.Logf("JSON decoder error: %v", err)
+	}
+	assert.Equal(t, 1, len(page.Tasks))
+	assert.Nil(t, page.NextCursor)
+	cleanDB()
+}
+
+// invalid list parameters return bad request
+func TestViewTasksInvalidParamFail(t *testing.T) {
+	// prepare
+	prepareDBTasks(2)
+	urls := []string{
+		"/tasks?limit=abc",
+		"/tasks?limit=0&sort=priority",
+		"/tasks?created_after=yesterday",
+		"/tasks?cursor=abc",
+	}
+
+	for _, url := range urls {
+		// act
+		response := makeRequest("GET", url, nil)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, response.Code, url)
+
+		var responseError httperrors.HttpError
+		if err := json.NewDecoder(response.Body).Decode(&responseError); err != nil {
+			t.Fail()
+			t.Logf("JSON decode error: %v", err)
+		}
+		assert.Equal(t, httperrors.INVALID_PARAM, responseError.Id)
+	}
+	cleanDB()
+}
+
+// search matches the description and highlights the matched words
+func TestSearchDescriptionHighlight(t *testing.T) {
+	// prepare
+	title, description := "Read story book", "Feludar sampta kando"
+	payload := httpController.CreateTask{
+		Title:       &title,
+		Description: &description,
+	}
+	makeRequest("POST", "/tasks", payload)
+	expectedCode := 200
+
+	// act
+	response := makeRequest("GET", "/search/tasks?q=samp*", nil)
+
+	// assert
+	assert.Equal(t, expectedCode, response.Code)
+
+	var responsePage services.SearchPage
+	if err := json.NewDecoder(response.Body).Decode(&responsePage); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	assert.Equal(t, 1, len(responsePage.Tasks))
+	if len(responsePage.Tasks) == 1 {
+		assert.Equal(t, title, responsePage.Tasks[0].Title)
+		assert.Equal(t, "Feludar <b>sampta</b> kando", responsePage.Tasks[0].Snippet)
+	}
+	cleanDB()
+}
+
+// a write with a stale etag is rejected instead of overwriting a newer update
+func TestUpdateStaleETagFail(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(1)
+	prepTask := makeRequest("GET", fmt.Sprintf("/tasks/%s", tasks[0].Id), nil)
+	etag := prepTask.Header().Get("ETag")
+	first := "Task title (first)"
+	second := "Task title (second)"
+	expectedCode := http.StatusPreconditionFailed
+	expectedBody := map[string]any{
+		"id":     "PRECONDITION_FAILED",
+		"title":  "Precondition failed",
+		"status": 412,
+	}
+
+	// act
+	response1 := makeRequestWithHeaders("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), services.UpdateTaskData{
+		Title: &first,
+	}, map[string]string{"If-Match": etag})
+	response2 := makeRequestWithHeaders("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), services.UpdateTaskData{
+		Title: &second,
+	}, map[string]string{"If-Match": etag})
+
+	// assert
+	assert.Equal(t, http.StatusOK, response1.Code)
+	assert.NotEqual(t, etag, response1.Header().Get("ETag"))
+	assert.Equal(t, expectedCode, response2.Code)
+
+	var responseError httperrors.HttpError
+	if err := json.NewDecoder(response2.Body).Decode(&responseError); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, expectedBody["id"], responseError.Id)
+	assert.Equal(t, expectedBody["title"], responseError.Title)
+	assert.Equal(t, expectedBody["status"], responseError.Status)
+
+	response3 := makeRequestWithHeaders("GET", fmt.Sprintf("/tasks/%s", tasks[0].Id), nil, map[string]string{
+		"If-None-Match": response1.Header().Get("ETag"),
+	})
+	assert.Equal(t, http.StatusNotModified, response3.Code)
+	cleanDB()
+}
+
+// deleted task goes to the trash and comes back on restore
+func TestDeleteAndRestoreTaskSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(2)
+	url := fmt.Sprintf("/tasks/%s", tasks[0].Id)
+
+	// act
+	deleteResponse := makeRequest("DELETE", url, nil)
+	getResponse := makeRequest("GET", url, nil)
+	trashResponse := makeRequest("GET", "/tasks/trash", nil)
+	restoreResponse := makeRequest("POST", url+"/restore", nil)
+	restoredResponse := makeRequest("GET", url, nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, deleteResponse.Code)
+	assert.Equal(t, http.StatusNotFound, getResponse.Code)
+	assert.Equal(t, http.StatusOK, trashResponse.Code)
+
+	var trash services.TaskPage
+	if err := json.NewDecoder(trashResponse.Body).Decode(&trash); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Len(t, trash.Tasks, 1)
+	if len(trash.Tasks) == 1 {
+		assert.Equal(t, tasks[0].Id, trash.Tasks[0].Id)
+		assert.NotNil(t, trash.Tasks[0].DeletedAt)
+	}
+	assert.Equal(t, http.StatusOK, restoreResponse.Code)
+	assert.Equal(t, http.StatusOK, restoredResponse.Code)
+	cleanDB()
+}
+
+// status follows the workflow and is_completed follows the status
+func TestUpdateStatusSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(1)
+	url := fmt.Sprintf("/tasks/%s", tasks[0].Id)
+	inProgress := entities.StatusInProgress
+	blocked := entities.StatusBlocked
+	done := entities.StatusDone
+
+	// act
+	response1 := makeRequest("PATCH", url, services.UpdateTaskData{Status: &inProgress})
+	response2 := makeRequest("PATCH", url, services.UpdateTaskData{Status: &blocked})
+	response3 := makeRequest("PATCH", url, services.UpdateTaskData{Status: &done})
+	response4 := makeRequest("PATCH", url, services.UpdateTaskData{Status: &inProgress})
+	response5 := makeRequest("PATCH", url, services.UpdateTaskData{Status: &done})
+
+	// assert
+	assert.Equal(t, http.StatusOK, response1.Code)
+	assert.Equal(t, http.StatusOK, response2.Code)
+	assert.Equal(t, http.StatusBadRequest, response3.Code)
+	assert.Equal(t, http.StatusOK, response4.Code)
+	assert.Equal(t, http.StatusOK, response5.Code)
+
+	var responseBody entities.Task
+	if err := json.NewDecoder(response5.Body).Decode(&responseBody); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, entities.StatusDone, responseBody.Status)
+	assert.True(t, responseBody.IsCompleted)
+	cleanDB()
+}
+
+// tags are normalized and tasks can be listed by any or all of them
+func TestTagAndFilterTasksSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(3)
+
+	// act
+	tagResponse1 := makeRequest("PUT", fmt.Sprintf("/tasks/%s/tags", tasks[0].Id), httpController.SetTags{Tags: &[]string{"Home", "work"}})
+	tagResponse2 := makeRequest("PUT", fmt.Sprintf("/tasks/%s/tags", tasks[1].Id), httpController.SetTags{Tags: &[]string{"WORK"}})
+	anyResponse := makeRequest("GET", "/tasks?tag=home&tag=work", nil)
+	allResponse := makeRequest("GET", "/tasks?tag=home&tag=work&tag_match=all", nil)
+	tagsResponse := makeRequest("GET", "/tags", nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, tagResponse1.Code)
+	assert.Equal(t, http.StatusOK, tagResponse2.Code)
+
+	var anyPage, allPage services.TaskPage
+	if err := json.NewDecoder(anyResponse.Body).Decode(&anyPage); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	if err := json.NewDecoder(allResponse.Body).Decode(&allPage); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Len(t, anyPage.Tasks, 2)
+	assert.Len(t, allPage.Tasks, 1)
+	if len(allPage.Tasks) == 1 {
+		assert.Equal(t, tasks[0].Id, allPage.Tasks[0].Id)
+		assert.Equal(t, []string{"home", "work"}, allPage.Tasks[0].Tags)
+	}
+
+	var tagList services.TagList
+	if err := json.NewDecoder(tagsResponse.Body).Decode(&tagList); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Contains(t, tagList.Tags, entities.TagCount{Name: "work", Count: 2})
+	cleanDB()
+}
+
+// best effort batches apply the valid operations and report the others
+func TestBatchTasksSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(2)
+	batch := map[string]any{
+		"operations": []map[string]any{
+			{"op": "create", "data": map[string]any{"title": "Batch title", "description": "Batch description"}},
+			{"op": "update", "id": tasks[0].Id, "data": map[string]any{"status": "done"}},
+			{"op": "update", "id": tasks[1].Id, "data": map[string]any{"title": ""}},
+			{"op": "delete", "id": tasks[1].Id},
+		},
+	}
+
+	// act
+	response := makeRequest("POST", "/tasks:batch", batch)
+	listResponse := makeRequest("GET", "/tasks", nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var result httpController.BatchResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var page services.TaskPage
+	if err := json.NewDecoder(listResponse.Body).Decode(&page); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.False(t, result.Applied)
+	assert.Len(t, result.Results, 4)
+	assert.Equal(t, http.StatusCreated, result.Results[0].Status)
+	assert.Equal(t, "Batch title", result.Results[0].Task.Title)
+	assert.Equal(t, http.StatusOK, result.Results[1].Status)
+	assert.True(t, result.Results[1].Task.IsCompleted)
+	assert.Equal(t, http.StatusBadRequest, result.Results[2].Status)
+	assert.Equal(t, "title", result.Results[2].Error.Errors[0].Field)
+	assert.Equal(t, http.StatusOK, result.Results[3].Status)
+	assert.Len(t, page.Tasks, 2)
+	cleanDB()
+}
+
+// atomic batches apply nothing when an operation fails
+func TestBatchTasksAtomicFail(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(1)
+	batch := map[string]any{
+		"atomic": true,
+		"operations": []map[string]any{
+			{"op": "create", "data": map[string]any{"title": "Batch title", "description": "Batch description"}},
+			{"op": "delete", "id": tasks[0].Id},
+			{"op": "update", "id": "missing-task", "data": map[string]any{"title": "Title"}},
+		},
+	}
+
+	// act
+	response := makeRequest("POST", "/tasks:batch", batch)
+	listResponse := makeRequest("GET", "/tasks", nil)
+	unknownResponse := makeRequest("POST", "/tasks:archive", batch)
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, http.StatusNotFound, unknownResponse.Code)
+
+	var result httpController.BatchResponse
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var page services.TaskPage
+	if err := json.NewDecoder(listResponse.Body).Decode(&page); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.False(t, result.Applied)
+	assert.Equal(t, http.StatusFailedDependency, result.Results[0].Status)
+	assert.Equal(t, httperrors.FAILED_DEPENDENCY, result.Results[1].Error.Id)
+	assert.Equal(t, http.StatusNotFound, result.Results[2].Status)
+	assert.Len(t, page.Tasks, 1)
+	assert.Equal(t, tasks[0].Id, page.Tasks[0].Id)
+	cleanDB()
+}
+
+func TestSubtaskTreeSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(1)
+	subtask := map[string]any{
+		"title":       "Subtask title",
+		"description": "Subtask description",
+		"parent_id":   tasks[0].Id,
+	}
+
+	// act
+	createResponse := makeRequest("POST", "/tasks", subtask)
+	listResponse := makeRequest("GET", fmt.Sprintf("/tasks/%s/subtasks", tasks[0].Id), nil)
+	treeResponse := makeRequest("GET", fmt.Sprintf("/tasks/%s/tree", tasks[0].Id), nil)
+	completeResponse := makeRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), map[string]any{"status": "done"})
+
+	// assert
+	assert.Equal(t, http.StatusCreated, createResponse.Code)
+	assert.Equal(t, http.StatusOK, listResponse.Code)
+	assert.Equal(t, http.StatusOK, treeResponse.Code)
+	assert.Equal(t, http.StatusBadRequest, completeResponse.Code)
+
+	var page services.TaskPage
+	if err := json.NewDecoder(listResponse.Body).Decode(&page); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var tree services.TaskTree
+	if err := json.NewDecoder(treeResponse.Body).Decode(&tree); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Len(t, page.Tasks, 1)
+	assert.Equal(t, "Subtask title", page.Tasks[0].Title)
+	assert.Equal(t, tasks[0].Id, tree.Task.Id)
+	assert.Equal(t, services.TaskProgress{Done: 0, Total: 1}, tree.Progress)
+	assert.Len(t, tree.Subtasks, 1)
+	cleanDB()
+}
+
+// a task can't be done while its blockers are open
+func TestDependencyBlocksCompletionFail(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(2)
+
+	// act
+	addResponse := makeRequest("POST", fmt.Sprintf("/tasks/%s/dependencies", tasks[0].Id), map[string]any{"blocker_id": tasks[1].Id})
+	blockingResponse := makeRequest("GET", fmt.Sprintf("/tasks/%s/blocking", tasks[1].Id), nil)
+	planResponse := makeRequest("GET", "/tasks/plan", nil)
+	completeResponse := makeRequest("PATCH", fmt.Sprintf("/tasks/%s", tasks[0].Id), map[string]any{"status": "done"})
+	removeResponse := makeRequest("DELETE", fmt.Sprintf("/tasks/%s/dependencies/%s", tasks[0].Id, tasks[1].Id), nil)
+
+	// assert
+	assert.Equal(t, http.StatusCreated, addResponse.Code)
+	assert.Equal(t, http.StatusOK, blockingResponse.Code)
+	assert.Equal(t, http.StatusOK, planResponse.Code)
+	assert.Equal(t, http.StatusBadRequest, completeResponse.Code)
+	assert.Equal(t, http.StatusOK, removeResponse.Code)
+
+	var blocking services.TaskList
+	if err := json.NewDecoder(blockingResponse.Body).Decode(&blocking); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var plan services.TaskPlan
+	if err := json.NewDecoder(planResponse.Body).Decode(&plan); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var problem httperrors.HttpError
+	if err := json.NewDecoder(completeResponse.Body).Decode(&problem); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Len(t, blocking.Tasks, 1)
+	assert.Equal(t, tasks[0].Id, blocking.Tasks[0].Id)
+	assert.Len(t, plan.Tasks, 2)
+	assert.Equal(t, tasks[1].Id, plan.Tasks[0].Task.Id)
+	assert.Equal(t, []string{tasks[1].Id}, plan.Tasks[1].BlockedBy)
+	assert.Equal(t, "blocked_by", problem.Errors[0].Field)
+	cleanDB()
+}
+
+// every write to a task is in its history, admins read the history of every task
+func TestTaskHistorySuccess(t *testing.T) {
+	// prepare
+	from := time.Now().UTC().Format(time.RFC3339Nano)
+	createResponse := makeRequest("POST", "/tasks", map[string]any{"title": "Title", "description": "Description"})
+	var created entities.Task
+	if err := json.NewDecoder(createResponse.Body).Decode(&created); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	admin := map[string]string{"Authorization": "Bearer " + adminToken}
+
+	// act
+	makeRequest("PATCH", fmt.Sprintf("/tasks/%s", created.Id), map[string]any{"status": "in_progress"})
+	historyResponse := makeRequest("GET", fmt.Sprintf("/tasks/%s/history", created.Id), nil)
+	auditResponse := makeRequestWithHeaders("GET", fmt.Sprintf("/audit?actor=%s&from=%s", ownerId, from), nil, admin)
+	forbiddenResponse := makeRequest("GET", "/audit", nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, historyResponse.Code)
+	assert.Equal(t, http.StatusOK, auditResponse.Code)
+	assert.Equal(t, http.StatusForbidden, forbiddenResponse.Code)
+
+	var history, audit services.EventPage
+	if err := json.NewDecoder(historyResponse.Body).Decode(&history); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	if err := json.NewDecoder(auditResponse.Body).Decode(&audit); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Len(t, history.Events, 2)
+	assert.Equal(t, entities.ActionCreate, history.Events[0].Action)
+	assert.Equal(t, entities.ActionUpdate, history.Events[1].Action)
+	assert.Equal(t, []entities.Change{{Field: "Status", From: "todo", To: "in_progress"}}, history.Events[1].Changes)
+	assert.Equal(t, ownerId, history.Events[1].ActorId)
+	assert.Len(t, audit.Events, 2)
+	cleanDB()
+}
+
+// readStreamEvent reads the fields of the next event of a Server-Sent Events
+// stream, skipping the comments.
+func readStreamEvent(t testing.TB, reader *bufio.Reader) map[string]string {
+	t.Helper()
+
+	fields := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream read error: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		fields[field] = value
+	}
+}
+
+// the stream sends the changes of the caller, and resumes after the last event
+// the client got
+func TestStreamTaskChangesSuccess(t *testing.T) {
+	// prepare
+	server := httptest.NewServer(httpController.Server)
+	defer server.Close()
+	client := &http.Client{Timeout: 5 * time.Second}
+	stream := func(lastEventId string) *http.Response {
+		request, _ := http.NewRequest("GET", server.URL+"/tasks/events", nil)
+		request.Header.Set("Authorization", "Bearer "+testToken)
+		if lastEventId != "" {
+			request.Header.Set("Last-Event-ID", lastEventId)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			t.Fatalf("stream request error: %v", err)
+		}
+		return response
+	}
+	response := stream("")
+
+	// act
+	createResponse := makeRequest("POST", "/tasks", map[string]any{"title": "Title", "description": "Description"})
+	event := readStreamEvent(t, bufio.NewReader(response.Body))
+	response.Body.Close()
+	id, _ := strconv.ParseInt(event["id"], 10, 64)
+	resumed := stream(strconv.FormatInt(id-1, 10))
+	replayed := readStreamEvent(t, bufio.NewReader(resumed.Body))
+	resumed.Body.Close()
+	invalidResponse := makeRequestWithHeaders("GET", "/tasks/events", nil, map[string]string{"Last-Event-ID": "last"})
+
+	// assert
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Equal(t, http.StatusBadRequest, invalidResponse.Code)
+
+	var created, change struct {
+		Type string
+		Task entities.Task
+	}
+	if err := json.NewDecoder(createResponse.Body).Decode(&created.Task); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	if err := json.Unmarshal([]byte(event["data"]), &change); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, "created", event["event"])
+	assert.Equal(t, created.Task.Id, change.Task.Id)
+	assert.Equal(t, event, replayed)
+	cleanDB()
+}
+
+// the socket sends the changes of the caller, and resumes after the last one
+// the client got
+func TestSyncTasksSuccess(t *testing.T) {
+	// prepare
+	server := httptest.NewServer(httpController.Server)
+	defer server.Close()
+	type change struct {
+		Id     int64
+		Type   string
+		TaskId string
+		Task   *entities.Task
+	}
+	dial := func(query string) *websocket.Conn {
+		config, _ := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, server.URL)
+		config.Header.Set("Authorization", "Bearer "+testToken)
+		conn, err := websocket.DialConfig(config)
+		if err != nil {
+			t.Fatalf("socket dial error: %v", err)
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		return conn
+	}
+	conn := dial("")
+
+	// act
+	createResponse := makeRequest("POST", "/tasks", map[string]any{"title": "Title", "description": "Description"})
+	var created change
+	if err := websocket.JSON.Receive(conn, &created); err != nil {
+		t.Fatalf("socket receive error: %v", err)
+	}
+	conn.Close()
+	resumed := dial(fmt.Sprintf("?last_event_id=%d", created.Id-1))
+	var replayed change
+	if err := websocket.JSON.Receive(resumed, &replayed); err != nil {
+		t.Fatalf("socket receive error: %v", err)
+	}
+	resumed.Close()
+	invalidResponse := makeRequest("GET", "/ws?last_event_id=last", nil)
+
+	// assert
+	var task entities.Task
+	if err := json.NewDecoder(createResponse.Body).Decode(&task); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, invalidResponse.Code)
+	assert.Equal(t, "created", created.Type)
+	assert.Equal(t, task.Id, created.TaskId)
+	assert.Equal(t, task.Id, created.Task.Id)
+	assert.Equal(t, created, replayed)
+	cleanDB()
+}
+
+func TestWebhookDeliverySuccess(t *testing.T) {
+	// prepare
+	secret := "webhook-test-secret"
+	received := make(chan *http.Request, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		received <- r
+	}))
+	defer receiver.Close()
+	webhookResponse := makeRequest("POST", "/webhooks", map[string]any{"url": receiver.URL, "events": []string{"task.completed"}, "secret": secret})
+	var webhook entities.Webhook
+	if err := json.NewDecoder(webhookResponse.Body).Decode(&webhook); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	createResponse := makeRequest("POST", "/tasks", map[string]any{"title": "Title", "description": "Description"})
+	var created entities.Task
+	if err := json.NewDecoder(createResponse.Body).Decode(&created); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	dispatcher, _ := taskService.NewTaskService(storage)
+
+	// act
+	makeRequest("PATCH", fmt.Sprintf("/tasks/%s", created.Id), map[string]any{"status": "done"})
+	err := dispatcher.DeliverWebhooks()
+	deliveriesResponse := makeRequest("GET", fmt.Sprintf("/webhooks/%s/deliveries?status=delivered", webhook.Id), nil)
+	invalidResponse := makeRequest("POST", "/webhooks", map[string]any{"url": receiver.URL, "events": []string{"task.archived"}, "secret": secret})
+
+	// assert
+	assert.Equal(t, http.StatusCreated, webhookResponse.Code)
+	assert.NotContains(t, webhookResponse.Body.String(), secret)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, deliveriesResponse.Code)
+	assert.Equal(t, http.StatusBadRequest, invalidResponse.Code)
+
+	request := <-received
+	body, _ := io.ReadAll(request.Body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(request.Header.Get("X-Webhook-Timestamp") + "."))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.Header.Get("X-Webhook-Signature"))
+	assert.Equal(t, "task.completed", request.Header.Get("X-Webhook-Event"))
+	assert.Len(t, received, 0)
+
+	var page services.DeliveryPage
+	if err := json.NewDecoder(deliveriesResponse.Body).Decode(&page); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	assert.Len(t, page.Deliveries, 1)
+	assert.Equal(t, created.Id, page.Deliveries[0].TaskId)
+	assert.Equal(t, 1, page.Deliveries[0].Attempts)
+
+	makeRequest("DELETE", fmt.Sprintf("/webhooks/%s", webhook.Id), nil)
+	cleanDB()
+}
+
+func TestGraphQLSuccess(t *testing.T) {
+	// prepare
+	tasks := prepareDBTasks(1)
+	query := url.QueryEscape(`mutation { deleteTask(id: "` + tasks[0].Id + `") }`)
+	noRole := map[string]string{"Authorization": "Bearer " + noRoleToken}
+
+	// act
+	createResponse := makeRequest("POST", "/graphql", map[string]any{
+		"query":     `mutation($input: CreateTaskInput!) { createTask(input: $input) { id parentId } }`,
+		"variables": map[string]any{"input": map[string]any{"title": "Subtask", "description": "Description", "parentId": tasks[0].Id}},
+	})
+	queryResponse := makeRequest("GET", "/graphql?query="+url.QueryEscape(`{ tasks { tasks { id subtasks { tasks { title } } } } }`), nil)
+	mutationResponse := makeRequest("GET", "/graphql?query="+query, nil)
+	forbiddenResponse := makeRequestWithHeaders("POST", "/graphql", map[string]any{"query": `{ tasks { nextCursor } }`}, noRole)
+	missingResponse := makeRequest("POST", "/graphql", map[string]any{})
+
+	// assert
+	assert.Equal(t, http.StatusOK, createResponse.Code)
+	assert.Equal(t, http.StatusOK, queryResponse.Code)
+	assert.Equal(t, http.StatusOK, mutationResponse.Code)
+	assert.Equal(t, http.StatusOK, forbiddenResponse.Code)
+	assert.Equal(t, http.StatusBadRequest, missingResponse.Code)
+
+	var created struct {
+		Data struct {
+			CreateTask struct {
+				Id       string `json:"id"`
+				ParentId string `json:"parentId"`
+			} `json:"createTask"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(createResponse.Body).Decode(&created); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var listed struct {
+		Data struct {
+			Tasks struct {
+				Tasks []struct {
+					Id       string `json:"id"`
+					Subtasks struct {
+						Tasks []struct {
+							Title string `json:"title"`
+						} `json:"tasks"`
+					} `json:"subtasks"`
+				} `json:"tasks"`
+			} `json:"tasks"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(queryResponse.Body).Decode(&listed); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	var mutation, forbidden struct {
+		Errors []struct {
+			Extensions map[string]any `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(mutationResponse.Body).Decode(&mutation); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+	if err := json.NewDecoder(forbiddenResponse.Body).Decode(&forbidden); err != nil {
+		t.Fail()
+		t.Logf("JSON decode error: %v", err)
+	}
+
+	assert.NotEmpty(t, created.Data.CreateTask.Id)
+	assert.Equal(t, tasks[0].Id, created.Data.CreateTask.ParentId)
+	assert.Len(t, listed.Data.Tasks.Tasks, 2)
+	for _, task := range listed.Data.Tasks.Tasks {
+		if task.Id == tasks[0].Id {
+			assert.Len(t, task.Subtasks.Tasks, 1)
+		}
+	}
+	if assert.Len(t, mutation.Errors, 1) {
+		assert.Equal(t, httperrors.INVALID_PARAM, mutation.Errors[0].Extensions["code"])
+	}
+	if assert.Len(t, forbidden.Errors, 1) {
+		assert.Equal(t, httperrors.FORBIDDEN, forbidden.Errors[0].Extensions["code"])
+	}
+	cleanDB()
+}
@@ -0,0 +1,7 @@
+package task
+
+// Dependency tells that a task can't be done before its blocker is done.
+type Dependency struct {
+	TaskId    string
+	BlockerId string
+}
@@ -0,0 +1,122 @@
+package task
+
+import (
+	"reflect"
+	"time"
+)
+
+// Actions of a task event.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+)
+
+// Change is the value of a task field before and after an event, From is nil
+// for a created task.
+type Change struct {
+	Field string
+	From  any
+	To    any
+}
+
+// Event is a write to a task in its change history.
+type Event struct {
+	Id      int64
+	TaskId  string
+	OwnerId string
+	// ActorId is the user who made the change.
+	ActorId   string
+	Action    string
+	Changes   []Change
+	CreatedAt time.Time
+}
+
+// EventOptions filters the change history, the events come in the order
+// they happened.
+type EventOptions struct {
+	OwnerId *string
+	TaskId  *string
+	ActorId *string
+	// From and To bound the time of the events, both included.
+	From  *time.Time
+	To    *time.Time
+	After int64
+	Limit int
+}
+
+// Matches reports whether the event passes the filters of the options.
+func (options EventOptions) Matches(event Event) bool {
+	return (options.OwnerId == nil || event.OwnerId == *options.OwnerId) &&
+		(options.TaskId == nil || event.TaskId == *options.TaskId) &&
+		(options.ActorId == nil || event.ActorId == *options.ActorId) &&
+		(options.From == nil || !event.CreatedAt.Before(*options.From)) &&
+		(options.To == nil || !event.CreatedAt.After(*options.To)) &&
+		event.Id > options.After
+}
+
+// fieldValues are the fields of a task that its history follows, nil for a
+// field that is not set.
+var fieldValues = []struct {
+	field string
+	value func(t *Task) any
+}{
+	{"Title", func(t *Task) any { return t.Title }},
+	{"Description", func(t *Task) any { return t.Description }},
+	{"Status", func(t *Task) any { return t.Status }},
+	{"Priority", func(t *Task) any { return t.Priority }},
+	{"DueAt", func(t *Task) any { return timeValue(t.DueAt) }},
+	{"Tags", func(t *Task) any {
+		if len(t.Tags) == 0 {
+			return nil
+		}
+		return t.Tags
+	}},
+	{"ParentId", func(t *Task) any { return stringValue(t.ParentId) }},
+	{"Recurrence", func(t *Task) any { return stringValue(t.Recurrence) }},
+	{"SeriesId", func(t *Task) any { return stringValue(t.SeriesId) }},
+	{"IsCompleted", func(t *Task) any { return t.IsCompleted }},
+}
+
+// Diff returns the fields that differ between the task before and after a
+// write, every field that is set when before is nil.
+func Diff(before, after *Task) []Change {
+	changes := []Change{}
+	for _, field := range fieldValues {
+		to := field.value(after)
+		if before == nil {
+			if to != nil {
+				changes = append(changes, Change{Field: field.field, To: to})
+			}
+			continue
+		}
+		if from := field.value(before); !reflect.DeepEqual(from, to) {
+			changes = append(changes, Change{Field: field.field, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+func timeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	// times of the same instant read from different sources compare equal
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func stringValue(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+// Notification tells that a task was written, with the action of the write,
+// it is sent between the replicas of the API.
+type Notification struct {
+	TaskId  string
+	OwnerId string
+	Action  string
+}
@@ -0,0 +1,13 @@
+package task
+
+// MaxDepth is the number of levels of a task hierarchy, a top level task is
+// at depth 1.
+const MaxDepth = 5
+
+// Policies to complete a task with open subtasks.
+const (
+	// CompletionBlock refuses to complete a task until its subtasks are done.
+	CompletionBlock = "block"
+	// CompletionCascade completes the open subtasks along with the task.
+	CompletionCascade = "cascade"
+)
@@ -0,0 +1,47 @@
+package task
+
+import "time"
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	SortByTitle     = "title"
+)
+
+// Cursor is the position of the last task of a page, the next page starts
+// right after it in the sort order.
+type Cursor struct {
+	Value string
+	Id    string
+}
+
+type ListOptions struct {
+	SortBy       string
+	Descending   bool
+	Limit        int
+	After        *Cursor
+	IsCompleted  *bool
+	CreatedAfter *time.Time
+	// Tags keeps the tasks with any of the tags, or all of them with AllTags.
+	Tags    []string
+	AllTags bool
+	// ParentId keeps the direct subtasks of the task.
+	ParentId *string
+	// SeriesId keeps the occurrences of a recurring task.
+	SeriesId *string
+	// Deleted lists the tasks in the trash instead of the live ones.
+	Deleted bool
+}
+
+// SortValue returns the value of the field tasks are sorted by, in the same
+// format it is stored in a Cursor.
+func (t *Task) SortValue(sortBy string) string {
+	switch sortBy {
+	case SortByUpdatedAt:
+		return t.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case SortByTitle:
+		return t.Title
+	default:
+		return t.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
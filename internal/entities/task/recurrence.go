@@ -0,0 +1,278 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of a recurrence rule.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxPeriods bounds how many periods Next looks through for a date that
+// matches the rule, like a BYMONTHDAY=31 with a month interval of 2 that no
+// month ever has.
+const maxPeriods = 1000
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum is a BYDAY value, a weekday with an optional position in the
+// month like -1 for the last one.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Recurrence is the subset of an iCalendar RRULE (RFC 5545) that tasks
+// support: FREQ, INTERVAL, COUNT, UNTIL, BYDAY for weekly and monthly rules
+// and BYMONTHDAY for monthly rules. The due date of the task is the start of
+// the series.
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// ParseRecurrence parses a rule like "FREQ=WEEKLY;BYDAY=MO,TH", with or
+// without the "RRULE:" prefix.
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	if len(rule) >= 6 && strings.EqualFold(rule[:6], "RRULE:") {
+		rule = rule[6:]
+	}
+
+	r := &Recurrence{Interval: 1}
+	seen := map[string]bool{}
+	for part := range strings.SplitSeq(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name, value = strings.ToUpper(strings.TrimSpace(name)), strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("RRULE part '%s' must look like NAME=value", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("RRULE part %s is repeated", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			if value != FreqDaily && value != FreqWeekly && value != FreqMonthly && value != FreqYearly {
+				return nil, fmt.Errorf("RRULE FREQ must be one of %s, %s, %s or %s", FreqDaily, FreqWeekly, FreqMonthly, FreqYearly)
+			}
+			r.Freq = value
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 {
+				return nil, fmt.Errorf("RRULE INTERVAL must be a positive number")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err != nil || r.Count < 1 {
+				return nil, fmt.Errorf("RRULE COUNT must be a positive number")
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		case "BYDAY":
+			for day := range strings.SplitSeq(value, ",") {
+				weekday, ok := weekdays[day[max(len(day)-2, 0):]]
+				if !ok {
+					return nil, fmt.Errorf("RRULE BYDAY value '%s' is not a weekday like MO or -1FR", day)
+				}
+				n := 0
+				if prefix := day[:len(day)-2]; prefix != "" {
+					n, err = strconv.Atoi(prefix)
+					if err != nil || n == 0 || n < -5 || n > 5 {
+						return nil, fmt.Errorf("RRULE BYDAY value '%s' is not a weekday like MO or -1FR", day)
+					}
+				}
+				r.ByDay = append(r.ByDay, WeekdayNum{N: n, Weekday: weekday})
+			}
+		case "BYMONTHDAY":
+			for day := range strings.SplitSeq(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("RRULE BYMONTHDAY value '%s' must be a day between 1 and 31, or -31 and -1", day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("RRULE WKST can only be MO")
+			}
+		default:
+			return nil, fmt.Errorf("RRULE part %s is not supported", name)
+		}
+	}
+
+	switch {
+	case r.Freq == "":
+		return nil, fmt.Errorf("RRULE FREQ is required")
+	case r.Count > 0 && r.Until != nil:
+		return nil, fmt.Errorf("RRULE can't have both COUNT and UNTIL")
+	case len(r.ByDay) > 0 && r.Freq != FreqWeekly && r.Freq != FreqMonthly:
+		return nil, fmt.Errorf("RRULE BYDAY is only supported with a WEEKLY or MONTHLY FREQ")
+	case len(r.ByMonthDay) > 0 && r.Freq != FreqMonthly:
+		return nil, fmt.Errorf("RRULE BYMONTHDAY is only supported with a MONTHLY FREQ")
+	case len(r.ByDay) > 0 && len(r.ByMonthDay) > 0:
+		return nil, fmt.Errorf("RRULE can't have both BYDAY and BYMONTHDAY")
+	case r.Freq == FreqWeekly && slices.ContainsFunc(r.ByDay, func(day WeekdayNum) bool { return day.N != 0 }):
+		return nil, fmt.Errorf("RRULE BYDAY of a WEEKLY FREQ can't have a position")
+	}
+
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// a date includes the whole day
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("RRULE UNTIL must be a date like 20250131 or a UTC time like 20250131T170000Z")
+}
+
+// String formats the rule the way it is stored.
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := []string{}
+		for _, day := range r.ByDay {
+			name := strings.ToUpper(day.Weekday.String()[:2])
+			if day.N != 0 {
+				name = strconv.Itoa(day.N) + name
+			}
+			days = append(days, name)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := []string{}
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first date of the rule after the occurrence at after, at
+// the same time of day, or nil when the series ends before it. COUNT is left
+// to the caller, it is the number of occurrences that are left.
+func (r *Recurrence) Next(after time.Time) *time.Time {
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.candidates(after, period*r.Interval)
+		slices.SortFunc(candidates, func(a, b time.Time) int { return a.Compare(b) })
+		for _, candidate := range candidates {
+			if !candidate.After(after) {
+				continue
+			}
+			if r.Until != nil && candidate.After(*r.Until) {
+				return nil
+			}
+			return &candidate
+		}
+	}
+	return nil
+}
+
+// candidates returns the dates of the rule in the period that is offset
+// periods after the one of start.
+func (r *Recurrence) candidates(start time.Time, offset int) []time.Time {
+	year, month, day := start.Date()
+	hour, minute, second := start.Clock()
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, start.Nanosecond(), start.Location())
+	}
+
+	switch r.Freq {
+	case FreqDaily:
+		return []time.Time{date(year, month, day+offset)}
+	case FreqWeekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{date(year, month, day+7*offset)}
+		}
+		// weeks start on Monday
+		monday := day - (int(start.Weekday())+6)%7 + 7*offset
+		dates := []time.Time{}
+		for _, byDay := range r.ByDay {
+			dates = append(dates, date(year, month, monday+(int(byDay.Weekday)+6)%7))
+		}
+		return dates
+	case FreqMonthly:
+		first := date(year, month+time.Month(offset), 1)
+		days := daysIn(first.Year(), first.Month())
+		monthDays := r.ByMonthDay
+		if len(r.ByDay) == 0 && len(monthDays) == 0 {
+			monthDays = []int{day}
+		}
+		dates := []time.Time{}
+		for _, n := range monthDays {
+			if n < 0 {
+				n = days + n + 1
+			}
+			// months without the day are skipped
+			if n >= 1 && n <= days {
+				dates = append(dates, date(first.Year(), first.Month(), n))
+			}
+		}
+		for _, byDay := range r.ByDay {
+			for n := 1; n <= days; n++ {
+				d := date(first.Year(), first.Month(), n)
+				if d.Weekday() != byDay.Weekday {
+					continue
+				}
+				position, fromEnd := (n-1)/7+1, -((days-n)/7 + 1)
+				if byDay.N == 0 || byDay.N == position || byDay.N == fromEnd {
+					dates = append(dates, d)
+				}
+			}
+		}
+		return dates
+	default:
+		// February 29 only comes back in leap years
+		if day > daysIn(year+offset, month) {
+			return nil
+		}
+		return []time.Time{date(year+offset, month, day)}
+	}
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchTerm is a single word or a quoted phrase of a search query, the last
+// word of a term ending with '*' is matched as a prefix.
+type SearchTerm struct {
+	Words  []string
+	Prefix bool
+}
+
+type SearchOptions struct {
+	Terms  []SearchTerm
+	Limit  int
+	Offset int
+}
+
+type SearchResult struct {
+	Task
+	Rank      float64
+	Highlight string
+	Snippet   string
+}
+
+// ParseSearchQuery splits a query into terms that all have to match. Text in
+// double quotes is a phrase, and anything other than letters and digits only
+// separates words.
+func ParseSearchQuery(query string) []SearchTerm {
+	terms := []SearchTerm{}
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			if term, ok := searchTerm(part); ok {
+				terms = append(terms, term)
+			}
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			if term, ok := searchTerm(field); ok {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+func searchTerm(text string) (SearchTerm, bool) {
+	words := SearchWords(text)
+	return SearchTerm{
+		Words:  words,
+		Prefix: strings.HasSuffix(strings.TrimSpace(text), "*"),
+	}, len(words) > 0
+}
+
+// SearchWords lower-cases text and splits it into words the same way search
+// queries are split.
+func SearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSearchSeparator)
+}
+
+func isSearchSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,62 @@
+package task
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+)
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var Statuses = []string{StatusTodo, StatusInProgress, StatusBlocked, StatusDone}
+
+var Priorities = []string{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+func IsStatus(status string) bool {
+	return slices.Contains(Statuses, status)
+}
+
+func IsPriority(priority string) bool {
+	return slices.Contains(Priorities, priority)
+}
+
+// Details are the planning fields of a task, on top of its title and
+// description.
+type Details struct {
+	Status     string
+	Priority   string
+	DueAt      *time.Time
+	ParentId   *string
+	Recurrence *string
+	SeriesId   *string
+}
+
+// WithDefaults fills the fields left empty, a new task is a todo of medium
+// priority.
+func (d Details) WithDefaults() Details {
+	if d.Status == "" {
+		d.Status = StatusTodo
+	}
+	if d.Priority == "" {
+		d.Priority = PriorityMedium
+	}
+	return d
+}
+
+// SetStatus changes the status of the task and keeps IsCompleted in sync,
+// a task is completed when it is done.
+func (t *Task) SetStatus(status string) {
+	t.Status = status
+	t.IsCompleted = status == StatusDone
+}
@@ -0,0 +1,34 @@
+package task
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	MaxTagLength   = 64
+	MaxTagsPerTask = 20
+)
+
+// TagCount is a tag of an owner with the number of live tasks using it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// NormalizeTag lowercases a tag name and joins its words with '-', so
+// "Work Stuff" and "work-stuff" are the same tag. It reports false for names
+// that are empty, too long or hold anything but letters, digits, '-' and '_'.
+func NormalizeTag(name string) (string, bool) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(name), "-"))
+	if normalized == "" || utf8.RuneCountInString(normalized) > MaxTagLength {
+		return "", false
+	}
+	for _, r := range normalized {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return "", false
+		}
+	}
+	return normalized, true
+}
@@ -4,9 +4,32 @@ import "time"
 
 type Task struct {
 	Id          string
+	OwnerId     string
 	Title       string
 	Description string
+	Status      string
+	Priority    string
+	DueAt       *time.Time
+	Tags        []string
+	// ParentId is the task this one is a subtask of, nil for a top level
+	// task.
+	ParentId *string
+	// Recurrence is the RRULE of a recurring task, the next occurrence is
+	// created when this one is done.
+	Recurrence *string
+	// SeriesId links the occurrences of a recurring task, it is the ID of
+	// the first one.
+	SeriesId *string
+	// IsCompleted is derived from Status, it is kept for the clients that
+	// predate the status workflow.
 	IsCompleted bool
+	Version     int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   *time.Time
+}
+
+// IsDeleted reports whether the task is in the trash.
+func (t *Task) IsDeleted() bool {
+	return t.DeletedAt != nil
 }
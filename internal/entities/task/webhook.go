@@ -0,0 +1,81 @@
+package task
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Event types a webhook subscribes to.
+const (
+	WebhookCreated   = "task.created"
+	WebhookUpdated   = "task.updated"
+	WebhookCompleted = "task.completed"
+	WebhookDeleted   = "task.deleted"
+	WebhookRestored  = "task.restored"
+)
+
+var WebhookEvents = []string{WebhookCreated, WebhookUpdated, WebhookCompleted, WebhookDeleted, WebhookRestored}
+
+// Statuses of a delivery.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook calls URL with the events of the tasks of its owner that are in
+// Events.
+type Webhook struct {
+	Id      string
+	OwnerId string
+	URL     string
+	Events  []string
+	// Secret signs the deliveries, it is never sent back.
+	Secret    string `json:"-"`
+	CreatedAt time.Time
+}
+
+// Subscribes reports whether the webhook is called with the events of the
+// type.
+func (webhook Webhook) Subscribes(event string) bool {
+	return slices.Contains(webhook.Events, event)
+}
+
+// Delivery is a call of a webhook with an event. It waits in the outbox until
+// it is delivered or runs out of attempts, and stays in the delivery log of
+// the webhook after.
+type Delivery struct {
+	Id        int64
+	WebhookId string
+	OwnerId   string
+	Event     string
+	TaskId    string
+	// Payload is the body of the call, built when the event happened.
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	// ResponseStatus and LastError tell how the last attempt went,
+	// ResponseStatus is nil when the receiver could not be reached.
+	ResponseStatus *int
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// DeliveryOptions filters the delivery log of a webhook, the latest
+// deliveries come first.
+type DeliveryOptions struct {
+	Status *string
+	// Before keeps the deliveries older than the one with this ID, when it is
+	// not 0.
+	Before int64
+	Limit  int
+}
+
+// Matches reports whether the delivery passes the filters of the options.
+func (options DeliveryOptions) Matches(delivery Delivery) bool {
+	return (options.Status == nil || delivery.Status == *options.Status) &&
+		(options.Before == 0 || delivery.Id < options.Before)
+}
@@ -4,6 +4,7 @@ const (
 	INVALID_INPUT = "INVALID_INPUT"
 	NOT_FOUND     = "NOT_FOUND"
 	NO_OPERATION  = "NOOP"
+	PRECONDITION  = "PRECONDITION_FAILED"
 )
 
 type AppError struct {
@@ -47,3 +48,7 @@ func NotFoundError(detail string) *AppError {
 func NoOp(detail string) *AppError {
 	return New(NO_OPERATION, "No operation happened", detail, nil)
 }
+
+func PreconditionFailedError(detail string) *AppError {
+	return New(PRECONDITION, "Precondition failed", detail, nil)
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+)
+
+// Types of a task change.
+const (
+	Created = "created"
+	Updated = "updated"
+	Deleted = "deleted"
+	// Reset tells a subscriber that it missed changes and has to reload the
+	// tasks.
+	Reset = "reset"
+)
+
+// subscriberBuffer is how many changes a subscriber can fall behind before
+// it is dropped, it catches up again from the replay buffer.
+const subscriberBuffer = 64
+
+// Change is a task that changed, Task is nil when it was deleted.
+type Change struct {
+	Id      int64
+	Type    string
+	OwnerId string
+	TaskId  string
+	Task    *task.Task
+}
+
+// Bus hands the task changes to the subscribers of their owner, and keeps
+// the latest ones so that a subscriber can resume after a disconnect.
+type Bus struct {
+	mu     sync.Mutex
+	nextId int64
+	// replay holds at most size changes, the oldest first.
+	replay      []Change
+	size        int
+	subscribers map[*Subscription]bool
+}
+
+// NewBus keeps the last size changes for replay. The IDs start from the
+// time the bus is created, so an ID from before a restart is never taken
+// for a newer change.
+func NewBus(size int) *Bus {
+	return &Bus{
+		nextId:      time.Now().UnixNano(),
+		size:        max(size, 0),
+		subscribers: map[*Subscription]bool{},
+	}
+}
+
+// Publish gives the change its ID and sends it to the subscribers of its
+// owner. A subscriber that can't keep up is closed instead of blocking the
+// writes.
+func (b *Bus) Publish(change Change) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	change.Id = b.nextId
+	b.nextId++
+	if b.size > 0 {
+		if len(b.replay) == b.size {
+			b.replay = b.replay[1:]
+		}
+		b.replay = append(b.replay, change)
+	}
+
+	for subscription := range b.subscribers {
+		if subscription.ownerId != change.OwnerId {
+			continue
+		}
+		select {
+		case subscription.changes <- change:
+		default:
+			b.remove(subscription)
+		}
+	}
+}
+
+// Reset drops the kept changes and closes every subscription, for when
+// changes were lost on the way to the bus. The subscribers that resume are
+// told that they missed changes.
+func (b *Bus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.replay = nil
+	// no change gets this ID, so every ID before it is missed
+	b.nextId++
+	for subscription := range b.subscribers {
+		b.remove(subscription)
+	}
+}
+
+// Subscription receives the changes of the tasks of one owner.
+type Subscription struct {
+	bus     *Bus
+	ownerId string
+	changes chan Change
+	// Replay holds the changes after the ID the subscription resumed from.
+	Replay []Change
+	// Missed tells that the changes after that ID are not kept anymore, the
+	// subscriber has to reload the tasks.
+	Missed bool
+}
+
+// Subscribe starts receiving the changes of the owner, resuming after the
+// change with lastId when it is not 0.
+func (b *Bus) Subscribe(ownerId string, lastId int64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscription := &Subscription{
+		bus:     b,
+		ownerId: ownerId,
+		changes: make(chan Change, subscriberBuffer),
+		Replay:  []Change{},
+	}
+	if lastId != 0 {
+		oldest := b.nextId
+		if len(b.replay) > 0 {
+			oldest = b.replay[0].Id
+		}
+		subscription.Missed = lastId < oldest-1 || lastId >= b.nextId
+		for _, change := range b.replay {
+			if change.OwnerId == ownerId && change.Id > lastId && !subscription.Missed {
+				subscription.Replay = append(subscription.Replay, change)
+			}
+		}
+	}
+	b.subscribers[subscription] = true
+
+	return subscription
+}
+
+// Changes is closed when the subscription is closed, or dropped for falling
+// behind.
+func (s *Subscription) Changes() <-chan Change {
+	return s.changes
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.remove(s)
+}
+
+func (b *Bus) remove(subscription *Subscription) {
+	if b.subscribers[subscription] {
+		delete(b.subscribers, subscription)
+		close(subscription.changes)
+	}
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"testing"
+)
+
+const owner = "test-owner"
+
+func TestBus(t *testing.T) {
+	t.Run("subscribers only get the changes of their owner", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription := bus.Subscribe(owner, 0)
+		defer subscription.Close()
+
+		bus.Publish(Change{Type: Created, OwnerId: "other-owner", TaskId: "task-1"})
+		bus.Publish(Change{Type: Created, OwnerId: owner, TaskId: "task-2"})
+
+		change := <-subscription.Changes()
+		if change.TaskId != "task-2" || change.Type != Created {
+			t.Errorf("expected task-2 to be created, but got %+v", change)
+		}
+		if len(subscription.Changes()) != 0 {
+			t.Errorf("expected no other change, but got %d", len(subscription.Changes()))
+		}
+	})
+	t.Run("resumed subscription replays the changes after the last one", func(t *testing.T) {
+		bus := NewBus(10)
+		first := bus.Subscribe(owner, 0)
+		for _, taskId := range []string{"task-1", "task-2", "task-3"} {
+			bus.Publish(Change{Type: Updated, OwnerId: owner, TaskId: taskId})
+		}
+		last := <-first.Changes()
+		first.Close()
+
+		subscription := bus.Subscribe(owner, last.Id)
+		defer subscription.Close()
+
+		if subscription.Missed || len(subscription.Replay) != 2 || subscription.Replay[0].TaskId != "task-2" {
+			t.Errorf("expected task-2 and task-3 to be replayed, but got %+v", subscription.Replay)
+		}
+	})
+	t.Run("changes that are not kept are missed", func(t *testing.T) {
+		bus := NewBus(2)
+		first := bus.Subscribe(owner, 0)
+		for _, taskId := range []string{"task-1", "task-2", "task-3", "task-4"} {
+			bus.Publish(Change{Type: Updated, OwnerId: owner, TaskId: taskId})
+		}
+		last := <-first.Changes()
+		first.Close()
+
+		for _, lastId := range []int64{last.Id, last.Id + 100} {
+			subscription := bus.Subscribe(owner, lastId)
+
+			if !subscription.Missed || len(subscription.Replay) != 0 {
+				t.Errorf("expected changes after %d to be missed, but got %+v", lastId, subscription)
+			}
+			subscription.Close()
+		}
+	})
+	t.Run("subscriber that falls behind is dropped", func(t *testing.T) {
+		bus := NewBus(0)
+		subscription := bus.Subscribe(owner, 0)
+
+		for range subscriberBuffer + 1 {
+			bus.Publish(Change{Type: Updated, OwnerId: owner, TaskId: "task-1"})
+		}
+
+		received := 0
+		for range subscription.Changes() {
+			received++
+		}
+		if received != subscriberBuffer {
+			t.Errorf("expected %d changes before the subscription closed, but got %d", subscriberBuffer, received)
+		}
+		subscription.Close()
+	})
+	t.Run("reset closes the subscriptions and misses the kept changes", func(t *testing.T) {
+		bus := NewBus(10)
+		subscription := bus.Subscribe(owner, 0)
+		bus.Publish(Change{Type: Created, OwnerId: owner, TaskId: "task-1"})
+		last := <-subscription.Changes()
+
+		bus.Reset()
+		bus.Publish(Change{Type: Updated, OwnerId: owner, TaskId: "task-1"})
+
+		if _, ok := <-subscription.Changes(); ok {
+			t.Errorf("expected the subscription to be closed")
+		}
+		resumed := bus.Subscribe(owner, last.Id)
+		defer resumed.Close()
+		if !resumed.Missed || len(resumed.Replay) != 0 {
+			t.Errorf("expected changes after %d to be missed, but got %+v", last.Id, resumed)
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/events"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/Arup3201/gotasks/internal/storages"
+)
+
+// auditedRepository adds an event to the change history for every create,
+// update, delete and restore of a task, in the transaction of the write, and
+// publishes the change to the bus once the transaction commits, unless the
+// bus is nil.
+type auditedRepository struct {
+	storages.TaskRepository
+	bus *events.Bus
+	// actorId is the caller authenticated by the controllers, the actor of
+	// the events. The owner of the task is when it is empty, for the writes
+	// the service makes on its own.
+	actorId string
+	// pending holds the changes of the transaction the repository is in, nil
+	// outside of one.
+	pending *[]events.Change
+}
+
+func (repo auditedRepository) InTransaction(fn func(repo storages.TaskRepository) error) error {
+	return repo.transaction(func(tx auditedRepository) error {
+		return fn(tx)
+	})
+}
+
+// transaction runs fn in the transaction of the repository, or in a new one
+// whose changes are published when it commits.
+func (repo auditedRepository) transaction(fn func(tx auditedRepository) error) error {
+	if repo.pending != nil {
+		return repo.TaskRepository.InTransaction(func(tx storages.TaskRepository) error {
+			return fn(auditedRepository{tx, repo.bus, repo.actorId, repo.pending})
+		})
+	}
+
+	pending := []events.Change{}
+	err := repo.TaskRepository.InTransaction(func(tx storages.TaskRepository) error {
+		return fn(auditedRepository{tx, repo.bus, repo.actorId, &pending})
+	})
+	if err != nil {
+		return err
+	}
+	if repo.bus != nil {
+		for _, change := range pending {
+			repo.bus.Publish(change)
+		}
+	}
+	return nil
+}
+
+// record adds the event of a write to the history and its deliveries to the
+// webhook outbox, and the change of the task to the ones published with the
+// transaction.
+func (repo auditedRepository) record(ownerId, taskId, action string, changes []task.Change, t *task.Task) error {
+	actorId := repo.actorId
+	if actorId == "" {
+		actorId = ownerId
+	}
+	err := repo.TaskRepository.AddEvent(task.Event{
+		TaskId:    taskId,
+		OwnerId:   ownerId,
+		ActorId:   actorId,
+		Action:    action,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := repo.enqueueDeliveries(ownerId, taskId, action, changes, t); err != nil {
+		return err
+	}
+
+	*repo.pending = append(*repo.pending, events.Change{Type: changeType(action), OwnerId: ownerId, TaskId: taskId, Task: t})
+	return nil
+}
+
+// changeType is the type of the change of a write, a restored task comes
+// back as created.
+func changeType(action string) string {
+	switch action {
+	case task.ActionUpdate:
+		return events.Updated
+	case task.ActionDelete:
+		return events.Deleted
+	default:
+		return events.Created
+	}
+}
+
+func (repo auditedRepository) Insert(ownerId, taskId string, taskTitle, taskDesc string, details task.Details) (*task.Task, error) {
+	var inserted *task.Task
+	err := repo.transaction(func(tx auditedRepository) error {
+		var err error
+		inserted, err = tx.TaskRepository.Insert(ownerId, taskId, taskTitle, taskDesc, details)
+		if err != nil {
+			return err
+		}
+		return tx.record(ownerId, taskId, task.ActionCreate, task.Diff(nil, inserted), inserted)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+func (repo auditedRepository) Update(ownerId, taskId string, version *int, data map[string]any) (*task.Task, error) {
+	var updated *task.Task
+	err := repo.transaction(func(tx auditedRepository) error {
+		before, err := tx.TaskRepository.Get(ownerId, taskId)
+		if err != nil {
+			return err
+		}
+		updated, err = tx.TaskRepository.Update(ownerId, taskId, version, data)
+		if err != nil || updated == nil {
+			return err
+		}
+		return tx.record(ownerId, taskId, task.ActionUpdate, task.Diff(before, updated), updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (repo auditedRepository) SetTags(ownerId, taskId string, version *int, tags []string) (*task.Task, error) {
+	var tagged *task.Task
+	err := repo.transaction(func(tx auditedRepository) error {
+		before, err := tx.TaskRepository.Get(ownerId, taskId)
+		if err != nil {
+			return err
+		}
+		tagged, err = tx.TaskRepository.SetTags(ownerId, taskId, version, tags)
+		if err != nil {
+			return err
+		}
+		return tx.record(ownerId, taskId, task.ActionUpdate, task.Diff(before, tagged), tagged)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagged, nil
+}
+
+func (repo auditedRepository) Delete(ownerId, taskId string, version *int) (*string, error) {
+	var dId *string
+	err := repo.transaction(func(tx auditedRepository) error {
+		var err error
+		dId, err = tx.TaskRepository.Delete(ownerId, taskId, version)
+		if err != nil {
+			return err
+		}
+		return tx.record(ownerId, taskId, task.ActionDelete, []task.Change{}, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dId, nil
+}
+
+func (repo auditedRepository) Restore(ownerId, taskId string) (*task.Task, error) {
+	var restored *task.Task
+	err := repo.transaction(func(tx auditedRepository) error {
+		var err error
+		restored, err = tx.TaskRepository.Restore(ownerId, taskId)
+		if err != nil {
+			return err
+		}
+		return tx.record(ownerId, taskId, task.ActionRestore, []task.Change{}, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// GetTaskHistory lists the events of a task from the oldest, the history of
+// a task in the trash or purged from it is kept. A task without any event,
+// like one written before the history was kept, has an empty history.
+func (ts *TaskService) GetTaskHistory(ownerId, taskId string, query services.EventsQuery) (*services.EventPage, error) {
+	options, err := eventOptions(query)
+	if err != nil {
+		return nil, err
+	}
+	options.OwnerId, options.TaskId = &ownerId, &taskId
+
+	page, err := ts.listEvents(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Events) == 0 && query.Cursor == "" {
+		if _, err := ts.taskRepository.Get(ownerId, taskId); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// GetAuditLog lists the events of the tasks of every owner from the oldest.
+func (ts *TaskService) GetAuditLog(query services.EventsQuery) (*services.EventPage, error) {
+	options, err := eventOptions(query)
+	if err != nil {
+		return nil, err
+	}
+	return ts.listEvents(options)
+}
+
+func eventOptions(query services.EventsQuery) (task.EventOptions, error) {
+	options := task.EventOptions{
+		From:  query.From,
+		To:    query.To,
+		Limit: query.Limit,
+	}
+	if query.ActorId != "" {
+		options.ActorId = &query.ActorId
+	}
+
+	if options.From != nil && options.To != nil && options.To.Before(*options.From) {
+		return options, errors.InputValidationError("Invalid list option", "Event list option 'to' is invalid", errors.AppErrorField{
+			Field:  "to",
+			Reason: "Event 'to' can't be before 'from'",
+		})
+	}
+
+	if options.Limit == 0 {
+		options.Limit = defaultPageLimit
+	}
+	if options.Limit < 0 || options.Limit > maxPageLimit {
+		return options, errors.InputValidationError("Invalid list option", "Event list option 'limit' is invalid", errors.AppErrorField{
+			Field:  "limit",
+			Reason: fmt.Sprintf("Event 'limit' must be between 1 and %d", maxPageLimit),
+		})
+	}
+
+	if query.Cursor != "" {
+		after, ok := decodeEventCursor(query.Cursor)
+		if !ok {
+			return options, errors.InputValidationError("Invalid list option", "Event list option 'cursor' is invalid", errors.AppErrorField{
+				Field:  "cursor",
+				Reason: "Event 'cursor' is malformed",
+			})
+		}
+		options.After = after
+	}
+
+	return options, nil
+}
+
+func (ts *TaskService) listEvents(options task.EventOptions) (*services.EventPage, error) {
+	// one extra event tells whether there is a next page
+	pageLimit := options.Limit
+	options.Limit++
+
+	events, err := ts.taskRepository.ListEvents(options)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &services.EventPage{
+		Events: events,
+	}
+	if page.Events == nil {
+		page.Events = []task.Event{}
+	}
+	if len(page.Events) > pageLimit {
+		page.Events = page.Events[:pageLimit]
+		nextCursor := encodeEventCursor(page.Events[pageLimit-1].Id)
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
+}
@@ -0,0 +1,107 @@
+package task
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/Arup3201/gotasks/internal/storages"
+)
+
+const maxBatchOperations = 100
+
+// errBatchFailed rolls back an atomic batch, the failure itself is in the
+// results.
+var errBatchFailed = stderrors.New("batch operation failed")
+
+// RunBatch applies the operations in order and returns a result for each of
+// them. The error is only set when the batch itself is invalid or could not
+// be committed.
+func (ts *TaskService) RunBatch(ownerId string, batch services.Batch) ([]services.BatchResult, error) {
+	if len(batch.Operations) == 0 || len(batch.Operations) > maxBatchOperations {
+		return nil, errors.InputValidationError("Invalid batch", "Batch 'operations' is invalid", errors.AppErrorField{
+			Field:  "operations",
+			Reason: fmt.Sprintf("Batch must have between 1 and %d operations", maxBatchOperations),
+		})
+	}
+
+	results := make([]services.BatchResult, len(batch.Operations))
+	if !batch.Atomic {
+		for i, operation := range batch.Operations {
+			results[i] = ts.runOperation(ownerId, operation)
+		}
+		return results, nil
+	}
+
+	err := ts.taskRepository.InTransaction(func(repo storages.TaskRepository) error {
+		tx := ts.withRepository(repo)
+		for i, operation := range batch.Operations {
+			results[i] = tx.runOperation(ownerId, operation)
+			if results[i].Err != nil {
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if err == errBatchFailed {
+		for i, operation := range batch.Operations {
+			if results[i].Err == nil {
+				results[i] = services.BatchResult{Op: operation.Op, TaskId: operation.TaskId, RolledBack: true}
+			}
+		}
+		return results, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (ts *TaskService) runOperation(ownerId string, operation services.BatchOperation) services.BatchResult {
+	result := services.BatchResult{Op: operation.Op, TaskId: operation.TaskId}
+
+	switch operation.Op {
+	case services.BatchCreate:
+		if operation.TaskId != "" {
+			result.Err = errors.InputValidationError("Invalid batch operation", "Batch operation 'id' is invalid", errors.AppErrorField{
+				Field:  "id",
+				Reason: "Batch 'id' can't be set when creating a task",
+			})
+			break
+		}
+		result.Task, result.Err = ts.CreateTask(ownerId, services.CreateTaskData{
+			Title:       operation.Data.Title,
+			Description: operation.Data.Description,
+			Status:      operation.Data.Status,
+			Priority:    operation.Data.Priority,
+			DueAt:       operation.Data.DueAt,
+			ParentId:    operation.Data.ParentId,
+			Recurrence:  operation.Data.Recurrence,
+		})
+	case services.BatchUpdate, services.BatchDelete:
+		if operation.TaskId == "" {
+			result.Err = errors.InputValidationError("Invalid batch operation", "Batch operation 'id' is invalid", errors.AppErrorField{
+				Field:  "id",
+				Reason: fmt.Sprintf("Batch 'id' is required to %s a task", operation.Op),
+			})
+			break
+		}
+		if operation.Op == services.BatchUpdate {
+			result.Task, result.Err = ts.UpdateTask(ownerId, operation.TaskId, operation.Version, operation.Data)
+		} else {
+			_, result.Err = ts.DeleteTask(ownerId, operation.TaskId, operation.Version)
+		}
+	default:
+		result.Err = errors.InputValidationError("Invalid batch operation", "Batch operation 'op' is invalid", errors.AppErrorField{
+			Field:  "op",
+			Reason: "Batch 'op' can only be 'create', 'update' or 'delete'",
+		})
+	}
+
+	if result.Task != nil {
+		result.TaskId = result.Task.Id
+	}
+	return result
+}
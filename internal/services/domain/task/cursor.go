@@ -0,0 +1,96 @@
+package task
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+)
+
+type cursorToken struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	Id     string `json:"id"`
+}
+
+func encodeCursor(sortBy string, last *task.Task) string {
+	token, _ := json.Marshal(cursorToken{
+		SortBy: sortBy,
+		Value:  last.SortValue(sortBy),
+		Id:     last.Id,
+	})
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// decodeCursor returns nil when the cursor is malformed or was issued for a
+// different sort field.
+func decodeCursor(sortBy, cursor string) *task.Cursor {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil || token.SortBy != sortBy || token.Id == "" {
+		return nil
+	}
+
+	return &task.Cursor{
+		Value: token.Value,
+		Id:    token.Id,
+	}
+}
+
+// Search results are ordered by rank, so their cursor is only the offset of
+// the next page.
+type searchCursorToken struct {
+	Offset int `json:"o"`
+}
+
+func encodeSearchCursor(offset int) string {
+	token, _ := json.Marshal(searchCursorToken{
+		Offset: offset,
+	})
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func decodeSearchCursor(cursor string) (int, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+
+	var token searchCursorToken
+	if err := json.Unmarshal(raw, &token); err != nil || token.Offset < 0 {
+		return 0, false
+	}
+
+	return token.Offset, true
+}
+
+// Events and webhook deliveries are ordered by ID, so their cursor is the ID
+// of the last event or delivery of the page.
+type eventCursorToken struct {
+	Id int64 `json:"e"`
+}
+
+func encodeEventCursor(id int64) string {
+	token, _ := json.Marshal(eventCursorToken{
+		Id: id,
+	})
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+func decodeEventCursor(cursor string) (int64, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+
+	var token eventCursorToken
+	if err := json.Unmarshal(raw, &token); err != nil || token.Id <= 0 {
+		return 0, false
+	}
+
+	return token.Id, true
+}
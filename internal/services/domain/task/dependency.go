@@ -0,0 +1,211 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+)
+
+func dependencyError(reason string) error {
+	return errors.InputValidationError("Invalid task 'blocker_id'", "Task 'blocker_id' value is invalid", errors.AppErrorField{
+		Field:  "blocker_id",
+		Reason: reason,
+	})
+}
+
+// AddDependency keeps the task from being done before the blocker is done,
+// and returns the blockers of the task.
+func (ts *TaskService) AddDependency(ownerId, taskId, blockerId string) (*services.TaskList, error) {
+	if _, err := ts.taskRepository.Get(ownerId, taskId); err != nil {
+		return nil, err
+	}
+	if blockerId == taskId {
+		return nil, dependencyError("Task can't block itself")
+	}
+	_, err := ts.taskRepository.Get(ownerId, blockerId)
+	if isNotFound(err) {
+		return nil, dependencyError(fmt.Sprintf("Task %s not found", blockerId))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := ts.taskRepository.ListDependencies(ownerId)
+	if err != nil {
+		return nil, err
+	}
+	if waitsOn(dependencies, blockerId, taskId) {
+		return nil, dependencyError(fmt.Sprintf("Task %s already waits on this task, directly or through other tasks", blockerId))
+	}
+
+	if err := ts.taskRepository.AddDependency(ownerId, taskId, blockerId); err != nil {
+		return nil, err
+	}
+	return ts.GetBlockers(ownerId, taskId)
+}
+
+// waitsOn reports whether the task can't be done before the other task,
+// following the blockers of its blockers.
+func waitsOn(dependencies []task.Dependency, taskId, otherId string) bool {
+	blockers := map[string][]string{}
+	for _, dependency := range dependencies {
+		blockers[dependency.TaskId] = append(blockers[dependency.TaskId], dependency.BlockerId)
+	}
+
+	seen := map[string]bool{taskId: true}
+	next := []string{taskId}
+	for len(next) > 0 {
+		id := next[0]
+		next = next[1:]
+		for _, blockerId := range blockers[id] {
+			if blockerId == otherId {
+				return true
+			}
+			if !seen[blockerId] {
+				seen[blockerId] = true
+				next = append(next, blockerId)
+			}
+		}
+	}
+	return false
+}
+
+// RemoveDependency lets the task be done regardless of the blocker, and
+// returns the blockers that are left.
+func (ts *TaskService) RemoveDependency(ownerId, taskId, blockerId string) (*services.TaskList, error) {
+	if _, err := ts.taskRepository.Get(ownerId, taskId); err != nil {
+		return nil, err
+	}
+	if err := ts.taskRepository.RemoveDependency(ownerId, taskId, blockerId); err != nil {
+		return nil, err
+	}
+	return ts.GetBlockers(ownerId, taskId)
+}
+
+func (ts *TaskService) GetBlockers(ownerId, taskId string) (*services.TaskList, error) {
+	if _, err := ts.taskRepository.Get(ownerId, taskId); err != nil {
+		return nil, err
+	}
+	tasks, err := ts.taskRepository.Blockers(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	return taskList(tasks), nil
+}
+
+// GetBlocking returns the tasks that can't be done before the task is done.
+func (ts *TaskService) GetBlocking(ownerId, taskId string) (*services.TaskList, error) {
+	if _, err := ts.taskRepository.Get(ownerId, taskId); err != nil {
+		return nil, err
+	}
+	tasks, err := ts.taskRepository.Blocking(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	return taskList(tasks), nil
+}
+
+func taskList(tasks []task.Task) *services.TaskList {
+	if tasks == nil {
+		tasks = []task.Task{}
+	}
+	return &services.TaskList{Tasks: tasks}
+}
+
+// checkBlockers fails when the task has blockers that are not done, the
+// tasks being completed along with it don't count.
+func (ts *TaskService) checkBlockers(ownerId, taskId string, completing []string) error {
+	blockers, err := ts.taskRepository.Blockers(ownerId, taskId)
+	if err != nil {
+		return err
+	}
+
+	fields := []errors.AppErrorField{}
+	for _, blocker := range blockers {
+		if blocker.Status != task.StatusDone && !slices.Contains(completing, blocker.Id) {
+			fields = append(fields, errors.AppErrorField{
+				Field:  "blocked_by",
+				Reason: fmt.Sprintf("Task %s is blocked by %s '%s' that is not done", taskId, blocker.Id, blocker.Title),
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return errors.InputValidationError("Invalid task 'status'", "Task has open blockers", fields...)
+	}
+	return nil
+}
+
+// GetPlan orders the open tasks so that each of them comes after its open
+// blockers, the tasks that are free at the same time stay in creation order.
+func (ts *TaskService) GetPlan(ownerId string) (*services.TaskPlan, error) {
+	open := false
+	tasks, err := ts.taskRepository.List(ownerId, task.ListOptions{SortBy: task.SortByCreatedAt, IsCompleted: &open})
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := ts.taskRepository.ListDependencies(ownerId)
+	if err != nil {
+		return nil, err
+	}
+
+	index := map[string]int{}
+	for i, t := range tasks {
+		index[t.Id] = i
+	}
+	blockedBy := make([][]string, len(tasks))
+	blocking := make([][]int, len(tasks))
+	waiting := make([]int, len(tasks))
+	for _, dependency := range dependencies {
+		i, isOpen := index[dependency.TaskId]
+		j, blockerOpen := index[dependency.BlockerId]
+		if !isOpen || !blockerOpen {
+			continue
+		}
+		blockedBy[i] = append(blockedBy[i], dependency.BlockerId)
+		blocking[j] = append(blocking[j], i)
+		waiting[i]++
+	}
+
+	// ready holds the indexes of the tasks without open blockers left, the
+	// earliest created first
+	ready := []int{}
+	for i := range tasks {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	plan := &services.TaskPlan{Tasks: []services.PlannedTask{}}
+	planned := make([]bool, len(tasks))
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		planned[i] = true
+		plan.Tasks = append(plan.Tasks, plannedTask(tasks[i], blockedBy[i]))
+		for _, j := range blocking[i] {
+			waiting[j]--
+			if waiting[j] == 0 {
+				at, _ := slices.BinarySearch(ready, j)
+				ready = slices.Insert(ready, at, j)
+			}
+		}
+	}
+
+	// a cycle can only come back with a restored task, its tasks go last
+	for i := range tasks {
+		if !planned[i] {
+			plan.Tasks = append(plan.Tasks, plannedTask(tasks[i], blockedBy[i]))
+		}
+	}
+
+	return plan, nil
+}
+
+func plannedTask(t task.Task, blockedBy []string) services.PlannedTask {
+	if blockedBy == nil {
+		blockedBy = []string{}
+	}
+	return services.PlannedTask{Task: t, BlockedBy: blockedBy}
+}
@@ -0,0 +1,217 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/Arup3201/gotasks/internal/storages"
+)
+
+func parentError(reason string) error {
+	return errors.InputValidationError("Invalid task 'parent_id'", "Task 'parent_id' value is invalid", errors.AppErrorField{
+		Field:  "parent_id",
+		Reason: reason,
+	})
+}
+
+func isNotFound(err error) bool {
+	appError, ok := err.(*errors.AppError)
+	return ok && appError.Type == errors.NOT_FOUND
+}
+
+// validateParent checks that the task can move under the parent without
+// making a cycle or a hierarchy deeper than MaxDepth, an empty taskId is a
+// task that is not created yet.
+func (ts *TaskService) validateParent(ownerId, taskId, parentId string) error {
+	parent, err := ts.taskRepository.Get(ownerId, parentId)
+	if isNotFound(err) {
+		return parentError(fmt.Sprintf("Task %s not found", parentId))
+	}
+	if err != nil {
+		return err
+	}
+
+	// depth ends at the level of the parent, a top level task is at 1
+	depth := 1
+	for ancestor := parent; ; depth++ {
+		if ancestor.Id == taskId {
+			return parentError("Task can't be a subtask of itself or of its own subtasks")
+		}
+		if ancestor.ParentId == nil || depth > task.MaxDepth {
+			break
+		}
+		ancestor, err = ts.taskRepository.Get(ownerId, *ancestor.ParentId)
+		if isNotFound(err) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	height := 1
+	if taskId != "" {
+		subtree, err := ts.taskRepository.Subtree(ownerId, taskId)
+		if err != nil {
+			return err
+		}
+		height = buildTree(taskId, subtree).height()
+	}
+	if depth+height > task.MaxDepth {
+		return parentError(fmt.Sprintf("Task hierarchy can't be deeper than %d levels", task.MaxDepth))
+	}
+
+	return nil
+}
+
+// GetSubtasks lists the direct subtasks of a task, with the same options as
+// GetAllTasks.
+func (ts *TaskService) GetSubtasks(ownerId, taskId string, query services.ListTasksQuery) (*services.TaskPage, error) {
+	if _, err := ts.taskRepository.Get(ownerId, taskId); err != nil {
+		return nil, err
+	}
+
+	query.ParentId = &taskId
+	return ts.listTasks(ownerId, query, false)
+}
+
+// GetTaskTree returns the task with its subtasks at every level, and the
+// progress of each of them.
+func (ts *TaskService) GetTaskTree(ownerId, taskId string) (*services.TaskTree, error) {
+	subtree, err := ts.taskRepository.Subtree(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := buildTree(taskId, subtree).toTaskTree()
+	return &tree, nil
+}
+
+type node struct {
+	task     task.Task
+	subtasks []*node
+}
+
+// buildTree links the tasks of a subtree under the task with rootId, they
+// keep the order of the subtree.
+func buildTree(rootId string, subtree []task.Task) *node {
+	nodes := map[string]*node{}
+	for _, t := range subtree {
+		nodes[t.Id] = &node{task: t}
+	}
+	for _, t := range subtree {
+		if t.Id == rootId || t.ParentId == nil {
+			continue
+		}
+		if parent, ok := nodes[*t.ParentId]; ok {
+			parent.subtasks = append(parent.subtasks, nodes[t.Id])
+		}
+	}
+	return nodes[rootId]
+}
+
+func (n *node) height() int {
+	height := 0
+	for _, subtask := range n.subtasks {
+		height = max(height, subtask.height())
+	}
+	return height + 1
+}
+
+// descendants returns the subtasks at every level below the node.
+func (n *node) descendants() []task.Task {
+	var tasks []task.Task
+	for _, subtask := range n.subtasks {
+		tasks = append(tasks, subtask.task)
+		tasks = append(tasks, subtask.descendants()...)
+	}
+	return tasks
+}
+
+func (n *node) toTaskTree() services.TaskTree {
+	tree := services.TaskTree{
+		Task:     n.task,
+		Subtasks: []services.TaskTree{},
+	}
+	for _, subtask := range n.subtasks {
+		child := subtask.toTaskTree()
+		tree.Progress.Total += child.Progress.Total + 1
+		tree.Progress.Done += child.Progress.Done
+		if child.Task.Status == task.StatusDone {
+			tree.Progress.Done++
+		}
+		tree.Subtasks = append(tree.Subtasks, child)
+	}
+	return tree
+}
+
+// completeTask applies an update that moves the task to done once its
+// blockers are done, following the completion policy when some of its
+// subtasks are still open.
+func (ts *TaskService) completeTask(ownerId string, current *task.Task, update map[string]any) (*task.Task, error) {
+	if err := ts.checkBlockers(ownerId, current.Id, nil); err != nil {
+		return nil, err
+	}
+
+	subtree, err := ts.taskRepository.Subtree(ownerId, current.Id)
+	if err != nil {
+		return nil, err
+	}
+	var open []task.Task
+	for _, subtask := range buildTree(current.Id, subtree).descendants() {
+		if subtask.Status != task.StatusDone {
+			open = append(open, subtask)
+		}
+	}
+	if len(open) == 0 {
+		return ts.applyUpdate(ownerId, current.Id, &current.Version, update)
+	}
+
+	if ts.completion != task.CompletionCascade {
+		return nil, errors.InputValidationError("Invalid task 'status'", "Task has open subtasks", errors.AppErrorField{
+			Field:  "status",
+			Reason: fmt.Sprintf("Task can't be done while %d of its subtasks are open", len(open)),
+		})
+	}
+
+	completing := []string{current.Id}
+	for _, subtask := range open {
+		completing = append(completing, subtask.Id)
+	}
+	for _, subtask := range open {
+		if err := ts.checkBlockers(ownerId, subtask.Id, completing); err != nil {
+			return nil, err
+		}
+	}
+
+	var completed *task.Task
+	err = ts.taskRepository.InTransaction(func(repo storages.TaskRepository) error {
+		tx := ts.withRepository(repo)
+		for _, subtask := range open {
+			if err := validateTransition(subtask.Status, task.StatusDone); err != nil {
+				return errors.InputValidationError("Invalid task 'status'", "Task has blocked subtasks", errors.AppErrorField{
+					Field:  "status",
+					Reason: fmt.Sprintf("Subtask %s is blocked and can't be done", subtask.Id),
+				})
+			}
+			done, err := tx.applyUpdate(ownerId, subtask.Id, &subtask.Version, map[string]any{"Status": task.StatusDone})
+			if err != nil {
+				return err
+			}
+			// a recurring subtask goes on with its next occurrence, like
+			// when it is done on its own
+			if _, err := tx.nextOccurrence(ownerId, done); err != nil {
+				return err
+			}
+		}
+		completed, err = tx.applyUpdate(ownerId, current.Id, &current.Version, update)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return completed, nil
+}
@@ -1,18 +1,27 @@
 package task
 
 import (
+	"context"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Arup3201/gotasks/internal/entities/task"
 	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/storages"
 )
 
 /* Mock up of the task repository for test */
 
 type mockTaskRepository struct {
-	tasks []task.Task
+	tasks        []task.Task
+	dependencies []task.Dependency
+	events       []task.Event
+	webhooks     []task.Webhook
+	deliveries   []task.Delivery
 }
 
 func NewMockTaskRepository() *mockTaskRepository {
@@ -21,9 +30,9 @@ func NewMockTaskRepository() *mockTaskRepository {
 	}
 }
 
-func (tr *mockTaskRepository) Get(taskId string) (*task.Task, error) {
+func (tr *mockTaskRepository) Get(ownerId, taskId string) (*task.Task, error) {
 	for _, task := range tr.tasks {
-		if task.Id == taskId {
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
 			return &task, nil
 		}
 	}
@@ -31,22 +40,34 @@ func (tr *mockTaskRepository) Get(taskId string) (*task.Task, error) {
 	return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 }
 
-func (tr *mockTaskRepository) Insert(id string, title, description string) (*task.Task, error) {
+func (tr *mockTaskRepository) Insert(ownerId, id string, title, description string, details task.Details) (*task.Task, error) {
+	details = details.WithDefaults()
 	task := task.Task{
 		Id:          id,
+		OwnerId:     ownerId,
 		Title:       title,
 		Description: description,
-		IsCompleted: false,
+		Tags:        []string{},
+		Priority:    details.Priority,
+		DueAt:       details.DueAt,
+		ParentId:    details.ParentId,
+		Recurrence:  details.Recurrence,
+		SeriesId:    details.SeriesId,
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	task.SetStatus(details.Status)
 	tr.tasks = append(tr.tasks, task)
 	return &task, nil
 }
 
-func (tr *mockTaskRepository) Update(taskId string, data map[string]any) (*task.Task, error) {
+func (tr *mockTaskRepository) Update(ownerId, taskId string, version *int, data map[string]any) (*task.Task, error) {
 	for i, task := range tr.tasks {
-		if task.Id == taskId {
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
+			if version != nil && task.Version != *version {
+				return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d", taskId, task.Version))
+			}
 			t := reflect.ValueOf(&task).Elem()
 			for f, v := range data {
 				field := t.FieldByName(f)
@@ -55,8 +76,12 @@ func (tr *mockTaskRepository) Update(taskId string, data map[string]any) (*task.
 					field.SetString(value.String())
 				} else if field.Kind() == reflect.Bool {
 					field.SetBool(value.Bool())
+				} else if field.Kind() == reflect.Pointer {
+					field.Set(value)
 				}
 			}
+			task.SetStatus(task.Status)
+			task.Version++
 			task.UpdatedAt = time.Now()
 			tr.tasks[i] = task
 			return &task, nil
@@ -66,10 +91,16 @@ func (tr *mockTaskRepository) Update(taskId string, data map[string]any) (*task.
 	return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 }
 
-func (tr *mockTaskRepository) Delete(taskId string) (*string, error) {
+func (tr *mockTaskRepository) Delete(ownerId, taskId string, version *int) (*string, error) {
 	for i, task := range tr.tasks {
-		if task.Id == taskId {
-			tr.tasks = append(tr.tasks[:i], tr.tasks[i+1:]...)
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
+			if version != nil && task.Version != *version {
+				return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d", taskId, task.Version))
+			}
+			deletedAt := time.Now()
+			task.DeletedAt = &deletedAt
+			task.Version++
+			tr.tasks[i] = task
 			return &task.Id, nil
 		}
 	}
@@ -77,10 +108,316 @@ func (tr *mockTaskRepository) Delete(taskId string) (*string, error) {
 	return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 }
 
-func (tr *mockTaskRepository) List() ([]task.Task, error) {
-	return tr.tasks, nil
+func (tr *mockTaskRepository) Restore(ownerId, taskId string) (*task.Task, error) {
+	for i, task := range tr.tasks {
+		if task.Id == taskId && task.OwnerId == ownerId && task.IsDeleted() {
+			task.DeletedAt = nil
+			task.Version++
+			task.UpdatedAt = time.Now()
+			tr.tasks[i] = task
+			return &task, nil
+		}
+	}
+
+	return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found in trash", taskId))
+}
+
+func (tr *mockTaskRepository) Purge(deletedBefore time.Time) (int64, error) {
+	tasks := []task.Task{}
+	for _, task := range tr.tasks {
+		if !task.IsDeleted() || !task.DeletedAt.Before(deletedBefore) {
+			tasks = append(tasks, task)
+		}
+	}
+	purged := int64(len(tr.tasks) - len(tasks))
+	tr.tasks = tasks
+	return purged, nil
+}
+
+func (tr *mockTaskRepository) List(ownerId string, options task.ListOptions) ([]task.Task, error) {
+	tasks := []task.Task{}
+	for _, task := range tr.tasks {
+		if task.OwnerId != ownerId || task.IsDeleted() != options.Deleted {
+			continue
+		}
+		if options.IsCompleted != nil && task.IsCompleted != *options.IsCompleted {
+			continue
+		}
+		if options.ParentId != nil && (task.ParentId == nil || *task.ParentId != *options.ParentId) {
+			continue
+		}
+		if options.SeriesId != nil && (task.SeriesId == nil || *task.SeriesId != *options.SeriesId) {
+			continue
+		}
+		if len(options.Tags) > 0 && !hasTags(task.Tags, options.Tags, options.AllTags) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if options.Limit > 0 && len(tasks) > options.Limit {
+		tasks = tasks[:options.Limit]
+	}
+	return tasks, nil
+}
+
+func (tr *mockTaskRepository) Search(ownerId string, options task.SearchOptions) ([]task.SearchResult, error) {
+	results := []task.SearchResult{}
+	for _, t := range tr.tasks {
+		if t.OwnerId != ownerId || t.IsDeleted() {
+			continue
+		}
+		text := strings.ToLower(t.Title + " " + t.Description)
+		matched := true
+		for _, term := range options.Terms {
+			if !strings.Contains(text, strings.Join(term.Words, " ")) {
+				matched = false
+			}
+		}
+		if matched {
+			results = append(results, task.SearchResult{Task: t})
+		}
+	}
+	if options.Offset >= len(results) {
+		return []task.SearchResult{}, nil
+	}
+	results = results[options.Offset:]
+	if options.Limit > 0 && len(results) > options.Limit {
+		results = results[:options.Limit]
+	}
+	return results, nil
+}
+
+func (tr *mockTaskRepository) Subtree(ownerId, taskId string) ([]task.Task, error) {
+	root, err := tr.Get(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []task.Task{*root}
+	for i := 0; i < len(tasks); i++ {
+		for _, task := range tr.tasks {
+			if task.OwnerId == ownerId && !task.IsDeleted() && task.ParentId != nil && *task.ParentId == tasks[i].Id {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (tr *mockTaskRepository) SetTags(ownerId, taskId string, version *int, tags []string) (*task.Task, error) {
+	for i, task := range tr.tasks {
+		if task.Id == taskId && task.OwnerId == ownerId && !task.IsDeleted() {
+			if version != nil && task.Version != *version {
+				return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d", taskId, task.Version))
+			}
+			task.Tags = slices.Sorted(slices.Values(tags))
+			task.Version++
+			task.UpdatedAt = time.Now()
+			tr.tasks[i] = task
+			return &task, nil
+		}
+	}
+
+	return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+}
+
+func (tr *mockTaskRepository) ListTags(ownerId string) ([]task.TagCount, error) {
+	counts := map[string]int{}
+	for _, task := range tr.tasks {
+		if task.OwnerId != ownerId || task.IsDeleted() {
+			continue
+		}
+		for _, name := range task.Tags {
+			counts[name]++
+		}
+	}
+	tags := []task.TagCount{}
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		tags = append(tags, task.TagCount{Name: name, Count: counts[name]})
+	}
+	return tags, nil
+}
+
+func hasTags(tags, wanted []string, all bool) bool {
+	for _, tag := range wanted {
+		if slices.Contains(tags, tag) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+func (tr *mockTaskRepository) AddDependency(ownerId, taskId, blockerId string) error {
+	dependency := task.Dependency{TaskId: taskId, BlockerId: blockerId}
+	if !slices.Contains(tr.dependencies, dependency) {
+		tr.dependencies = append(tr.dependencies, dependency)
+	}
+	return nil
+}
+
+func (tr *mockTaskRepository) RemoveDependency(ownerId, taskId, blockerId string) error {
+	i := slices.Index(tr.dependencies, task.Dependency{TaskId: taskId, BlockerId: blockerId})
+	if i < 0 {
+		return errors.NotFoundError(fmt.Sprintf("Task with ID %s is not blocked by %s", taskId, blockerId))
+	}
+	tr.dependencies = slices.Delete(tr.dependencies, i, i+1)
+	return nil
+}
+
+func (tr *mockTaskRepository) ListDependencies(ownerId string) ([]task.Dependency, error) {
+	dependencies := []task.Dependency{}
+	for _, dependency := range tr.dependencies {
+		_, taskErr := tr.Get(ownerId, dependency.TaskId)
+		_, blockerErr := tr.Get(ownerId, dependency.BlockerId)
+		if taskErr == nil && blockerErr == nil {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies, nil
+}
+
+func (tr *mockTaskRepository) Blockers(ownerId, taskId string) ([]task.Task, error) {
+	tasks := []task.Task{}
+	for _, dependency := range tr.dependencies {
+		if task, err := tr.Get(ownerId, dependency.BlockerId); err == nil && dependency.TaskId == taskId {
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks, nil
+}
+
+func (tr *mockTaskRepository) Blocking(ownerId, taskId string) ([]task.Task, error) {
+	tasks := []task.Task{}
+	for _, dependency := range tr.dependencies {
+		if task, err := tr.Get(ownerId, dependency.TaskId); err == nil && dependency.BlockerId == taskId {
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks, nil
+}
+
+func (tr *mockTaskRepository) AddEvent(event task.Event) error {
+	event.Id = int64(len(tr.events) + 1)
+	tr.events = append(tr.events, event)
+	return nil
+}
+
+func (tr *mockTaskRepository) ListEvents(options task.EventOptions) ([]task.Event, error) {
+	events := []task.Event{}
+	for _, event := range tr.events {
+		if options.Matches(event) && (options.Limit == 0 || len(events) < options.Limit) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (tr *mockTaskRepository) AddWebhook(webhook task.Webhook) error {
+	tr.webhooks = append(tr.webhooks, webhook)
+	return nil
+}
+
+func (tr *mockTaskRepository) ListWebhooks(ownerId string) ([]task.Webhook, error) {
+	webhooks := []task.Webhook{}
+	for _, webhook := range tr.webhooks {
+		if webhook.OwnerId == ownerId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (tr *mockTaskRepository) GetWebhook(ownerId, webhookId string) (*task.Webhook, error) {
+	for _, webhook := range tr.webhooks {
+		if webhook.Id == webhookId && webhook.OwnerId == ownerId {
+			return &webhook, nil
+		}
+	}
+	return nil, errors.NotFoundError(fmt.Sprintf("Webhook with ID %s not found", webhookId))
+}
+
+func (tr *mockTaskRepository) DeleteWebhook(ownerId, webhookId string) error {
+	if _, err := tr.GetWebhook(ownerId, webhookId); err != nil {
+		return err
+	}
+	tr.webhooks = slices.DeleteFunc(tr.webhooks, func(webhook task.Webhook) bool { return webhook.Id == webhookId })
+	tr.deliveries = slices.DeleteFunc(tr.deliveries, func(delivery task.Delivery) bool { return delivery.WebhookId == webhookId })
+	return nil
+}
+
+func (tr *mockTaskRepository) AddDelivery(delivery task.Delivery) error {
+	delivery.Id = 1
+	if len(tr.deliveries) > 0 {
+		delivery.Id = tr.deliveries[len(tr.deliveries)-1].Id + 1
+	}
+	tr.deliveries = append(tr.deliveries, delivery)
+	return nil
+}
+
+func (tr *mockTaskRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]task.Delivery, error) {
+	deliveries := []task.Delivery{}
+	for i, delivery := range tr.deliveries {
+		if delivery.Status == task.DeliveryPending && !delivery.NextAttemptAt.After(now) && len(deliveries) < limit {
+			tr.deliveries[i].NextAttemptAt = now.Add(lease)
+			deliveries = append(deliveries, tr.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func (tr *mockTaskRepository) UpdateDelivery(delivery task.Delivery) error {
+	for i := range tr.deliveries {
+		if tr.deliveries[i].Id == delivery.Id {
+			tr.deliveries[i] = delivery
+		}
+	}
+	return nil
+}
+
+func (tr *mockTaskRepository) ListDeliveries(ownerId, webhookId string, options task.DeliveryOptions) ([]task.Delivery, error) {
+	deliveries := []task.Delivery{}
+	for _, delivery := range slices.Backward(tr.deliveries) {
+		if delivery.OwnerId == ownerId && delivery.WebhookId == webhookId && options.Matches(delivery) && (options.Limit == 0 || len(deliveries) < options.Limit) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// InTransaction puts the tasks back when fn fails.
+func (tr *mockTaskRepository) InTransaction(fn func(repo storages.TaskRepository) error) error {
+	tasks, dependencies, events := slices.Clone(tr.tasks), slices.Clone(tr.dependencies), slices.Clone(tr.events)
+	webhooks, deliveries := slices.Clone(tr.webhooks), slices.Clone(tr.deliveries)
+	if err := fn(tr); err != nil {
+		tr.tasks, tr.dependencies, tr.events = tasks, dependencies, events
+		tr.webhooks, tr.deliveries = webhooks, deliveries
+		return err
+	}
+	return nil
 }
 
 func (tr *mockTaskRepository) Close() error {
 	return nil
 }
+
+// mockNotifyingRepository is a task repository shared with other replicas,
+// ListenChanges tells about the notifications it holds and then about lost
+// ones when reset is set, or fails with listenError.
+type mockNotifyingRepository struct {
+	*mockTaskRepository
+	notifications []task.Notification
+	reset         bool
+	listenError   error
+}
+
+func (tr *mockNotifyingRepository) ListenChanges(ctx context.Context, onChange func(task.Notification), onReset func()) error {
+	if tr.listenError != nil {
+		return tr.listenError
+	}
+	for _, notification := range tr.notifications {
+		onChange(notification)
+	}
+	if tr.reset {
+		onReset()
+	}
+	return nil
+}
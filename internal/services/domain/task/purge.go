@@ -0,0 +1,29 @@
+package task
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunPurge purges the trash right away and then every interval until ctx is
+// done, a failed run is logged and retried on the next tick.
+func (ts *TaskService) RunPurge(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := ts.PurgeTasks(retention)
+		if err != nil {
+			log.Printf("purge error: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d tasks deleted more than %s ago", purged, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package task
+
+import (
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/Arup3201/gotasks/internal/storages"
+	"github.com/google/uuid"
+)
+
+// parseRecurrence validates the rule of a task due at dueAt, and returns it
+// the way it is stored.
+func parseRecurrence(rule string, dueAt *time.Time) (*string, error) {
+	recurrence, err := task.ParseRecurrence(rule)
+	if err != nil {
+		return nil, errors.InputValidationError("Invalid task 'recurrence'", "Task 'recurrence' value is invalid", errors.AppErrorField{
+			Field:  "recurrence",
+			Reason: err.Error(),
+		})
+	}
+	if dueAt == nil {
+		return nil, errors.InputValidationError("Invalid task 'due_at'", "Task 'due_at' is missing", errors.AppErrorField{
+			Field:  "due_at",
+			Reason: "Task 'due_at' is required for a recurring task, it is the start of the series",
+		})
+	}
+
+	normalized := recurrence.String()
+	return &normalized, nil
+}
+
+// completeOccurrence completes a recurring task and creates its next
+// occurrence together.
+func (ts *TaskService) completeOccurrence(ownerId string, current *task.Task, update map[string]any) (*task.Task, error) {
+	var completed *task.Task
+	err := ts.taskRepository.InTransaction(func(repo storages.TaskRepository) error {
+		tx := ts.withRepository(repo)
+
+		var err error
+		completed, err = tx.completeTask(ownerId, current, update)
+		if err != nil {
+			return err
+		}
+		_, err = tx.nextOccurrence(ownerId, completed)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return completed, nil
+}
+
+// nextOccurrence creates the occurrence that follows the task, the ones that
+// are already past are skipped and count against COUNT. It returns nil when
+// the series ended or the next occurrence already exists.
+func (ts *TaskService) nextOccurrence(ownerId string, t *task.Task) (*task.Task, error) {
+	if t.Recurrence == nil || t.DueAt == nil || t.SeriesId == nil {
+		return nil, nil
+	}
+	rule, err := task.ParseRecurrence(*t.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	// a reopened occurrence that is done again does not start a second one
+	occurrences, err := ts.taskRepository.List(ownerId, task.ListOptions{SortBy: task.SortByCreatedAt, SeriesId: t.SeriesId})
+	if err != nil {
+		return nil, err
+	}
+	for _, occurrence := range occurrences {
+		if occurrence.Id != t.Id && occurrence.DueAt != nil && occurrence.DueAt.After(*t.DueAt) {
+			return nil, nil
+		}
+	}
+
+	dueAt, now := t.DueAt, time.Now()
+	for {
+		if rule.Count == 1 {
+			return nil, nil
+		}
+		dueAt = rule.Next(*dueAt)
+		if dueAt == nil {
+			return nil, nil
+		}
+		if rule.Count > 1 {
+			rule.Count--
+		}
+		if dueAt.After(now) {
+			break
+		}
+	}
+
+	taskId, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	recurrence := rule.String()
+	next, err := ts.taskRepository.Insert(ownerId, taskId.String(), t.Title, t.Description, task.Details{
+		Priority:   t.Priority,
+		DueAt:      dueAt,
+		ParentId:   t.ParentId,
+		Recurrence: &recurrence,
+		SeriesId:   t.SeriesId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(t.Tags) > 0 {
+		return ts.taskRepository.SetTags(ownerId, next.Id, nil, t.Tags)
+	}
+
+	return next, nil
+}
+
+// seriesOf returns the live occurrences of the series the task is part of.
+func (ts *TaskService) seriesOf(ownerId, taskId string) ([]task.Task, error) {
+	current, err := ts.taskRepository.Get(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	if current.SeriesId == nil {
+		return nil, errors.InputValidationError("Invalid scope", "Task is not recurring", errors.AppErrorField{
+			Field:  "scope",
+			Reason: "Task is not part of a series, only 'this' task can change",
+		})
+	}
+
+	return ts.taskRepository.List(ownerId, task.ListOptions{SortBy: task.SortByCreatedAt, SeriesId: current.SeriesId})
+}
+
+// UpdateSeries applies the update to every occurrence of the series the task
+// is part of. The version only conditions the task itself, and the fields
+// of a single occurrence can't change.
+func (ts *TaskService) UpdateSeries(ownerId, taskId string, version *int, data services.UpdateTaskData) (*task.Task, error) {
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"status", data.Status != nil},
+		{"is_completed", data.IsCompleted != nil},
+		{"due_at", data.DueAt != nil || data.ClearDueAt},
+		{"parent_id", data.ParentId != nil},
+	} {
+		if field.set {
+			return nil, errors.InputValidationError("Invalid series update", "Series update is invalid", errors.AppErrorField{
+				Field:  field.name,
+				Reason: "Task '" + field.name + "' can only change for one occurrence",
+			})
+		}
+	}
+
+	var updated *task.Task
+	err := ts.taskRepository.InTransaction(func(repo storages.TaskRepository) error {
+		tx := ts.withRepository(repo)
+		occurrences, err := tx.seriesOf(ownerId, taskId)
+		if err != nil {
+			return err
+		}
+		for _, occurrence := range occurrences {
+			var occurrenceVersion *int
+			if occurrence.Id == taskId {
+				occurrenceVersion = version
+			}
+			result, err := tx.UpdateTask(ownerId, occurrence.Id, occurrenceVersion, data)
+			if err != nil {
+				return err
+			}
+			if occurrence.Id == taskId {
+				updated = result
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteSeries moves the open occurrences of the series the task is part of
+// to the trash, the completed ones stay as the record of the work done. The
+// version only conditions the task itself.
+func (ts *TaskService) DeleteSeries(ownerId, taskId string, version *int) (*string, error) {
+	err := ts.taskRepository.InTransaction(func(repo storages.TaskRepository) error {
+		occurrences, err := ts.withRepository(repo).seriesOf(ownerId, taskId)
+		if err != nil {
+			return err
+		}
+		for _, occurrence := range occurrences {
+			if occurrence.IsCompleted {
+				continue
+			}
+			var occurrenceVersion *int
+			if occurrence.Id == taskId {
+				occurrenceVersion = version
+			}
+			if _, err := repo.Delete(ownerId, occurrence.Id, occurrenceVersion); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &taskId, nil
+}
@@ -0,0 +1,69 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+)
+
+// statusTransitions lists the statuses a task can move to from each status,
+// a blocked task has to be unblocked before it can be done.
+var statusTransitions = map[string][]string{
+	task.StatusTodo:       {task.StatusInProgress, task.StatusBlocked, task.StatusDone},
+	task.StatusInProgress: {task.StatusTodo, task.StatusBlocked, task.StatusDone},
+	task.StatusBlocked:    {task.StatusTodo, task.StatusInProgress},
+	task.StatusDone:       {task.StatusTodo, task.StatusInProgress},
+}
+
+// maxStatusAttempts bounds how often a status change is retried when the
+// task changes between validating and applying it.
+const maxStatusAttempts = 3
+
+func validateStatus(status string) error {
+	if !task.IsStatus(status) {
+		return errors.InputValidationError("Invalid task 'status'", "Task 'status' value is invalid", errors.AppErrorField{
+			Field:  "status",
+			Reason: fmt.Sprintf("Task 'status' must be one of '%s'", strings.Join(task.Statuses, "', '")),
+		})
+	}
+	return nil
+}
+
+func validatePriority(priority string) error {
+	if !task.IsPriority(priority) {
+		return errors.InputValidationError("Invalid task 'priority'", "Task 'priority' value is invalid", errors.AppErrorField{
+			Field:  "priority",
+			Reason: fmt.Sprintf("Task 'priority' must be one of '%s'", strings.Join(task.Priorities, "', '")),
+		})
+	}
+	return nil
+}
+
+func validateTransition(from, to string) error {
+	if from == to || slices.Contains(statusTransitions[from], to) {
+		return nil
+	}
+	return errors.InputValidationError("Invalid task 'status'", "Task 'status' can't change this way", errors.AppErrorField{
+		Field:  "status",
+		Reason: fmt.Sprintf("Task can't move from '%s' to '%s'", from, to),
+	})
+}
+
+// nextStatus is the status an update moves the task to, is_completed only
+// decides it when no status is given.
+func nextStatus(current string, data services.UpdateTaskData) string {
+	switch {
+	case data.Status != nil:
+		return *data.Status
+	case *data.IsCompleted:
+		return task.StatusDone
+	case current == task.StatusDone:
+		return task.StatusTodo
+	default:
+		return current
+	}
+}
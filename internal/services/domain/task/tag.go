@@ -0,0 +1,33 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+)
+
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// normalizeTags normalizes every tag name and drops the duplicates that
+// only differ in case or spacing, field names the input in the error.
+func normalizeTags(field string, names []string) ([]string, error) {
+	tags := []string{}
+	for _, name := range names {
+		tag, ok := task.NormalizeTag(name)
+		if !ok {
+			return nil, errors.InputValidationError(fmt.Sprintf("Invalid task '%s'", field), fmt.Sprintf("Task '%s' value is invalid", field), errors.AppErrorField{
+				Field:  field,
+				Reason: fmt.Sprintf("Tag '%s' must have 1 to %d letters, digits, '-' or '_'", name, task.MaxTagLength),
+			})
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
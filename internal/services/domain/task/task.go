@@ -1,46 +1,174 @@
 package task
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Arup3201/gotasks/internal/entities/task"
 	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/events"
 	"github.com/Arup3201/gotasks/internal/services"
 	"github.com/Arup3201/gotasks/internal/storages"
+	"github.com/Arup3201/gotasks/internal/utils"
 	"github.com/google/uuid"
 )
 
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
 type TaskService struct {
 	taskRepository storages.TaskRepository
+	// completion is the policy to complete a task with open subtasks, they
+	// block it unless it is CompletionCascade.
+	completion string
+	// bus gets the changes of the tasks once they are written.
+	bus *events.Bus
+	// notifier is set for a storage that tells about the writes of every
+	// replica, the bus gets the changes from it instead of the writes.
+	notifier storages.ChangeNotifier
+	// webhookClient sends the deliveries of the webhooks, a failed one is
+	// tried webhookAttempts times in all, waiting webhookBackoff after the
+	// first failure.
+	webhookClient   *http.Client
+	webhookAttempts int
+	webhookBackoff  time.Duration
+	// webhookPrivate allows webhooks to loopback, private and link-local
+	// addresses, which are refused by default.
+	webhookPrivate bool
 }
 
 func NewTaskService(repo storages.TaskRepository) (*TaskService, error) {
+	bus := events.NewBus(utils.Config.EventReplaySize)
+	audited := auditedRepository{TaskRepository: repo, bus: bus}
+	notifier, ok := repo.(storages.ChangeNotifier)
+	if ok {
+		audited.bus = nil
+	}
 	return &TaskService{
-		taskRepository: repo,
+		taskRepository:  audited,
+		completion:      utils.Config.SubtaskCompletion,
+		bus:             bus,
+		notifier:        notifier,
+		webhookClient:   newWebhookClient(utils.Config.WebhookTimeout, utils.Config.WebhookAllowPrivate),
+		webhookAttempts: utils.Config.WebhookMaxAttempts,
+		webhookBackoff:  utils.Config.WebhookBackoff,
+		webhookPrivate:  utils.Config.WebhookAllowPrivate,
 	}, nil
 }
 
-func (ts *TaskService) CreateTask(title, description string) (*task.Task, error) {
-	if strings.TrimSpace(title) == "" {
+// WithActor returns the service with the writes recorded in the change
+// history as made by actorId, the caller of the controllers.
+func (ts *TaskService) WithActor(actorId string) services.ServiceHandler {
+	actor := *ts
+	if audited, ok := ts.taskRepository.(auditedRepository); ok {
+		audited.actorId = actorId
+		actor.taskRepository = audited
+	}
+	return &actor
+}
+
+// withRepository returns the service working on repo, the repository of a
+// transaction.
+func (ts *TaskService) withRepository(repo storages.TaskRepository) *TaskService {
+	tx := *ts
+	tx.taskRepository = repo
+	return &tx
+}
+
+// SubscribeTaskChanges streams the changes of the tasks of the owner,
+// resuming after the change with lastId when it is not 0.
+func (ts *TaskService) SubscribeTaskChanges(ownerId string, lastId int64) *events.Subscription {
+	return ts.bus.Subscribe(ownerId, lastId)
+}
+
+// FollowChanges starts publishing the task writes of every replica told by
+// the storage until ctx is done. The writes of this replica are published as
+// they commit when the storage doesn't tell them. It fails when the storage
+// can't tell them, the subscribers would get no change at all.
+func (ts *TaskService) FollowChanges(ctx context.Context) error {
+	if ts.notifier == nil {
+		return nil
+	}
+	return ts.notifier.ListenChanges(ctx, ts.publishNotification, ts.bus.Reset)
+}
+
+func (ts *TaskService) publishNotification(notification task.Notification) {
+	change := events.Change{
+		Type:    changeType(notification.Action),
+		OwnerId: notification.OwnerId,
+		TaskId:  notification.TaskId,
+	}
+	if notification.Action != task.ActionDelete {
+		t, err := ts.taskRepository.Get(notification.OwnerId, notification.TaskId)
+		if err != nil {
+			// the task was deleted in the meantime, its delete comes next
+			return
+		}
+		change.Task = t
+	}
+	ts.bus.Publish(change)
+}
+
+func (ts *TaskService) CreateTask(ownerId string, data services.CreateTaskData) (*task.Task, error) {
+	if data.Title == nil || strings.TrimSpace(*data.Title) == "" {
 		return nil, errors.InputValidationError("Invalid task value", "Task property 'title' is invalid", errors.AppErrorField{
 			Field:  "title",
 			Reason: "Task 'title' can't be empty",
 		})
 	}
 
-	if strings.TrimSpace(description) == "" {
+	if data.Description == nil || strings.TrimSpace(*data.Description) == "" {
 		return nil, errors.InputValidationError("Invalid task value", "Task property 'description' is invalid", errors.AppErrorField{
 			Field:  "description",
 			Reason: "Task 'description' can't be empty",
 		})
 	}
 
+	// an empty status or priority takes the default, like a missing one
+	details := task.Details{
+		DueAt: data.DueAt,
+	}
+	if data.Status != nil && *data.Status != "" {
+		if err := validateStatus(*data.Status); err != nil {
+			return nil, err
+		}
+		details.Status = *data.Status
+	}
+	if data.Priority != nil && *data.Priority != "" {
+		if err := validatePriority(*data.Priority); err != nil {
+			return nil, err
+		}
+		details.Priority = *data.Priority
+	}
+	if data.ParentId != nil && *data.ParentId != "" {
+		if err := ts.validateParent(ownerId, "", *data.ParentId); err != nil {
+			return nil, err
+		}
+		details.ParentId = data.ParentId
+	}
+	if data.Recurrence != nil && *data.Recurrence != "" {
+		recurrence, err := parseRecurrence(*data.Recurrence, data.DueAt)
+		if err != nil {
+			return nil, err
+		}
+		details.Recurrence = recurrence
+	}
+
 	taskId, err := uuid.NewUUID()
 	if err != nil {
 		return nil, err
 	}
-	task, err := ts.taskRepository.Insert(taskId.String(), title, description)
+	if details.Recurrence != nil {
+		// the first occurrence names the series
+		seriesId := taskId.String()
+		details.SeriesId = &seriesId
+	}
+	task, err := ts.taskRepository.Insert(ownerId, taskId.String(), *data.Title, *data.Description, details)
 	if err != nil {
 		return nil, err
 	}
@@ -48,8 +176,8 @@ func (ts *TaskService) CreateTask(title, description string) (*task.Task, error)
 	return task, nil
 }
 
-func (ts *TaskService) GetTask(taskId string) (*task.Task, error) {
-	task, err := ts.taskRepository.Get(taskId)
+func (ts *TaskService) GetTask(ownerId, taskId string) (*task.Task, error) {
+	task, err := ts.taskRepository.Get(ownerId, taskId)
 	if err != nil {
 		return nil, err
 	}
@@ -57,16 +185,111 @@ func (ts *TaskService) GetTask(taskId string) (*task.Task, error) {
 	return task, nil
 }
 
-func (ts *TaskService) GetAllTasks() ([]task.Task, error) {
-	tasks, err := ts.taskRepository.List()
+func (ts *TaskService) GetAllTasks(ownerId string, query services.ListTasksQuery) (*services.TaskPage, error) {
+	return ts.listTasks(ownerId, query, false)
+}
+
+// GetTrash lists the deleted tasks that were not purged yet, with the same
+// options as GetAllTasks.
+func (ts *TaskService) GetTrash(ownerId string, query services.ListTasksQuery) (*services.TaskPage, error) {
+	return ts.listTasks(ownerId, query, true)
+}
+
+func (ts *TaskService) listTasks(ownerId string, query services.ListTasksQuery, deleted bool) (*services.TaskPage, error) {
+	options := task.ListOptions{
+		SortBy:       query.SortBy,
+		Limit:        query.Limit,
+		IsCompleted:  query.IsCompleted,
+		CreatedAfter: query.CreatedAfter,
+		ParentId:     query.ParentId,
+		Deleted:      deleted,
+	}
+
+	tags, err := normalizeTags("tag", query.Tags)
+	if err != nil {
+		return nil, err
+	}
+	options.Tags = tags
+
+	switch strings.ToLower(query.TagMatch) {
+	case "", TagMatchAny:
+		options.AllTags = false
+	case TagMatchAll:
+		options.AllTags = true
+	default:
+		return nil, errors.InputValidationError("Invalid list option", "Task list option 'tag_match' is invalid", errors.AppErrorField{
+			Field:  "tag_match",
+			Reason: "Task 'tag_match' can only be 'any' or 'all'",
+		})
+	}
+
+	if options.SortBy == "" {
+		options.SortBy = task.SortByCreatedAt
+	}
+	if options.SortBy != task.SortByCreatedAt && options.SortBy != task.SortByUpdatedAt && options.SortBy != task.SortByTitle {
+		return nil, errors.InputValidationError("Invalid list option", "Task list option 'sort' is invalid", errors.AppErrorField{
+			Field:  "sort",
+			Reason: "Tasks can only be sorted by 'created_at', 'updated_at' or 'title'",
+		})
+	}
+
+	switch strings.ToLower(query.Order) {
+	case "", "asc":
+		options.Descending = false
+	case "desc":
+		options.Descending = true
+	default:
+		return nil, errors.InputValidationError("Invalid list option", "Task list option 'order' is invalid", errors.AppErrorField{
+			Field:  "order",
+			Reason: "Task order can only be 'asc' or 'desc'",
+		})
+	}
+
+	if options.Limit == 0 {
+		options.Limit = defaultPageLimit
+	}
+	if options.Limit < 0 || options.Limit > maxPageLimit {
+		return nil, errors.InputValidationError("Invalid list option", "Task list option 'limit' is invalid", errors.AppErrorField{
+			Field:  "limit",
+			Reason: fmt.Sprintf("Task 'limit' must be between 1 and %d", maxPageLimit),
+		})
+	}
+
+	if query.Cursor != "" {
+		options.After = decodeCursor(options.SortBy, query.Cursor)
+		if options.After == nil {
+			return nil, errors.InputValidationError("Invalid list option", "Task list option 'cursor' is invalid", errors.AppErrorField{
+				Field:  "cursor",
+				Reason: "Task 'cursor' is malformed or does not match the sort field",
+			})
+		}
+	}
+
+	// one extra task tells whether there is a next page
+	pageLimit := options.Limit
+	options.Limit++
+
+	tasks, err := ts.taskRepository.List(ownerId, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return tasks, nil
+	page := &services.TaskPage{
+		Tasks: tasks,
+	}
+	if page.Tasks == nil {
+		page.Tasks = []task.Task{}
+	}
+	if len(page.Tasks) > pageLimit {
+		page.Tasks = page.Tasks[:pageLimit]
+		nextCursor := encodeCursor(options.SortBy, &page.Tasks[pageLimit-1])
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
 }
 
-func (ts *TaskService) UpdateTask(taskId string, data services.UpdateTaskData) (*task.Task, error) {
+func (ts *TaskService) UpdateTask(ownerId, taskId string, version *int, data services.UpdateTaskData) (*task.Task, error) {
 	update := map[string]any{}
 
 	if data.Title != nil {
@@ -91,11 +314,136 @@ func (ts *TaskService) UpdateTask(taskId string, data services.UpdateTaskData) (
 		update["Description"] = *data.Description
 	}
 
-	if data.IsCompleted != nil {
-		update["IsCompleted"] = *data.IsCompleted
+	if data.Priority != nil {
+		if err := validatePriority(*data.Priority); err != nil {
+			return nil, err
+		}
+		update["Priority"] = *data.Priority
+	}
+
+	if data.DueAt != nil {
+		update["DueAt"] = data.DueAt
+	}
+
+	if data.ClearDueAt {
+		if err := ts.validateClearDueAt(ownerId, taskId, data); err != nil {
+			return nil, err
+		}
+		update["DueAt"] = (*time.Time)(nil)
+	}
+
+	if data.ParentId != nil {
+		if *data.ParentId == "" {
+			update["ParentId"] = (*string)(nil)
+		} else {
+			if err := ts.validateParent(ownerId, taskId, *data.ParentId); err != nil {
+				return nil, err
+			}
+			update["ParentId"] = data.ParentId
+		}
+	}
+
+	if data.Recurrence != nil {
+		if *data.Recurrence == "" {
+			update["Recurrence"] = (*string)(nil)
+		} else {
+			current, err := ts.taskRepository.Get(ownerId, taskId)
+			if err != nil {
+				return nil, err
+			}
+			dueAt := data.DueAt
+			if dueAt == nil && !data.ClearDueAt {
+				dueAt = current.DueAt
+			}
+			recurrence, err := parseRecurrence(*data.Recurrence, dueAt)
+			if err != nil {
+				return nil, err
+			}
+			update["Recurrence"] = recurrence
+			if current.SeriesId == nil {
+				update["SeriesId"] = &current.Id
+			}
+		}
 	}
 
-	task, err := ts.taskRepository.Update(taskId, update)
+	if data.Status != nil {
+		if err := validateStatus(*data.Status); err != nil {
+			return nil, err
+		}
+		if data.IsCompleted != nil && *data.IsCompleted != (*data.Status == task.StatusDone) {
+			return nil, errors.InputValidationError("Invalid task 'is_completed'",
+				"Task 'is_completed' value contradicts 'status'", errors.AppErrorField{
+					Field:  "is_completed",
+					Reason: "Task 'is_completed' can only be true with status 'done'",
+				})
+		}
+	}
+
+	if data.Status == nil && data.IsCompleted == nil {
+		return ts.applyUpdate(ownerId, taskId, version, update)
+	}
+
+	// the transition is checked against the task it is applied to, so the
+	// update is conditioned on the version that was checked
+	for attempt := 1; ; attempt++ {
+		current, err := ts.taskRepository.Get(ownerId, taskId)
+		if err != nil {
+			return nil, err
+		}
+		if version != nil && current.Version != *version {
+			return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d, not %d", taskId, current.Version, *version))
+		}
+
+		status := nextStatus(current.Status, data)
+		if err := validateTransition(current.Status, status); err != nil {
+			return nil, err
+		}
+		update["Status"] = status
+
+		var updated *task.Task
+		switch {
+		case status == task.StatusDone && current.Status != task.StatusDone && (current.Recurrence != nil || data.Recurrence != nil):
+			updated, err = ts.completeOccurrence(ownerId, current, update)
+		case status == task.StatusDone && current.Status != task.StatusDone:
+			updated, err = ts.completeTask(ownerId, current, update)
+		default:
+			updated, err = ts.applyUpdate(ownerId, taskId, &current.Version, update)
+		}
+		if appError, ok := err.(*errors.AppError); ok && appError.Type == errors.PRECONDITION && version == nil && attempt < maxStatusAttempts {
+			continue
+		}
+		return updated, err
+	}
+}
+
+// validateClearDueAt checks that the due date of the task can be removed, a
+// recurring task needs it unless the update stops the series.
+func (ts *TaskService) validateClearDueAt(ownerId, taskId string, data services.UpdateTaskData) error {
+	if data.DueAt != nil {
+		return errors.InputValidationError("Invalid task 'due_at'", "Task 'due_at' can't be set and cleared", errors.AppErrorField{
+			Field:  "due_at",
+			Reason: "Task 'due_at' can either be set or cleared in one update",
+		})
+	}
+	if data.Recurrence != nil {
+		return nil
+	}
+
+	current, err := ts.taskRepository.Get(ownerId, taskId)
+	if err != nil {
+		return err
+	}
+	if current.Recurrence != nil {
+		return errors.InputValidationError("Invalid task 'due_at'", "Task 'due_at' is missing", errors.AppErrorField{
+			Field:  "due_at",
+			Reason: "Task 'due_at' is required for a recurring task, it is the start of the series",
+		})
+	}
+	return nil
+}
+
+func (ts *TaskService) applyUpdate(ownerId, taskId string, version *int, update map[string]any) (*task.Task, error) {
+	task, err := ts.taskRepository.Update(ownerId, taskId, version, update)
 	if err != nil {
 		return nil, err
 	}
@@ -106,8 +454,29 @@ func (ts *TaskService) UpdateTask(taskId string, data services.UpdateTaskData) (
 	return task, nil
 }
 
-func (ts *TaskService) DeleteTask(taskId string) (*string, error) {
-	dId, err := ts.taskRepository.Delete(taskId)
+// DeleteTask moves the task to the trash, deleting an open occurrence of a
+// recurring task skips it and the series goes on with the next one.
+func (ts *TaskService) DeleteTask(ownerId, taskId string, version *int) (*string, error) {
+	current, err := ts.taskRepository.Get(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	if current.Recurrence == nil || current.Status == task.StatusDone {
+		return ts.taskRepository.Delete(ownerId, taskId, version)
+	}
+
+	var dId *string
+	err = ts.taskRepository.InTransaction(func(repo storages.TaskRepository) error {
+		tx := ts.withRepository(repo)
+
+		var err error
+		dId, err = repo.Delete(ownerId, taskId, version)
+		if err != nil {
+			return err
+		}
+		_, err = tx.nextOccurrence(ownerId, current)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -115,25 +484,110 @@ func (ts *TaskService) DeleteTask(taskId string) (*string, error) {
 	return dId, nil
 }
 
-func (ts *TaskService) SearchTasks(query string) ([]task.Task, error) {
-	allTasks, err := ts.taskRepository.List()
+// SetTaskTags replaces the tags of a task, tags that no task uses anymore are
+// kept for the owner.
+func (ts *TaskService) SetTaskTags(ownerId, taskId string, version *int, names []string) (*task.Task, error) {
+	tags, err := normalizeTags("tags", names)
 	if err != nil {
 		return nil, err
 	}
+	if len(tags) > task.MaxTagsPerTask {
+		return nil, errors.InputValidationError("Invalid task 'tags'", "Task 'tags' value is invalid", errors.AppErrorField{
+			Field:  "tags",
+			Reason: fmt.Sprintf("Task can't have more than %d tags", task.MaxTagsPerTask),
+		})
+	}
 
-	matches := []task.Task{}
-	contains := false
-	for _, task := range allTasks {
-		contains = true
-		for part := range strings.SplitSeq(query, " ") {
-			if !strings.Contains(strings.ToLower(task.Title), strings.ToLower(part)) {
-				contains = false
-			}
-		}
-		if contains {
-			matches = append(matches, task)
+	return ts.taskRepository.SetTags(ownerId, taskId, version, tags)
+}
+
+func (ts *TaskService) GetTags(ownerId string) (*services.TagList, error) {
+	tags, err := ts.taskRepository.ListTags(ownerId)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []task.TagCount{}
+	}
+
+	return &services.TagList{
+		Tags: tags,
+	}, nil
+}
+
+func (ts *TaskService) RestoreTask(ownerId, taskId string) (*task.Task, error) {
+	task, err := ts.taskRepository.Restore(ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// PurgeTasks permanently removes the tasks of every owner that stayed in the
+// trash for longer than retention.
+func (ts *TaskService) PurgeTasks(retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		return 0, fmt.Errorf("purge retention must be positive, got %s", retention)
+	}
+
+	return ts.taskRepository.Purge(time.Now().Add(-retention))
+}
+
+func (ts *TaskService) SearchTasks(ownerId string, query services.SearchTasksQuery) (*services.SearchPage, error) {
+	options := task.SearchOptions{
+		Terms: task.ParseSearchQuery(query.Query),
+		Limit: query.Limit,
+	}
+
+	if len(options.Terms) == 0 {
+		return nil, errors.InputValidationError("Invalid search query", "Search query 'q' is invalid", errors.AppErrorField{
+			Field:  "q",
+			Reason: "Search query 'q' must contain at least one word",
+		})
+	}
+
+	if options.Limit == 0 {
+		options.Limit = defaultPageLimit
+	}
+	if options.Limit < 0 || options.Limit > maxPageLimit {
+		return nil, errors.InputValidationError("Invalid search option", "Search option 'limit' is invalid", errors.AppErrorField{
+			Field:  "limit",
+			Reason: fmt.Sprintf("Search 'limit' must be between 1 and %d", maxPageLimit),
+		})
+	}
+
+	if query.Cursor != "" {
+		offset, ok := decodeSearchCursor(query.Cursor)
+		if !ok {
+			return nil, errors.InputValidationError("Invalid search option", "Search option 'cursor' is invalid", errors.AppErrorField{
+				Field:  "cursor",
+				Reason: "Search 'cursor' is malformed",
+			})
 		}
+		options.Offset = offset
+	}
+
+	// one extra result tells whether there is a next page
+	pageLimit := options.Limit
+	options.Limit++
+
+	results, err := ts.taskRepository.Search(ownerId, options)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &services.SearchPage{
+		Tasks: results,
+	}
+	if page.Tasks == nil {
+		page.Tasks = []task.SearchResult{}
+	}
+	if len(page.Tasks) > pageLimit {
+		page.Tasks = page.Tasks[:pageLimit]
+		nextCursor := encodeSearchCursor(options.Offset + pageLimit)
+		page.NextCursor = &nextCursor
 	}
 
-	return matches, nil
+	return page, nil
 }
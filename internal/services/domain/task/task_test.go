@@ -1,20 +1,39 @@
 package task
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Arup3201/gotasks/internal/entities/task"
 	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/events"
 	"github.com/Arup3201/gotasks/internal/services"
 )
 
+const owner = "test-owner"
+
+func newTask(title, description string) services.CreateTaskData {
+	return services.CreateTaskData{
+		Title:       &title,
+		Description: &description,
+	}
+}
+
 func TestAddTask(t *testing.T) {
 	t.Run("Create a task - 1", func(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		got, _ := ts.CreateTask(title, description)
+		got, _ := ts.CreateTask(owner, newTask(title, description))
 
 		if got.Title != title {
 			t.Errorf("expected title %s but got %s", title, got.Title)
@@ -28,7 +47,7 @@ func TestAddTask(t *testing.T) {
 		description := "Test task 1 description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		got, _ := ts.CreateTask(title, description)
+		got, _ := ts.CreateTask(owner, newTask(title, description))
 
 		if got.Title != title {
 			t.Errorf("expected title %s but got %s", title, got.Title)
@@ -42,7 +61,7 @@ func TestAddTask(t *testing.T) {
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		got, _ := ts.CreateTask(title, description)
+		got, _ := ts.CreateTask(owner, newTask(title, description))
 
 		if got.Id == "" {
 			t.Errorf("expected non-empty task ID")
@@ -53,8 +72,8 @@ func TestAddTask(t *testing.T) {
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		task1, _ := ts.CreateTask(title, description)
-		task2, _ := ts.CreateTask(title, description)
+		task1, _ := ts.CreateTask(owner, newTask(title, description))
+		task2, _ := ts.CreateTask(owner, newTask(title, description))
 
 		if task1.Id == task2.Id {
 			t.Errorf("Two tasks can't have same ID")
@@ -65,7 +84,7 @@ func TestAddTask(t *testing.T) {
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		task, _ := ts.CreateTask(title, description)
+		task, _ := ts.CreateTask(owner, newTask(title, description))
 
 		if task.CreatedAt.IsZero() {
 			t.Errorf("created task has zero created_at value")
@@ -76,7 +95,7 @@ func TestAddTask(t *testing.T) {
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		task, _ := ts.CreateTask(title, description)
+		task, _ := ts.CreateTask(owner, newTask(title, description))
 
 		if task.UpdatedAt.IsZero() {
 			t.Errorf("created task has zero updated_at value")
@@ -87,7 +106,7 @@ func TestAddTask(t *testing.T) {
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		_, err := ts.CreateTask(title, description)
+		_, err := ts.CreateTask(owner, newTask(title, description))
 		inputInvalidError, ok := err.(*errors.AppError)
 		if !ok {
 			t.Errorf("expected `Error` on create task with empty title")
@@ -101,7 +120,7 @@ func TestAddTask(t *testing.T) {
 		description := ""
 		ts, _ := NewTaskService(NewMockTaskRepository())
 
-		_, err := ts.CreateTask(title, description)
+		_, err := ts.CreateTask(owner, newTask(title, description))
 		inputInvalidError, ok := err.(*errors.AppError)
 		if !ok {
 			t.Errorf("expected `Error` on create task with empty description")
@@ -117,9 +136,9 @@ func TestGetTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 
-		task, _ := ts.GetTask(created.Id)
+		task, _ := ts.GetTask(owner, created.Id)
 
 		if task.Id != created.Id {
 			t.Errorf("Task ID does not match, expected %s but got %s", created.Id, task.Id)
@@ -129,9 +148,9 @@ func TestGetTask(t *testing.T) {
 		title := "Test task 1"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 
-		task, _ := ts.GetTask(created.Id)
+		task, _ := ts.GetTask(owner, created.Id)
 
 		if task.Title != title {
 			t.Errorf("Task title does not match, expected %s but got %s", title, task.Title)
@@ -141,9 +160,9 @@ func TestGetTask(t *testing.T) {
 		title := "Test task 2"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 
-		task, _ := ts.GetTask(created.Id)
+		task, _ := ts.GetTask(owner, created.Id)
 
 		if task.Title != title {
 			t.Errorf("Task title does not match, expected %s but got %s", title, task.Title)
@@ -151,6 +170,53 @@ func TestGetTask(t *testing.T) {
 	})
 }
 
+func TestTaskOwnership(t *testing.T) {
+	t.Run("Get task of another owner is not found", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+
+		_, err := ts.GetTask("other-owner", created.Id)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok {
+			t.Errorf("expected `Error` on get task of another owner")
+			return
+		}
+		if appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected `NOT_FOUND` type error on get task of another owner")
+		}
+	})
+	t.Run("Update task of another owner is not found", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		title := "Test task (updated)"
+
+		_, err := ts.UpdateTask("other-owner", created.Id, nil, services.UpdateTaskData{
+			Title: &title,
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok {
+			t.Errorf("expected `Error` on update task of another owner")
+			return
+		}
+		if appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected `NOT_FOUND` type error on update task of another owner")
+		}
+	})
+	t.Run("List only returns tasks of the owner", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		ts.CreateTask(owner, newTask("Test task 1", "Test task description"))
+		ts.CreateTask("other-owner", newTask("Test task 2", "Test task description"))
+
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+
+		if len(page.Tasks) != 1 {
+			t.Errorf("expected 1 task but got %d", len(page.Tasks))
+		}
+	})
+}
+
 func TestGetAllTasks(t *testing.T) {
 	t.Run("get all tasks", func(t *testing.T) {
 		cases := []struct {
@@ -176,30 +242,81 @@ func TestGetAllTasks(t *testing.T) {
 		}
 		ts, _ := NewTaskService(NewMockTaskRepository())
 		for _, tc := range cases {
-			ts.CreateTask(tc.title, tc.description)
+			ts.CreateTask(owner, newTask(tc.title, tc.description))
 		}
 
-		tasks, err := ts.GetAllTasks()
+		page, err := ts.GetAllTasks(owner, services.ListTasksQuery{})
 
 		if err != nil {
 			t.Errorf("GetAllTasks error: %v", err)
 		}
 		want := 4
-		if got := len(tasks); got != want {
+		if got := len(page.Tasks); got != want {
 			t.Errorf("expected %d tasks, but got %d", want, got)
 		}
 	})
 }
 
+func TestGetAllTasksPagination(t *testing.T) {
+	t.Run("next cursor is set when there are more tasks", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		for range 3 {
+			ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		}
+
+		page, err := ts.GetAllTasks(owner, services.ListTasksQuery{Limit: 2})
+
+		if err != nil {
+			t.Errorf("GetAllTasks error: %v", err)
+			return
+		}
+		if len(page.Tasks) != 2 {
+			t.Errorf("expected 2 tasks, but got %d", len(page.Tasks))
+		}
+		if page.NextCursor == nil {
+			t.Errorf("expected next cursor to be set")
+		}
+	})
+	t.Run("next cursor is empty on the last page", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		for range 2 {
+			ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		}
+
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{Limit: 2})
+
+		if page.NextCursor != nil {
+			t.Errorf("expected no next cursor but got %s", *page.NextCursor)
+		}
+	})
+	t.Run("fail with invalid list options", func(t *testing.T) {
+		cases := []services.ListTasksQuery{
+			{SortBy: "description"},
+			{Order: "up"},
+			{Limit: 1000},
+			{Cursor: "not-a-cursor"},
+		}
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		for _, query := range cases {
+			_, err := ts.GetAllTasks(owner, query)
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || appError.Type != errors.INVALID_INPUT {
+				t.Errorf("expected `INVALID_INPUT` error for %+v but got %v", query, err)
+			}
+		}
+	})
+}
+
 func TestUpdateTask(t *testing.T) {
 	t.Run("update task updates correct task", func(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 		updated_title := "Test task (updated)"
 
-		updated, _ := ts.UpdateTask(created.Id, services.UpdateTaskData{
+		updated, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
 			Title: &updated_title,
 		})
 
@@ -214,10 +331,10 @@ func TestUpdateTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 		updated_title := "Test task (updated)"
 
-		updated, _ := ts.UpdateTask(created.Id, services.UpdateTaskData{
+		updated, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
 			Title: &updated_title,
 		})
 
@@ -229,10 +346,10 @@ func TestUpdateTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 		updated_description := "Test task description (updated)"
 
-		updated, _ := ts.UpdateTask(created.Id, services.UpdateTaskData{
+		updated, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
 			Description: &updated_description,
 		})
 
@@ -244,10 +361,10 @@ func TestUpdateTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 		isCompleted := true
 
-		updated, _ := ts.UpdateTask(created.Id, services.UpdateTaskData{
+		updated, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
 			IsCompleted: &isCompleted,
 		})
 
@@ -259,11 +376,11 @@ func TestUpdateTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 		time.Sleep(1000 * 2) // 2 secs
 		updated_title := "Test task (updated)"
 
-		updated, _ := ts.UpdateTask(created.Id, services.UpdateTaskData{
+		updated, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
 			Title: &updated_title,
 		})
 
@@ -278,18 +395,191 @@ func TestUpdateTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 		updated_title := "Test task (updated)"
-		updated, _ := ts.UpdateTask(created.Id, services.UpdateTaskData{
+		updated, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
 			Title: &updated_title,
 		})
 
-		task, _ := ts.GetTask(created.Id)
+		task, _ := ts.GetTask(owner, created.Id)
 
 		if task.Title != updated.Title {
 			t.Errorf("task update did not persist, expected %s but got %s", updated.Title, task.Title)
 		}
 	})
+	t.Run("Update task with a stale version", func(t *testing.T) {
+		title := "Test task"
+		description := "Test task description"
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask(title, description))
+		stale := created.Version
+		updated_title := "Test task (updated)"
+		ts.UpdateTask(owner, created.Id, &stale, services.UpdateTaskData{
+			Title: &updated_title,
+		})
+
+		_, err := ts.UpdateTask(owner, created.Id, &stale, services.UpdateTaskData{
+			Title: &title,
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition failed error, but got %v", err)
+		}
+	})
+}
+
+func TestTaskPlanning(t *testing.T) {
+	t.Run("Create task with default status and priority", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+
+		if created.Status != task.StatusTodo || created.Priority != task.PriorityMedium {
+			t.Errorf("expected a todo of medium priority, but got %s of %s priority", created.Status, created.Priority)
+		}
+		if created.IsCompleted {
+			t.Errorf("expected a new task to not be completed")
+		}
+	})
+	t.Run("Create task with planning fields", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		data := newTask("Test task", "Test task description")
+		priority := task.PriorityUrgent
+		dueAt := time.Now().Add(24 * time.Hour)
+		data.Priority = &priority
+		data.DueAt = &dueAt
+
+		created, _ := ts.CreateTask(owner, data)
+
+		if created.Priority != priority {
+			t.Errorf("expected priority %s, but got %s", priority, created.Priority)
+		}
+		if created.DueAt == nil || !created.DueAt.Equal(dueAt) {
+			t.Errorf("expected due_at %v, but got %v", dueAt, created.DueAt)
+		}
+	})
+	t.Run("Clear the due date of a task", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		data := newTask("Test task", "Test task description")
+		dueAt := time.Now().Add(24 * time.Hour)
+		data.DueAt = &dueAt
+		created, _ := ts.CreateTask(owner, data)
+
+		_, err := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{DueAt: &dueAt, ClearDueAt: true})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "due_at" {
+			t.Errorf("expected invalid due_at error, but got %v", err)
+		}
+		updated, err := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{ClearDueAt: true})
+		if err != nil || updated.DueAt != nil {
+			t.Errorf("expected the due date to be cleared, but got %v, %v", updated, err)
+		}
+	})
+	t.Run("Create task fail with invalid priority", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		data := newTask("Test task", "Test task description")
+		priority := "someday"
+		data.Priority = &priority
+
+		_, err := ts.CreateTask(owner, data)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "priority" {
+			t.Errorf("expected invalid priority error, but got %v", err)
+		}
+	})
+	t.Run("Move task through the workflow", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+
+		for _, status := range []string{task.StatusInProgress, task.StatusBlocked, task.StatusInProgress, task.StatusDone} {
+			updated, err := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
+				Status: &status,
+			})
+			if err != nil {
+				t.Fatalf("moving to %s failed: %v", status, err)
+			}
+			if updated.Status != status {
+				t.Errorf("expected status %s, but got %s", status, updated.Status)
+			}
+			if updated.IsCompleted != (status == task.StatusDone) {
+				t.Errorf("expected is_completed to follow status %s", status)
+			}
+		}
+	})
+	t.Run("Blocked task can't be done", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		data := newTask("Test task", "Test task description")
+		blocked := task.StatusBlocked
+		data.Status = &blocked
+		created, _ := ts.CreateTask(owner, data)
+		done := task.StatusDone
+
+		_, err := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
+			Status: &done,
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT {
+			t.Errorf("expected invalid transition error, but got %v", err)
+		}
+	})
+	t.Run("is_completed moves the task to done and back", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		completed, reopened := true, false
+
+		done, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
+			IsCompleted: &completed,
+		})
+		todo, _ := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
+			IsCompleted: &reopened,
+		})
+
+		if done.Status != task.StatusDone || !done.IsCompleted {
+			t.Errorf("expected a done task, but got status %s", done.Status)
+		}
+		if todo.Status != task.StatusTodo || todo.IsCompleted {
+			t.Errorf("expected a reopened todo, but got status %s", todo.Status)
+		}
+	})
+	t.Run("is_completed can't contradict status", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		status := task.StatusInProgress
+		completed := true
+
+		_, err := ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
+			Status:      &status,
+			IsCompleted: &completed,
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT {
+			t.Errorf("expected invalid input error, but got %v", err)
+		}
+	})
+	t.Run("Status change with a stale version", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		stale := created.Version
+		title := "Test task (updated)"
+		ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{
+			Title: &title,
+		})
+		status := task.StatusInProgress
+
+		_, err := ts.UpdateTask(owner, created.Id, &stale, services.UpdateTaskData{
+			Status: &status,
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition failed error, but got %v", err)
+		}
+	})
 }
 
 func TestDeleteTask(t *testing.T) {
@@ -297,9 +587,9 @@ func TestDeleteTask(t *testing.T) {
 		title := "Test task"
 		description := "Test task description"
 		ts, _ := NewTaskService(NewMockTaskRepository())
-		created, _ := ts.CreateTask(title, description)
+		created, _ := ts.CreateTask(owner, newTask(title, description))
 
-		taskId, _ := ts.DeleteTask(created.Id)
+		taskId, _ := ts.DeleteTask(owner, created.Id, nil)
 
 		if *taskId != created.Id {
 			t.Errorf("Task ID does not match, expected %s but got %s", created.Id, *taskId)
@@ -307,6 +597,165 @@ func TestDeleteTask(t *testing.T) {
 	})
 }
 
+func TestTrash(t *testing.T) {
+	t.Run("Deleted task moves to the trash", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+
+		ts.DeleteTask(owner, created.Id, nil)
+
+		if _, err := ts.GetTask(owner, created.Id); err == nil {
+			t.Errorf("expected deleted task to be hidden")
+		}
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if len(page.Tasks) != 0 {
+			t.Errorf("expected no live tasks, but got %d", len(page.Tasks))
+		}
+		trash, _ := ts.GetTrash(owner, services.ListTasksQuery{})
+		if len(trash.Tasks) != 1 || trash.Tasks[0].Id != created.Id {
+			t.Errorf("expected the deleted task in the trash, but got %v", trash.Tasks)
+		}
+	})
+	t.Run("Restore task from the trash", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		ts.DeleteTask(owner, created.Id, nil)
+
+		restored, err := ts.RestoreTask(owner, created.Id)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if restored.IsDeleted() {
+			t.Errorf("expected restored task to not be deleted")
+		}
+		if _, err := ts.GetTask(owner, created.Id); err != nil {
+			t.Errorf("expected restored task to be found, but got %v", err)
+		}
+	})
+	t.Run("Purge tasks older than the retention", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		ts.DeleteTask(owner, created.Id, nil)
+
+		purged, _ := ts.PurgeTasks(time.Hour)
+		if purged != 0 {
+			t.Errorf("expected recently deleted task to be kept, but %d were purged", purged)
+		}
+		time.Sleep(time.Millisecond)
+		purged, _ = ts.PurgeTasks(time.Nanosecond)
+		if purged != 1 {
+			t.Errorf("expected 1 purged task, but got %d", purged)
+		}
+	})
+	t.Run("Purge fail with no retention", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+
+		if _, err := ts.PurgeTasks(0); err == nil {
+			t.Errorf("expected purge without retention to fail")
+		}
+	})
+}
+
+func TestTaskTags(t *testing.T) {
+	t.Run("Tags are normalized and deduplicated", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+
+		tagged, err := ts.SetTaskTags(owner, created.Id, nil, []string{"Work Stuff", " work-stuff ", "HOME"})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(tagged.Tags) != 2 || tagged.Tags[0] != "home" || tagged.Tags[1] != "work-stuff" {
+			t.Errorf("expected tags [home work-stuff], but got %v", tagged.Tags)
+		}
+	})
+	t.Run("Invalid tag fail", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+
+		for _, tags := range [][]string{{""}, {"no/slash"}, {strings.Repeat("a", task.MaxTagLength+1)}} {
+			_, err := ts.SetTaskTags(owner, created.Id, nil, tags)
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || appError.Type != errors.INVALID_INPUT {
+				t.Errorf("expected invalid input error for %q, but got %v", tags, err)
+			}
+		}
+	})
+	t.Run("Too many tags fail", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Test task", "Test task description"))
+		tags := []string{}
+		for i := range task.MaxTagsPerTask + 1 {
+			tags = append(tags, fmt.Sprintf("tag-%d", i))
+		}
+
+		_, err := ts.SetTaskTags(owner, created.Id, nil, tags)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT {
+			t.Errorf("expected invalid input error, but got %v", err)
+		}
+	})
+	t.Run("List tasks by any or all tags", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, newTask("Test task 1", "Test task description"))
+		second, _ := ts.CreateTask(owner, newTask("Test task 2", "Test task description"))
+		ts.CreateTask(owner, newTask("Test task 3", "Test task description"))
+		ts.SetTaskTags(owner, first.Id, nil, []string{"home", "work"})
+		ts.SetTaskTags(owner, second.Id, nil, []string{"work"})
+
+		anyPage, err := ts.GetAllTasks(owner, services.ListTasksQuery{Tags: []string{"Home", "WORK"}})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		allPage, err := ts.GetAllTasks(owner, services.ListTasksQuery{Tags: []string{"home", "work"}, TagMatch: "all"})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		if len(anyPage.Tasks) != 2 {
+			t.Errorf("expected 2 tasks with any of the tags, but got %d", len(anyPage.Tasks))
+		}
+		if len(allPage.Tasks) != 1 || allPage.Tasks[0].Id != first.Id {
+			t.Errorf("expected only the first task with all of the tags, but got %v", allPage.Tasks)
+		}
+	})
+	t.Run("List tasks with invalid tag match fail", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+
+		_, err := ts.GetAllTasks(owner, services.ListTasksQuery{Tags: []string{"home"}, TagMatch: "some"})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT {
+			t.Errorf("expected invalid input error, but got %v", err)
+		}
+	})
+	t.Run("Get tags with usage counts", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, newTask("Test task 1", "Test task description"))
+		second, _ := ts.CreateTask(owner, newTask("Test task 2", "Test task description"))
+		ts.SetTaskTags(owner, first.Id, nil, []string{"home", "work"})
+		ts.SetTaskTags(owner, second.Id, nil, []string{"work"})
+
+		list, err := ts.GetTags(owner)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(list.Tags) != 2 || list.Tags[0] != (task.TagCount{Name: "home", Count: 1}) || list.Tags[1] != (task.TagCount{Name: "work", Count: 2}) {
+			t.Errorf("expected tags home(1) and work(2), but got %v", list.Tags)
+		}
+	})
+}
+
 func TestSearchTasks(t *testing.T) {
 	t.Run("Search tasks with match", func(t *testing.T) {
 		tasks := []struct {
@@ -332,18 +781,18 @@ func TestSearchTasks(t *testing.T) {
 		}
 		ts, _ := NewTaskService(NewMockTaskRepository())
 		for _, task := range tasks {
-			ts.CreateTask(task.title, task.description)
+			ts.CreateTask(owner, newTask(task.title, task.description))
 		}
 		query := "learn"
 
-		results, err := ts.SearchTasks(query)
+		results, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: query})
 
 		want := 2
 		if err != nil {
 			t.Errorf("Search failed: %v", err)
 			return
 		}
-		if got := len(results); got != want {
+		if got := len(results.Tasks); got != want {
 			t.Errorf("expected searched results %d but got %d", want, got)
 		}
 	})
@@ -371,18 +820,18 @@ func TestSearchTasks(t *testing.T) {
 		}
 		ts, _ := NewTaskService(NewMockTaskRepository())
 		for _, task := range tasks {
-			ts.CreateTask(task.title, task.description)
+			ts.CreateTask(owner, newTask(task.title, task.description))
 		}
 		query := "nothing"
 
-		results, err := ts.SearchTasks(query)
+		results, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: query})
 
 		want := 0
 		if err != nil {
 			t.Errorf("Search failed: %v", err)
 			return
 		}
-		if got := len(results); got != want {
+		if got := len(results.Tasks); got != want {
 			t.Errorf("expected searched results %d but got %d", want, got)
 		}
 	})
@@ -410,18 +859,18 @@ func TestSearchTasks(t *testing.T) {
 		}
 		ts, _ := NewTaskService(NewMockTaskRepository())
 		for _, task := range tasks {
-			ts.CreateTask(task.title, task.description)
+			ts.CreateTask(owner, newTask(task.title, task.description))
 		}
 		query := "learn golang"
 
-		results, err := ts.SearchTasks(query)
+		results, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: query})
 
 		want := 1
 		if err != nil {
 			t.Errorf("Search failed: %v", err)
 			return
 		}
-		if got := len(results); got != want {
+		if got := len(results.Tasks); got != want {
 			t.Errorf("expected searched results %d but got %d", want, got)
 		}
 	})
@@ -449,18 +898,18 @@ func TestSearchTasks(t *testing.T) {
 		}
 		ts, _ := NewTaskService(NewMockTaskRepository())
 		for _, task := range tasks {
-			ts.CreateTask(task.title, task.description)
+			ts.CreateTask(owner, newTask(task.title, task.description))
 		}
 		query := "learn language"
 
-		results, err := ts.SearchTasks(query)
+		results, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: query})
 
 		want := 2
 		if err != nil {
 			t.Errorf("Search failed: %v", err)
 			return
 		}
-		if got := len(results); got != want {
+		if got := len(results.Tasks); got != want {
 			t.Errorf("expected searched results %d but got %d", want, got)
 		}
 	})
@@ -488,19 +937,1069 @@ func TestSearchTasks(t *testing.T) {
 		}
 		ts, _ := NewTaskService(NewMockTaskRepository())
 		for _, task := range tasks {
-			ts.CreateTask(task.title, task.description)
+			ts.CreateTask(owner, newTask(task.title, task.description))
 		}
 		query := "play hr"
 
-		results, err := ts.SearchTasks(query)
+		results, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: query})
 
 		want := 2 // tasks[2].title has 'hrs' which has 'hr' in it
 		if err != nil {
 			t.Errorf("Search failed: %v", err)
 			return
 		}
-		if got := len(results); got != want {
+		if got := len(results.Tasks); got != want {
 			t.Errorf("expected searched results %d but got %d", want, got)
 		}
 	})
 }
+
+func TestSearchTasksPagination(t *testing.T) {
+	t.Run("Search tasks page by page", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		for range 3 {
+			ts.CreateTask(owner, newTask("Learn Golang", "Learn reflect concept in Golang"))
+		}
+
+		first, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: "learn", Limit: 2})
+		if err != nil {
+			t.Errorf("Search failed: %v", err)
+			return
+		}
+		if first.NextCursor == nil {
+			t.Errorf("expected next cursor on the first page")
+			return
+		}
+		second, _ := ts.SearchTasks(owner, services.SearchTasksQuery{Query: "learn", Limit: 2, Cursor: *first.NextCursor})
+
+		if got := len(first.Tasks) + len(second.Tasks); got != 3 {
+			t.Errorf("expected searched results %d but got %d", 3, got)
+		}
+		if second.NextCursor != nil {
+			t.Errorf("expected no next cursor on the last page")
+		}
+	})
+	t.Run("Search tasks fail without words", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+
+		_, err := ts.SearchTasks(owner, services.SearchTasksQuery{Query: `"" * !`})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT {
+			t.Errorf("expected `INVALID_INPUT` error but got %v", err)
+		}
+	})
+}
+
+func TestRunBatch(t *testing.T) {
+	title, description, empty := "Batch task", "Batch task description", ""
+
+	t.Run("best effort batch applies every valid operation", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		existing, _ := ts.CreateTask(owner, newTask("Existing task", "Existing task description"))
+		status := task.StatusDone
+
+		results, err := ts.RunBatch(owner, services.Batch{Operations: []services.BatchOperation{
+			{Op: services.BatchCreate, Data: services.UpdateTaskData{Title: &title, Description: &description}},
+			{Op: services.BatchCreate, Data: services.UpdateTaskData{Title: &empty, Description: &description}},
+			{Op: services.BatchUpdate, TaskId: existing.Id, Data: services.UpdateTaskData{Status: &status}},
+			{Op: "archive", TaskId: existing.Id},
+		}})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if results[0].Err != nil || results[0].Task.Title != title {
+			t.Errorf("expected task %s to be created, but got %+v", title, results[0])
+		}
+		appError, ok := results[1].Err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "title" {
+			t.Errorf("expected invalid title, but got %v", results[1].Err)
+		}
+		if results[2].Err != nil || !results[2].Task.IsCompleted {
+			t.Errorf("expected task %s to be done, but got %+v", existing.Id, results[2])
+		}
+		if appError, ok := results[3].Err.(*errors.AppError); !ok || appError.Errors[0].Field != "op" {
+			t.Errorf("expected invalid op, but got %v", results[3].Err)
+		}
+		if page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{}); len(page.Tasks) != 2 {
+			t.Errorf("expected 2 tasks, but got %d", len(page.Tasks))
+		}
+	})
+	t.Run("atomic batch is rolled back when an operation fails", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		existing, _ := ts.CreateTask(owner, newTask("Existing task", "Existing task description"))
+
+		results, err := ts.RunBatch(owner, services.Batch{Atomic: true, Operations: []services.BatchOperation{
+			{Op: services.BatchCreate, Data: services.UpdateTaskData{Title: &title, Description: &description}},
+			{Op: services.BatchDelete, TaskId: existing.Id},
+			{Op: services.BatchUpdate, TaskId: "missing-task", Data: services.UpdateTaskData{Title: &title}},
+			{Op: services.BatchDelete, TaskId: existing.Id},
+		}})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		for _, i := range []int{0, 1, 3} {
+			if !results[i].RolledBack || results[i].Err != nil || results[i].Task != nil {
+				t.Errorf("expected operation %d to be rolled back, but got %+v", i, results[i])
+			}
+		}
+		if appError, ok := results[2].Err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected task not found, but got %v", results[2].Err)
+		}
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if len(page.Tasks) != 1 || page.Tasks[0].Id != existing.Id {
+			t.Errorf("expected only task %s, but got %d tasks", existing.Id, len(page.Tasks))
+		}
+	})
+	t.Run("atomic batch applies every operation", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		existing, _ := ts.CreateTask(owner, newTask("Existing task", "Existing task description"))
+		version := existing.Version
+
+		results, err := ts.RunBatch(owner, services.Batch{Atomic: true, Operations: []services.BatchOperation{
+			{Op: services.BatchCreate, Data: services.UpdateTaskData{Title: &title, Description: &description}},
+			{Op: services.BatchDelete, TaskId: existing.Id, Version: &version},
+		}})
+
+		if err != nil || results[0].Err != nil || results[1].Err != nil {
+			t.Errorf("unexpected errors: %v, %+v", err, results)
+			return
+		}
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if len(page.Tasks) != 1 || page.Tasks[0].Id != results[0].TaskId {
+			t.Errorf("expected only the created task, but got %d tasks", len(page.Tasks))
+		}
+	})
+	t.Run("batch without operations fail", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+
+		_, err := ts.RunBatch(owner, services.Batch{})
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Errors[0].Field != "operations" {
+			t.Errorf("expected invalid operations, but got %v", err)
+		}
+	})
+}
+
+func TestTaskHierarchy(t *testing.T) {
+	newSubtask := func(ts *TaskService, parentId string) (*task.Task, error) {
+		data := newTask("Subtask", "Subtask description")
+		data.ParentId = &parentId
+		return ts.CreateTask(owner, data)
+	}
+
+	t.Run("subtasks are listed under their parent", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		subtask, _ := newSubtask(ts, parent.Id)
+		newSubtask(ts, subtask.Id)
+
+		page, err := ts.GetSubtasks(owner, parent.Id, services.ListTasksQuery{})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(page.Tasks) != 1 || page.Tasks[0].Id != subtask.Id {
+			t.Errorf("expected only subtask %s, but got %d tasks", subtask.Id, len(page.Tasks))
+		}
+	})
+	t.Run("missing parent is invalid", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+
+		_, err := newSubtask(ts, "missing-task")
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "parent_id" {
+			t.Errorf("expected invalid parent_id, but got %v", err)
+		}
+	})
+	t.Run("task can't move under its own subtask", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		subtask, _ := newSubtask(ts, parent.Id)
+		grandchild, _ := newSubtask(ts, subtask.Id)
+
+		for _, parentId := range []string{parent.Id, grandchild.Id} {
+			_, err := ts.UpdateTask(owner, parent.Id, nil, services.UpdateTaskData{ParentId: &parentId})
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "parent_id" {
+				t.Errorf("expected invalid parent_id %s, but got %v", parentId, err)
+			}
+		}
+	})
+	t.Run("hierarchy can't be deeper than the limit", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		top, _ := ts.CreateTask(owner, newTask("Top task", "Top task description"))
+		last := top
+		for range task.MaxDepth - 1 {
+			last, _ = newSubtask(ts, last.Id)
+		}
+
+		_, err := newSubtask(ts, last.Id)
+		if appError, ok := err.(*errors.AppError); !ok || appError.Errors[0].Field != "parent_id" {
+			t.Errorf("expected invalid parent_id, but got %v", err)
+		}
+
+		// a task with a subtask needs two free levels
+		other, _ := ts.CreateTask(owner, newTask("Other task", "Other task description"))
+		newSubtask(ts, other.Id)
+		_, err = ts.UpdateTask(owner, other.Id, nil, services.UpdateTaskData{ParentId: &top.Id})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		parentId := *last.ParentId
+		_, err = ts.UpdateTask(owner, other.Id, nil, services.UpdateTaskData{ParentId: &parentId})
+		if appError, ok := err.(*errors.AppError); !ok || appError.Errors[0].Field != "parent_id" {
+			t.Errorf("expected invalid parent_id, but got %v", err)
+		}
+	})
+	t.Run("empty parent moves the task to the top level", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		subtask, _ := newSubtask(ts, parent.Id)
+		empty := ""
+
+		got, err := ts.UpdateTask(owner, subtask.Id, nil, services.UpdateTaskData{ParentId: &empty})
+
+		if err != nil || got.ParentId != nil {
+			t.Errorf("expected a top level task, but got %+v, %v", got, err)
+		}
+	})
+	t.Run("tree counts the progress of every parent", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		first, _ := newSubtask(ts, parent.Id)
+		newSubtask(ts, parent.Id)
+		grandchild, _ := newSubtask(ts, first.Id)
+		done := task.StatusDone
+		ts.UpdateTask(owner, grandchild.Id, nil, services.UpdateTaskData{Status: &done})
+
+		tree, err := ts.GetTaskTree(owner, parent.Id)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if tree.Progress != (services.TaskProgress{Done: 1, Total: 3}) {
+			t.Errorf("expected 1 of 3 subtasks done, but got %+v", tree.Progress)
+		}
+		if len(tree.Subtasks) != 2 || tree.Subtasks[0].Task.Id != first.Id {
+			t.Errorf("expected 2 subtasks starting with %s, but got %+v", first.Id, tree.Subtasks)
+			return
+		}
+		if tree.Subtasks[0].Progress != (services.TaskProgress{Done: 1, Total: 1}) {
+			t.Errorf("expected 1 of 1 subtasks done, but got %+v", tree.Subtasks[0].Progress)
+		}
+	})
+	t.Run("open subtasks block completing the parent", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		newSubtask(ts, parent.Id)
+		done := task.StatusDone
+
+		_, err := ts.UpdateTask(owner, parent.Id, nil, services.UpdateTaskData{Status: &done})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "status" {
+			t.Errorf("expected invalid status, but got %v", err)
+		}
+	})
+	t.Run("cascade completes the open subtasks", func(t *testing.T) {
+		ts := &TaskService{taskRepository: NewMockTaskRepository(), completion: task.CompletionCascade}
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		subtask, _ := newSubtask(ts, parent.Id)
+		grandchild, _ := newSubtask(ts, subtask.Id)
+		completed := true
+
+		got, err := ts.UpdateTask(owner, parent.Id, nil, services.UpdateTaskData{IsCompleted: &completed})
+
+		if err != nil || got.Status != task.StatusDone {
+			t.Errorf("expected parent to be done, but got %+v, %v", got, err)
+			return
+		}
+		for _, id := range []string{subtask.Id, grandchild.Id} {
+			if got, _ := ts.GetTask(owner, id); got.Status != task.StatusDone {
+				t.Errorf("expected subtask %s to be done, but got %s", id, got.Status)
+			}
+		}
+	})
+	t.Run("cascade goes on with the next occurrence of a recurring subtask", func(t *testing.T) {
+		ts := &TaskService{taskRepository: NewMockTaskRepository(), completion: task.CompletionCascade}
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		rule, dueAt := "FREQ=DAILY", time.Now().Add(time.Hour)
+		data := newTask("Subtask", "Subtask description")
+		data.ParentId, data.Recurrence, data.DueAt = &parent.Id, &rule, &dueAt
+		subtask, _ := ts.CreateTask(owner, data)
+		completed := true
+
+		_, err := ts.UpdateTask(owner, parent.Id, nil, services.UpdateTaskData{IsCompleted: &completed})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		series, _ := ts.seriesOf(owner, subtask.Id)
+		if len(series) != 2 || series[0].Status != task.StatusDone || series[1].Status == task.StatusDone || !series[1].DueAt.Equal(dueAt.AddDate(0, 0, 1)) {
+			t.Errorf("expected the subtask done and its next occurrence open, but got %+v", series)
+		}
+	})
+	t.Run("cascade stops at a blocked subtask", func(t *testing.T) {
+		ts := &TaskService{taskRepository: NewMockTaskRepository(), completion: task.CompletionCascade}
+		parent, _ := ts.CreateTask(owner, newTask("Parent task", "Parent task description"))
+		open, _ := newSubtask(ts, parent.Id)
+		blocked, _ := newSubtask(ts, parent.Id)
+		status, done := task.StatusBlocked, task.StatusDone
+		ts.UpdateTask(owner, blocked.Id, nil, services.UpdateTaskData{Status: &status})
+
+		_, err := ts.UpdateTask(owner, parent.Id, nil, services.UpdateTaskData{Status: &done})
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Errors[0].Field != "status" {
+			t.Errorf("expected invalid status, but got %v", err)
+		}
+		for _, id := range []string{parent.Id, open.Id} {
+			if got, _ := ts.GetTask(owner, id); got.Status == task.StatusDone {
+				t.Errorf("expected task %s to stay open", id)
+			}
+		}
+	})
+}
+
+func TestTaskDependencies(t *testing.T) {
+	t.Run("open blockers keep the task from being done", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		blocked, _ := ts.CreateTask(owner, newTask("Deploy", "Deploy the release"))
+		first, _ := ts.CreateTask(owner, newTask("Build", "Build the release"))
+		second, _ := ts.CreateTask(owner, newTask("Test", "Test the release"))
+		ts.AddDependency(owner, blocked.Id, first.Id)
+		ts.AddDependency(owner, blocked.Id, second.Id)
+		done := task.StatusDone
+		ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{Status: &done})
+
+		_, err := ts.UpdateTask(owner, blocked.Id, nil, services.UpdateTaskData{Status: &done})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || len(appError.Errors) != 1 {
+			t.Errorf("expected one open blocker, but got %v", err)
+			return
+		}
+		if appError.Errors[0].Field != "blocked_by" || !strings.Contains(appError.Errors[0].Reason, second.Id) {
+			t.Errorf("expected blocker %s, but got %+v", second.Id, appError.Errors[0])
+		}
+	})
+	t.Run("done blockers let the task be done", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		blocked, _ := ts.CreateTask(owner, newTask("Deploy", "Deploy the release"))
+		blocker, _ := ts.CreateTask(owner, newTask("Build", "Build the release"))
+		ts.AddDependency(owner, blocked.Id, blocker.Id)
+		completed := true
+		ts.UpdateTask(owner, blocker.Id, nil, services.UpdateTaskData{IsCompleted: &completed})
+
+		got, err := ts.UpdateTask(owner, blocked.Id, nil, services.UpdateTaskData{IsCompleted: &completed})
+
+		if err != nil || got.Status != task.StatusDone {
+			t.Errorf("expected task to be done, but got %+v, %v", got, err)
+		}
+	})
+	t.Run("dependency can't make a cycle", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		a, _ := ts.CreateTask(owner, newTask("Task A", "Task A description"))
+		b, _ := ts.CreateTask(owner, newTask("Task B", "Task B description"))
+		c, _ := ts.CreateTask(owner, newTask("Task C", "Task C description"))
+		ts.AddDependency(owner, b.Id, a.Id)
+		ts.AddDependency(owner, c.Id, b.Id)
+
+		for _, blockerId := range []string{a.Id, c.Id} {
+			_, err := ts.AddDependency(owner, a.Id, blockerId)
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "blocker_id" {
+				t.Errorf("expected invalid blocker_id %s, but got %v", blockerId, err)
+			}
+		}
+	})
+	t.Run("blockers and blocked tasks are listed", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		blocked, _ := ts.CreateTask(owner, newTask("Deploy", "Deploy the release"))
+		blocker, _ := ts.CreateTask(owner, newTask("Build", "Build the release"))
+
+		blockers, err := ts.AddDependency(owner, blocked.Id, blocker.Id)
+		if err != nil || len(blockers.Tasks) != 1 || blockers.Tasks[0].Id != blocker.Id {
+			t.Errorf("expected blocker %s, but got %+v, %v", blocker.Id, blockers, err)
+		}
+		blocking, _ := ts.GetBlocking(owner, blocker.Id)
+		if len(blocking.Tasks) != 1 || blocking.Tasks[0].Id != blocked.Id {
+			t.Errorf("expected blocked task %s, but got %+v", blocked.Id, blocking)
+		}
+		blockers, err = ts.RemoveDependency(owner, blocked.Id, blocker.Id)
+		if err != nil || len(blockers.Tasks) != 0 {
+			t.Errorf("expected no blockers, but got %+v, %v", blockers, err)
+		}
+		_, err = ts.RemoveDependency(owner, blocked.Id, blocker.Id)
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected dependency not found, but got %v", err)
+		}
+	})
+	t.Run("plan puts every task after its open blockers", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		deploy, _ := ts.CreateTask(owner, newTask("Deploy", "Deploy the release"))
+		test, _ := ts.CreateTask(owner, newTask("Test", "Test the release"))
+		build, _ := ts.CreateTask(owner, newTask("Build", "Build the release"))
+		docs, _ := ts.CreateTask(owner, newTask("Docs", "Write the release notes"))
+		ts.AddDependency(owner, deploy.Id, test.Id)
+		ts.AddDependency(owner, test.Id, build.Id)
+		ts.AddDependency(owner, deploy.Id, docs.Id)
+
+		plan, err := ts.GetPlan(owner)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		ids := []string{}
+		for _, planned := range plan.Tasks {
+			ids = append(ids, planned.Task.Id)
+		}
+		want := []string{build.Id, test.Id, docs.Id, deploy.Id}
+		if fmt.Sprint(ids) != fmt.Sprint(want) {
+			t.Errorf("expected plan %v, but got %v", want, ids)
+		}
+		if len(plan.Tasks[3].BlockedBy) != 2 {
+			t.Errorf("expected deploy to wait on 2 tasks, but got %v", plan.Tasks[3].BlockedBy)
+		}
+	})
+}
+
+func TestTaskRecurrence(t *testing.T) {
+	recurring := func(rule string, dueAt time.Time) services.CreateTaskData {
+		data := newTask("Standup", "Daily standup notes")
+		data.Recurrence = &rule
+		data.DueAt = &dueAt
+		return data
+	}
+
+	t.Run("rules give the next dates", func(t *testing.T) {
+		date := func(year int, month time.Month, day int) time.Time {
+			return time.Date(year, month, day, 9, 0, 0, 0, time.UTC)
+		}
+		tests := []struct {
+			rule  string
+			start time.Time
+			want  []time.Time
+			// ends tells that the rule gives no date after the wanted ones
+			ends bool
+		}{
+			{"FREQ=WEEKLY;BYDAY=MO,TH", date(2025, 1, 6), []time.Time{date(2025, 1, 9), date(2025, 1, 13), date(2025, 1, 16)}, false},
+			{"FREQ=MONTHLY;BYMONTHDAY=31", date(2025, 1, 31), []time.Time{date(2025, 3, 31), date(2025, 5, 31)}, false},
+			{"RRULE:FREQ=MONTHLY;BYDAY=-1FR", date(2025, 1, 31), []time.Time{date(2025, 2, 28), date(2025, 3, 28)}, false},
+			{"FREQ=DAILY;INTERVAL=2;UNTIL=20250105", date(2025, 1, 1), []time.Time{date(2025, 1, 3), date(2025, 1, 5)}, true},
+			{"FREQ=YEARLY", date(2024, 2, 29), []time.Time{date(2028, 2, 29)}, false},
+		}
+
+		for _, test := range tests {
+			rule, err := task.ParseRecurrence(test.rule)
+			if err != nil {
+				t.Errorf("expected rule %s to parse, but got %v", test.rule, err)
+				continue
+			}
+			got := []time.Time{}
+			for next := rule.Next(test.start); next != nil && len(got) < len(test.want)+1; next = rule.Next(*next) {
+				got = append(got, *next)
+			}
+			if test.ends && len(got) != len(test.want) {
+				t.Errorf("expected rule %s to end after %d dates, but got %v", test.rule, len(test.want), got)
+				continue
+			}
+			for i, want := range test.want {
+				if i >= len(got) || !got[i].Equal(want) {
+					t.Errorf("expected rule %s to give %v, but got %v", test.rule, test.want, got)
+					break
+				}
+			}
+		}
+	})
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		dueAt := time.Now().Add(time.Hour)
+
+		for _, rule := range []string{"FREQ=HOURLY", "BYDAY=MO", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;BYMONTHDAY=1", "FREQ=WEEKLY;BYDAY=XX"} {
+			_, err := ts.CreateTask(owner, recurring(rule, dueAt))
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "recurrence" {
+				t.Errorf("expected invalid recurrence %s, but got %v", rule, err)
+			}
+		}
+	})
+	t.Run("recurring task needs a due date", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		data := newTask("Standup", "Daily standup notes")
+		rule := "FREQ=DAILY"
+		data.Recurrence = &rule
+
+		_, err := ts.CreateTask(owner, data)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "due_at" {
+			t.Errorf("expected invalid due_at, but got %v", err)
+		}
+	})
+	t.Run("completing an occurrence creates the next one", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		dueAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		first, _ := ts.CreateTask(owner, recurring("RRULE:FREQ=DAILY;COUNT=2", dueAt))
+		done := task.StatusDone
+
+		ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{Status: &done})
+
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if len(page.Tasks) != 2 {
+			t.Errorf("expected 2 occurrences, but got %d", len(page.Tasks))
+			return
+		}
+		next := page.Tasks[1]
+		if next.SeriesId == nil || *next.SeriesId != first.Id || next.Status != task.StatusTodo {
+			t.Errorf("expected open occurrence of series %s, but got %+v", first.Id, next)
+		}
+		if next.DueAt == nil || !next.DueAt.Equal(dueAt.Add(24*time.Hour)) {
+			t.Errorf("expected next due date %v, but got %v", dueAt.Add(24*time.Hour), next.DueAt)
+		}
+		if next.Recurrence == nil || *next.Recurrence != "FREQ=DAILY;COUNT=1" {
+			t.Errorf("expected one occurrence left, but got %v", next.Recurrence)
+		}
+
+		ts.UpdateTask(owner, next.Id, nil, services.UpdateTaskData{Status: &done})
+
+		page, _ = ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if len(page.Tasks) != 2 {
+			t.Errorf("expected the series to end after 2 occurrences, but got %d", len(page.Tasks))
+		}
+	})
+	t.Run("missed occurrences are skipped", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, recurring("FREQ=DAILY", time.Now().Add(-72*time.Hour)))
+		completed := true
+
+		ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{IsCompleted: &completed})
+
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if len(page.Tasks) != 2 || !page.Tasks[1].DueAt.After(time.Now()) {
+			t.Errorf("expected one occurrence due in the future, but got %+v", page.Tasks)
+		}
+	})
+	t.Run("deleting an open occurrence skips it", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, recurring("FREQ=WEEKLY", time.Now().Add(time.Hour)))
+
+		_, err := ts.DeleteTask(owner, first.Id, nil)
+
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if err != nil || len(page.Tasks) != 1 || page.Tasks[0].Id == first.Id {
+			t.Errorf("expected the next occurrence only, but got %+v, %v", page.Tasks, err)
+		}
+	})
+	t.Run("series update changes every occurrence", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, recurring("FREQ=DAILY", time.Now().Add(time.Hour)))
+		done := task.StatusDone
+		ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{Status: &done})
+		title := "Weekly sync"
+
+		got, err := ts.UpdateSeries(owner, first.Id, &first.Version, services.UpdateTaskData{Title: &title})
+
+		if err == nil {
+			t.Errorf("expected a stale version to be rejected")
+		}
+		got, err = ts.UpdateSeries(owner, first.Id, nil, services.UpdateTaskData{Title: &title})
+		if err != nil || got.Id != first.Id {
+			t.Errorf("expected task %s to be updated, but got %+v, %v", first.Id, got, err)
+			return
+		}
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		for _, occurrence := range page.Tasks {
+			if occurrence.Title != title {
+				t.Errorf("expected every occurrence to be renamed, but got %s", occurrence.Title)
+			}
+		}
+	})
+	t.Run("recurring task due date can't be cleared", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, recurring("FREQ=DAILY", time.Now().Add(time.Hour)))
+
+		_, err := ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{ClearDueAt: true})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "due_at" {
+			t.Errorf("expected invalid due_at, but got %v", err)
+		}
+		stop := ""
+		updated, err := ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{ClearDueAt: true, Recurrence: &stop})
+		if err != nil || updated.DueAt != nil || updated.Recurrence != nil {
+			t.Errorf("expected the series to stop without a due date, but got %+v, %v", updated, err)
+		}
+	})
+	t.Run("series update can't change one occurrence fields", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, recurring("FREQ=DAILY", time.Now().Add(time.Hour)))
+		dueAt := time.Now()
+
+		_, err := ts.UpdateSeries(owner, first.Id, nil, services.UpdateTaskData{DueAt: &dueAt})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "due_at" {
+			t.Errorf("expected invalid due_at, but got %v", err)
+		}
+	})
+	t.Run("series delete trashes the open occurrences", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		first, _ := ts.CreateTask(owner, recurring("FREQ=DAILY", time.Now().Add(time.Hour)))
+		other, _ := ts.CreateTask(owner, newTask("Review", "Review the pull requests"))
+		done := task.StatusDone
+		ts.UpdateTask(owner, first.Id, nil, services.UpdateTaskData{Status: &done})
+
+		_, err := ts.DeleteSeries(owner, first.Id, nil)
+
+		page, _ := ts.GetAllTasks(owner, services.ListTasksQuery{})
+		if err != nil || len(page.Tasks) != 2 || page.Tasks[0].Id != first.Id || page.Tasks[1].Id != other.Id {
+			t.Errorf("expected tasks %s and %s to be left, but got %+v, %v", first.Id, other.Id, page.Tasks, err)
+		}
+	})
+	t.Run("task that does not recur has no series", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		single, _ := ts.CreateTask(owner, newTask("Review", "Review the pull requests"))
+
+		_, err := ts.DeleteSeries(owner, single.Id, nil)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.INVALID_INPUT || appError.Errors[0].Field != "scope" {
+			t.Errorf("expected invalid scope, but got %v", err)
+		}
+	})
+}
+
+func TestTaskHistory(t *testing.T) {
+	t.Run("every write is in the history", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		title := "Task 1 renamed"
+		ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{Title: &title})
+		ts.SetTaskTags(owner, created.Id, nil, []string{"work"})
+		ts.DeleteTask(owner, created.Id, nil)
+		ts.RestoreTask(owner, created.Id)
+
+		page, err := ts.GetTaskHistory(owner, created.Id, services.EventsQuery{})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		actions := []string{}
+		for _, event := range page.Events {
+			actions = append(actions, event.Action)
+			if event.ActorId != owner {
+				t.Errorf("expected actor %s, but got %s", owner, event.ActorId)
+			}
+		}
+		want := []string{task.ActionCreate, task.ActionUpdate, task.ActionUpdate, task.ActionDelete, task.ActionRestore}
+		if fmt.Sprint(actions) != fmt.Sprint(want) {
+			t.Errorf("expected actions %v, but got %v", want, actions)
+			return
+		}
+		changes := page.Events[1].Changes
+		if len(changes) != 1 || changes[0].Field != "Title" || changes[0].From != "Task 1" || changes[0].To != title {
+			t.Errorf("expected title change, but got %+v", changes)
+		}
+		changes = page.Events[2].Changes
+		if len(changes) != 1 || changes[0].Field != "Tags" || changes[0].From != nil {
+			t.Errorf("expected tags change, but got %+v", changes)
+		}
+	})
+	t.Run("writes are recorded as made by the actor", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		actor := ts.WithActor("support-user")
+		created, _ := actor.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		title := "Task 1 renamed"
+		actor.RunBatch(owner, services.Batch{Operations: []services.BatchOperation{
+			{Op: services.BatchUpdate, TaskId: created.Id, Data: services.UpdateTaskData{Title: &title}},
+		}})
+		ts.DeleteTask(owner, created.Id, nil)
+
+		page, _ := ts.GetTaskHistory(owner, created.Id, services.EventsQuery{})
+
+		actors := []string{}
+		for _, event := range page.Events {
+			actors = append(actors, event.ActorId)
+		}
+		want := []string{"support-user", "support-user", owner}
+		if fmt.Sprint(actors) != fmt.Sprint(want) {
+			t.Errorf("expected actors %v, but got %v", want, actors)
+		}
+	})
+	t.Run("failed write is not in the history", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		title, stale := "Task 1 renamed", created.Version+1
+
+		ts.UpdateTask(owner, created.Id, &stale, services.UpdateTaskData{Title: &title})
+
+		page, _ := ts.GetTaskHistory(owner, created.Id, services.EventsQuery{})
+		if len(page.Events) != 1 {
+			t.Errorf("expected only the create event, but got %+v", page.Events)
+		}
+	})
+	t.Run("history of a missing task fail", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+
+		_, err := ts.GetTaskHistory("other-owner", created.Id, services.EventsQuery{})
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected task not found, but got %v", err)
+		}
+	})
+	t.Run("history of a task without events is empty", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		ts, _ := NewTaskService(repo)
+		repo.Insert(owner, "task-1", "Task 1", "Task 1 description", task.Details{})
+
+		page, err := ts.GetTaskHistory(owner, "task-1", services.EventsQuery{})
+
+		if err != nil || page == nil || len(page.Events) != 0 {
+			t.Errorf("expected an empty history, but got %+v, %v", page, err)
+		}
+	})
+	t.Run("audit log filters by actor and time", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		ts.CreateTask("other-owner", newTask("Task 2", "Task 2 description"))
+		ts.CreateTask(owner, newTask("Task 3", "Task 3 description"))
+		past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+		page, err := ts.GetAuditLog(services.EventsQuery{ActorId: owner, From: &past, Limit: 1})
+		if err != nil || len(page.Events) != 1 || page.NextCursor == nil {
+			t.Errorf("expected a page of 1 event and a cursor, but got %+v, %v", page, err)
+			return
+		}
+		page, _ = ts.GetAuditLog(services.EventsQuery{ActorId: owner, From: &past, Limit: 1, Cursor: *page.NextCursor})
+		if len(page.Events) != 1 || page.NextCursor != nil || page.Events[0].ActorId != owner {
+			t.Errorf("expected the last event of %s, but got %+v", owner, page)
+		}
+		page, _ = ts.GetAuditLog(services.EventsQuery{From: &future})
+		if len(page.Events) != 0 {
+			t.Errorf("expected no event in the future, but got %+v", page.Events)
+		}
+		_, err = ts.GetAuditLog(services.EventsQuery{From: &future, To: &past})
+		if appError, ok := err.(*errors.AppError); !ok || appError.Errors[0].Field != "to" {
+			t.Errorf("expected invalid to, but got %v", err)
+		}
+	})
+}
+
+func TestTaskChanges(t *testing.T) {
+	t.Run("writes are published once they commit", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		subscription := ts.SubscribeTaskChanges(owner, 0)
+		defer subscription.Close()
+		other := ts.SubscribeTaskChanges("other-owner", 0)
+		defer other.Close()
+
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		title, stale := "Task 1 renamed", created.Version+1
+		ts.UpdateTask(owner, created.Id, &stale, services.UpdateTaskData{Title: &title})
+
+		change := <-subscription.Changes()
+		if change.Type != events.Created || change.Task == nil || change.Task.Id != created.Id {
+			t.Errorf("expected task %s to be created, but got %+v", created.Id, change)
+		}
+		if len(subscription.Changes()) != 0 || len(other.Changes()) != 0 {
+			t.Errorf("expected the failed update and other owners to get nothing")
+		}
+	})
+	t.Run("changes of a shared storage are published as it tells them", func(t *testing.T) {
+		repo := &mockNotifyingRepository{mockTaskRepository: NewMockTaskRepository()}
+		ts, _ := NewTaskService(repo)
+		subscription := ts.SubscribeTaskChanges(owner, 0)
+		defer subscription.Close()
+
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		if len(subscription.Changes()) != 0 {
+			t.Errorf("expected the write to wait for the storage, but got %d changes", len(subscription.Changes()))
+		}
+		repo.notifications = []task.Notification{
+			{TaskId: created.Id, OwnerId: owner, Action: task.ActionCreate},
+			{TaskId: "missing-task", OwnerId: owner, Action: task.ActionUpdate},
+			{TaskId: created.Id, OwnerId: owner, Action: task.ActionDelete},
+		}
+
+		err := ts.FollowChanges(context.Background())
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		first, second := <-subscription.Changes(), <-subscription.Changes()
+		if first.Type != events.Created || first.Task == nil || first.Task.Id != created.Id {
+			t.Errorf("expected task %s to be created, but got %+v", created.Id, first)
+		}
+		if second.Type != events.Deleted || second.Task != nil || second.TaskId != created.Id {
+			t.Errorf("expected task %s to be deleted, but got %+v", created.Id, second)
+		}
+		if len(subscription.Changes()) != 0 {
+			t.Errorf("expected the missing task to be skipped")
+		}
+	})
+	t.Run("a storage that can't tell the changes fails to follow them", func(t *testing.T) {
+		listenError := fmt.Errorf("permission denied for LISTEN")
+		ts, _ := NewTaskService(&mockNotifyingRepository{mockTaskRepository: NewMockTaskRepository(), listenError: listenError})
+
+		err := ts.FollowChanges(context.Background())
+
+		if err != listenError {
+			t.Errorf("expected the listen error, but got %v", err)
+		}
+	})
+	t.Run("lost notifications reset the subscribers", func(t *testing.T) {
+		ts, _ := NewTaskService(&mockNotifyingRepository{mockTaskRepository: NewMockTaskRepository(), reset: true})
+		subscription := ts.SubscribeTaskChanges(owner, 0)
+
+		ts.FollowChanges(context.Background())
+
+		if _, ok := <-subscription.Changes(); ok {
+			t.Errorf("expected the subscription to be closed")
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	secret := "webhook-test-secret"
+	newWebhook := func(url string, events ...string) services.CreateWebhookData {
+		return services.CreateWebhookData{URL: &url, Events: events, Secret: &secret}
+	}
+	// receiver answers every delivery with status and keeps the requests
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	receiver := func(t *testing.T, status int) (*httptest.Server, *[]received) {
+		requests := []received{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, received{r.Header, body})
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+		return server, &requests
+	}
+	// localService delivers to the receivers, which listen on loopback
+	localService := func(repo *mockTaskRepository) *TaskService {
+		ts, _ := NewTaskService(repo)
+		ts.webhookPrivate = true
+		ts.webhookClient = newWebhookClient(ts.webhookClient.Timeout, true)
+		return ts
+	}
+
+	t.Run("create validates the subscription", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		short := "short"
+
+		for _, test := range []struct {
+			data  services.CreateWebhookData
+			field string
+		}{
+			{newWebhook("ftp://example.com/hook", task.WebhookCreated), "url"},
+			{newWebhook("/hook", task.WebhookCreated), "url"},
+			{newWebhook("http://example.com/hook"), "events"},
+			{newWebhook("http://example.com/hook", "task.archived"), "events"},
+			{services.CreateWebhookData{URL: newWebhook("http://example.com/hook").URL, Events: []string{task.WebhookCreated}, Secret: &short}, "secret"},
+		} {
+			_, err := ts.CreateWebhook(owner, test.data)
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || len(appError.Errors) != 1 || appError.Errors[0].Field != test.field {
+				t.Errorf("expected an error on %s, but got %v", test.field, err)
+			}
+		}
+		webhook, err := ts.CreateWebhook(owner, newWebhook("http://example.com/hook", task.WebhookCreated, task.WebhookCreated))
+		if err != nil || len(webhook.Events) != 1 {
+			t.Errorf("expected the events to be deduplicated, but got %v and %v", webhook, err)
+		}
+	})
+	t.Run("webhooks to private addresses are refused", func(t *testing.T) {
+		for _, url := range []string{
+			"http://127.0.0.1:8080/hook",
+			"http://localhost/hook",
+			"http://10.0.0.5/hook",
+			"http://192.168.1.1/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"http://[::1]/hook",
+			"http://[::ffff:127.0.0.1]/hook",
+			"http://0.0.0.0/hook",
+		} {
+			ts, _ := NewTaskService(NewMockTaskRepository())
+
+			_, err := ts.CreateWebhook(owner, newWebhook(url, task.WebhookCreated))
+
+			appError, ok := err.(*errors.AppError)
+			if !ok || len(appError.Errors) != 1 || appError.Errors[0].Field != "url" {
+				t.Errorf("expected an error on url for %s, but got %v", url, err)
+			}
+		}
+
+		repo := NewMockTaskRepository()
+		ts := localService(repo)
+		server, requests := receiver(t, http.StatusNoContent)
+		ts.CreateWebhook(owner, newWebhook(server.URL, task.WebhookCreated))
+		ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		// a public name may resolve to a private address once created
+		ts.webhookClient = newWebhookClient(time.Second, false)
+
+		ts.DeliverWebhooks()
+
+		if len(*requests) != 0 || repo.deliveries[0].LastError == nil || !strings.Contains(*repo.deliveries[0].LastError, "not public") {
+			t.Errorf("expected the delivery to a private address to fail, but got %d requests and %+v", len(*requests), repo.deliveries[0])
+		}
+	})
+	t.Run("writes are delivered signed to the subscribed webhooks", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		ts := localService(repo)
+		server, requests := receiver(t, http.StatusNoContent)
+		webhook, _ := ts.CreateWebhook(owner, newWebhook(server.URL, task.WebhookCreated, task.WebhookCompleted))
+		ts.CreateWebhook("other-owner", newWebhook(server.URL, task.WebhookEvents...))
+
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		completed := true
+		ts.UpdateTask(owner, created.Id, nil, services.UpdateTaskData{IsCompleted: &completed})
+		err := ts.DeliverWebhooks()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(*requests) != 2 {
+			t.Errorf("expected 2 deliveries, but got %d", len(*requests))
+			return
+		}
+		for i, event := range []string{task.WebhookCreated, task.WebhookCompleted} {
+			request := (*requests)[i]
+			if request.header.Get("X-Webhook-Event") != event || request.header.Get("X-Webhook-Id") != webhook.Id {
+				t.Errorf("expected event %s of webhook %s, but got %v", event, webhook.Id, request.header)
+			}
+			signature := webhookSignature(secret, request.header.Get("X-Webhook-Timestamp"), request.body)
+			if request.header.Get("X-Webhook-Signature") != signature {
+				t.Errorf("expected signature %s, but got %s", signature, request.header.Get("X-Webhook-Signature"))
+			}
+			var payload webhookPayload
+			if err := json.Unmarshal(request.body, &payload); err != nil || payload.Event != event || payload.TaskId != created.Id || payload.Task == nil {
+				t.Errorf("expected the payload of %s for task %s, but got %s", event, created.Id, request.body)
+			}
+		}
+		page, _ := ts.GetDeliveries(owner, webhook.Id, services.DeliveriesQuery{})
+		for _, delivery := range page.Deliveries {
+			if delivery.Status != task.DeliveryDelivered || delivery.Attempts != 1 || delivery.DeliveredAt == nil {
+				t.Errorf("expected delivery %d to be delivered, but got %+v", delivery.Id, delivery)
+			}
+		}
+	})
+	t.Run("failed deliveries are retried with backoff until the last attempt", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		ts := localService(repo)
+		ts.webhookAttempts, ts.webhookBackoff = 2, time.Minute
+		server, requests := receiver(t, http.StatusInternalServerError)
+		ts.CreateWebhook(owner, newWebhook(server.URL, task.WebhookCreated))
+		ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+
+		ts.DeliverWebhooks()
+		retried := repo.deliveries[0]
+		ts.DeliverWebhooks()
+
+		if len(*requests) != 1 {
+			t.Errorf("expected the retry to wait for the backoff, but got %d requests", len(*requests))
+		}
+		if retried.Status != task.DeliveryPending || retried.Attempts != 1 || retried.ResponseStatus == nil || *retried.ResponseStatus != http.StatusInternalServerError {
+			t.Errorf("expected a pending delivery with one failed attempt, but got %+v", retried)
+		}
+		if wait := time.Until(retried.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+			t.Errorf("expected the next attempt in a minute, but got %v", wait)
+		}
+
+		repo.deliveries[0].NextAttemptAt = time.Now()
+		ts.DeliverWebhooks()
+
+		failed := repo.deliveries[0]
+		if len(*requests) != 2 || failed.Status != task.DeliveryFailed || failed.Attempts != 2 || failed.LastError == nil {
+			t.Errorf("expected the delivery to fail after 2 attempts, but got %+v", failed)
+		}
+	})
+	t.Run("deliveries of a deleted webhook fail without stopping the others", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		ts := localService(repo)
+		server, requests := receiver(t, http.StatusNoContent)
+		deleted, _ := ts.CreateWebhook(owner, newWebhook(server.URL, task.WebhookCreated))
+		kept, _ := ts.CreateWebhook(owner, newWebhook(server.URL, task.WebhookCreated))
+		ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+		// the webhook is deleted once its delivery is claimed
+		repo.webhooks = slices.DeleteFunc(repo.webhooks, func(webhook task.Webhook) bool { return webhook.Id == deleted.Id })
+
+		err := ts.DeliverWebhooks()
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(*requests) != 1 || (*requests)[0].header.Get("X-Webhook-Id") != kept.Id {
+			t.Errorf("expected the delivery of webhook %s, but got %d requests", kept.Id, len(*requests))
+		}
+		for _, delivery := range repo.deliveries {
+			if delivery.WebhookId == deleted.Id && (delivery.Status != task.DeliveryFailed || delivery.LastError == nil) {
+				t.Errorf("expected the delivery of the deleted webhook to fail, but got %+v", delivery)
+			}
+		}
+	})
+	t.Run("a failed write leaves nothing in the outbox", func(t *testing.T) {
+		repo := NewMockTaskRepository()
+		ts, _ := NewTaskService(repo)
+		ts.CreateWebhook(owner, newWebhook("http://example.com/hook", task.WebhookEvents...))
+		created, _ := ts.CreateTask(owner, newTask("Task 1", "Task 1 description"))
+
+		title, stale := "Task 1 renamed", created.Version+1
+		ts.UpdateTask(owner, created.Id, &stale, services.UpdateTaskData{Title: &title})
+
+		if len(repo.deliveries) != 1 || repo.deliveries[0].Event != task.WebhookCreated {
+			t.Errorf("expected only the created delivery, but got %+v", repo.deliveries)
+		}
+	})
+	t.Run("backoff doubles up to its bound", func(t *testing.T) {
+		for attempt, expected := range map[int]time.Duration{
+			1:  time.Minute,
+			2:  2 * time.Minute,
+			4:  8 * time.Minute,
+			20: maxWebhookBackoff,
+		} {
+			if got := webhookBackoff(time.Minute, attempt); got != expected {
+				t.Errorf("expected backoff %v after attempt %d, but got %v", expected, attempt, got)
+			}
+		}
+	})
+	t.Run("the delivery log is paged from the latest", func(t *testing.T) {
+		ts, _ := NewTaskService(NewMockTaskRepository())
+		webhook, _ := ts.CreateWebhook(owner, newWebhook("http://example.com/hook", task.WebhookCreated))
+		for i := range 3 {
+			ts.CreateTask(owner, newTask(fmt.Sprintf("Task %d", i), "Task description"))
+		}
+
+		first, _ := ts.GetDeliveries(owner, webhook.Id, services.DeliveriesQuery{Limit: 2})
+		second, _ := ts.GetDeliveries(owner, webhook.Id, services.DeliveriesQuery{Limit: 2, Cursor: *first.NextCursor})
+		_, err := ts.GetDeliveries("other-owner", webhook.Id, services.DeliveriesQuery{})
+
+		if len(first.Deliveries) != 2 || first.Deliveries[0].Id != 3 || len(second.Deliveries) != 1 || second.NextCursor != nil {
+			t.Errorf("expected pages of 2 and 1 deliveries from the latest, but got %+v and %+v", first, second)
+		}
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected the webhook of another owner to be not found, but got %v", err)
+		}
+	})
+}
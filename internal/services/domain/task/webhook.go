@@ -0,0 +1,429 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/Arup3201/gotasks/internal/services"
+	"github.com/google/uuid"
+)
+
+const (
+	minWebhookSecret = 16
+	// deliveryBatch is how many deliveries are claimed at once
+	deliveryBatch = 10
+	// maxWebhookBackoff bounds the wait between two attempts of a delivery
+	maxWebhookBackoff = 6 * time.Hour
+)
+
+// webhookPayload is the body of a delivery, Task is nil for a deleted task.
+type webhookPayload struct {
+	Event      string     `json:"event"`
+	TaskId     string     `json:"task_id"`
+	Task       *task.Task `json:"task"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// webhookEvents are the event types of a write, an update that completes
+// the task is a completion too.
+func webhookEvents(action string, changes []task.Change) []string {
+	switch action {
+	case task.ActionCreate:
+		return []string{task.WebhookCreated}
+	case task.ActionDelete:
+		return []string{task.WebhookDeleted}
+	case task.ActionRestore:
+		return []string{task.WebhookRestored}
+	}
+
+	events := []string{task.WebhookUpdated}
+	for _, change := range changes {
+		if change.Field == "IsCompleted" && change.To == true {
+			events = append(events, task.WebhookCompleted)
+		}
+	}
+	return events
+}
+
+// enqueueDeliveries puts a delivery in the outbox for every webhook of the
+// owner that subscribes to the events of the write, in the transaction of
+// the write, so that a task change and its deliveries are kept together.
+func (repo auditedRepository) enqueueDeliveries(ownerId, taskId, action string, changes []task.Change, t *task.Task) error {
+	webhooks, err := repo.TaskRepository.ListWebhooks(ownerId)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	for _, event := range webhookEvents(action, changes) {
+		payload, err := json.Marshal(webhookPayload{
+			Event:      event,
+			TaskId:     taskId,
+			Task:       t,
+			OccurredAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		for _, webhook := range webhooks {
+			if !webhook.Subscribes(event) {
+				continue
+			}
+			err := repo.TaskRepository.AddDelivery(task.Delivery{
+				WebhookId:     webhook.Id,
+				OwnerId:       ownerId,
+				Event:         event,
+				TaskId:        taskId,
+				Payload:       payload,
+				Status:        task.DeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ts *TaskService) CreateWebhook(ownerId string, data services.CreateWebhookData) (*task.Webhook, error) {
+	if data.URL == nil {
+		return nil, errors.InputValidationError("Invalid webhook value", "Webhook property 'url' is invalid", errors.AppErrorField{
+			Field:  "url",
+			Reason: "Webhook 'url' can't be empty",
+		})
+	}
+	target, err := url.Parse(*data.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.InputValidationError("Invalid webhook value", "Webhook property 'url' is invalid", errors.AppErrorField{
+			Field:  "url",
+			Reason: "Webhook 'url' must be an absolute http or https URL",
+		})
+	}
+	if !ts.webhookPrivate && !publicHost(target.Hostname()) {
+		return nil, errors.InputValidationError("Invalid webhook value", "Webhook property 'url' is invalid", errors.AppErrorField{
+			Field:  "url",
+			Reason: "Webhook 'url' can't be a loopback, private or link-local address",
+		})
+	}
+
+	if len(data.Events) == 0 {
+		return nil, errors.InputValidationError("Invalid webhook value", "Webhook property 'events' is invalid", errors.AppErrorField{
+			Field:  "events",
+			Reason: "Webhook 'events' can't be empty",
+		})
+	}
+	events := []string{}
+	for _, event := range data.Events {
+		if !slices.Contains(task.WebhookEvents, event) {
+			return nil, errors.InputValidationError("Invalid webhook value", "Webhook property 'events' is invalid", errors.AppErrorField{
+				Field:  "events",
+				Reason: fmt.Sprintf("Webhook 'events' must be some of %s", strings.Join(task.WebhookEvents, "/")),
+			})
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+
+	if data.Secret == nil || len(*data.Secret) < minWebhookSecret {
+		return nil, errors.InputValidationError("Invalid webhook value", "Webhook property 'secret' is invalid", errors.AppErrorField{
+			Field:  "secret",
+			Reason: fmt.Sprintf("Webhook 'secret' must hold at least %d characters", minWebhookSecret),
+		})
+	}
+
+	webhookId, err := uuid.NewUUID()
+	if err != nil {
+		return nil, err
+	}
+	webhook := task.Webhook{
+		Id:        webhookId.String(),
+		OwnerId:   ownerId,
+		URL:       *data.URL,
+		Events:    events,
+		Secret:    *data.Secret,
+		CreatedAt: time.Now(),
+	}
+	if err := ts.taskRepository.AddWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (ts *TaskService) GetWebhooks(ownerId string) (*services.WebhookList, error) {
+	webhooks, err := ts.taskRepository.ListWebhooks(ownerId)
+	if err != nil {
+		return nil, err
+	}
+	return &services.WebhookList{
+		Webhooks: webhooks,
+	}, nil
+}
+
+// DeleteWebhook removes the webhook with its delivery log, the deliveries
+// that are still pending are dropped.
+func (ts *TaskService) DeleteWebhook(ownerId, webhookId string) (*string, error) {
+	if err := ts.taskRepository.DeleteWebhook(ownerId, webhookId); err != nil {
+		return nil, err
+	}
+	return &webhookId, nil
+}
+
+// GetDeliveries lists the deliveries of a webhook from the latest.
+func (ts *TaskService) GetDeliveries(ownerId, webhookId string, query services.DeliveriesQuery) (*services.DeliveryPage, error) {
+	if _, err := ts.taskRepository.GetWebhook(ownerId, webhookId); err != nil {
+		return nil, err
+	}
+
+	options := task.DeliveryOptions{
+		Limit: query.Limit,
+	}
+	if query.Status != "" {
+		if !slices.Contains([]string{task.DeliveryPending, task.DeliveryDelivered, task.DeliveryFailed}, query.Status) {
+			return nil, errors.InputValidationError("Invalid list option", "Delivery list option 'status' is invalid", errors.AppErrorField{
+				Field:  "status",
+				Reason: "Delivery 'status' must be pending/delivered/failed",
+			})
+		}
+		options.Status = &query.Status
+	}
+	if options.Limit == 0 {
+		options.Limit = defaultPageLimit
+	}
+	if options.Limit < 0 || options.Limit > maxPageLimit {
+		return nil, errors.InputValidationError("Invalid list option", "Delivery list option 'limit' is invalid", errors.AppErrorField{
+			Field:  "limit",
+			Reason: fmt.Sprintf("Delivery 'limit' must be between 1 and %d", maxPageLimit),
+		})
+	}
+	if query.Cursor != "" {
+		before, ok := decodeEventCursor(query.Cursor)
+		if !ok {
+			return nil, errors.InputValidationError("Invalid list option", "Delivery list option 'cursor' is invalid", errors.AppErrorField{
+				Field:  "cursor",
+				Reason: "Delivery 'cursor' is malformed",
+			})
+		}
+		options.Before = before
+	}
+
+	// one extra delivery tells whether there is a next page
+	pageLimit := options.Limit
+	options.Limit++
+	deliveries, err := ts.taskRepository.ListDeliveries(ownerId, webhookId, options)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &services.DeliveryPage{
+		Deliveries: deliveries,
+	}
+	if page.Deliveries == nil {
+		page.Deliveries = []task.Delivery{}
+	}
+	if len(page.Deliveries) > pageLimit {
+		page.Deliveries = page.Deliveries[:pageLimit]
+		nextCursor := encodeEventCursor(page.Deliveries[pageLimit-1].Id)
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
+}
+
+// RunWebhooks sends the due deliveries of the outbox right away and then
+// every interval until ctx is done, a failed run is logged and retried on
+// the next tick.
+func (ts *TaskService) RunWebhooks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ts.DeliverWebhooks(); err != nil {
+			log.Printf("webhook delivery error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverWebhooks sends the deliveries of the outbox that are due, until
+// none is left. A delivery that fails is tried again after a backoff that
+// doubles with every attempt, and given up after the last attempt. A
+// delivery that can't be handled is logged and skipped, it is claimed again
+// once its lease is over.
+func (ts *TaskService) DeliverWebhooks() error {
+	// a claimed batch stays out of the outbox while every delivery of it
+	// may take the whole timeout
+	lease := time.Duration(deliveryBatch+1) * ts.webhookClient.Timeout
+	for {
+		deliveries, err := ts.taskRepository.ClaimDeliveries(time.Now(), lease, deliveryBatch)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			if err := ts.deliver(delivery); err != nil {
+				log.Printf("webhook delivery %d error: %v", delivery.Id, err)
+			}
+		}
+		if len(deliveries) < deliveryBatch {
+			return nil
+		}
+	}
+}
+
+// deliver makes an attempt of the delivery, it fails right away when its
+// webhook was deleted since it was claimed.
+func (ts *TaskService) deliver(delivery task.Delivery) error {
+	webhook, err := ts.taskRepository.GetWebhook(delivery.OwnerId, delivery.WebhookId)
+	if appError, ok := err.(*errors.AppError); ok && appError.Type == errors.NOT_FOUND {
+		lastError := appError.Error()
+		delivery.Status = task.DeliveryFailed
+		delivery.LastError = &lastError
+		return ts.taskRepository.UpdateDelivery(delivery)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := ts.send(webhook, delivery)
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	if err == nil {
+		deliveredAt := time.Now()
+		delivery.Status = task.DeliveryDelivered
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = nil
+	} else {
+		lastError := err.Error()
+		delivery.LastError = &lastError
+		if delivery.Attempts >= ts.webhookAttempts {
+			delivery.Status = task.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(webhookBackoff(ts.webhookBackoff, delivery.Attempts))
+		}
+	}
+
+	return ts.taskRepository.UpdateDelivery(delivery)
+}
+
+// send posts the payload of the delivery to the webhook, signed with its
+// secret. The status of the response is nil when there is none, any status
+// other than 2xx is a failure.
+func (ts *TaskService) send(webhook *task.Webhook, delivery task.Delivery) (*int, error) {
+	request, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Id", webhook.Id)
+	request.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.Id, 10))
+	request.Header.Set("X-Webhook-Event", delivery.Event)
+	request.Header.Set("X-Webhook-Timestamp", timestamp)
+	request.Header.Set("X-Webhook-Signature", webhookSignature(webhook.Secret, timestamp, delivery.Payload))
+
+	response, err := ts.webhookClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	// the connection is only reused once the body is read
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &response.StatusCode, fmt.Errorf("webhook answered with status %d", response.StatusCode)
+	}
+	return &response.StatusCode, nil
+}
+
+// newWebhookClient returns the client of the deliveries. Unless allowPrivate,
+// it refuses to connect to an address that isn't public, so that a webhook
+// can't reach the services next to the API even through a name resolving to
+// them or a redirect.
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(addrPort.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// the address dialed has to be the one of the webhook, not of a proxy
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// publicHost tells whether the host of a webhook URL may be public, a name
+// other than localhost is checked once it is resolved by a delivery.
+func publicHost(host string) bool {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return publicAddress(ip)
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() && !ip.IsUnspecified() && !ip.IsLoopback() && !ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast()
+}
+
+// webhookSignature signs the timestamp and the payload of a delivery, so
+// that a receiver can tell it came from this API and is not replayed.
+func webhookSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is the wait after the failed attempt of a delivery, base
+// after the first and twice as long after every other one.
+func webhookBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for range attempt - 1 {
+		if backoff >= maxWebhookBackoff/2 {
+			return maxWebhookBackoff
+		}
+		backoff *= 2
+	}
+	return min(backoff, maxWebhookBackoff)
+}
@@ -1,18 +1,225 @@
 package services
 
-import "github.com/Arup3201/gotasks/internal/entities/task"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/events"
+)
+
+type CreateTaskData struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	Priority    *string    `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	ParentId    *string    `json:"parent_id"`
+	Recurrence  *string    `json:"recurrence"`
+}
 
 type UpdateTaskData struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	IsCompleted *bool   `json:"is_completed"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	Priority    *string    `json:"priority"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	// ClearDueAt removes the due date of the task, a JSON null 'due_at'
+	// sets it, so a nil DueAt is left out of the JSON.
+	ClearDueAt bool `json:"-"`
+	// ParentId moves the task under another task, an empty one moves it to
+	// the top level.
+	ParentId *string `json:"parent_id"`
+	// Recurrence is the RRULE of a recurring task, an empty one stops the
+	// series after this occurrence.
+	Recurrence *string `json:"recurrence"`
+	// IsCompleted is kept for older clients, true moves the task to done and
+	// false reopens a done task.
+	IsCompleted *bool `json:"is_completed"`
+}
+
+// UnmarshalJSON reads the update like its fields are tagged, a 'due_at' that
+// is null clears the due date.
+func (data *UpdateTaskData) UnmarshalJSON(b []byte) error {
+	type fields UpdateTaskData
+	if err := json.Unmarshal(b, (*fields)(data)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	dueAt, ok := raw["due_at"]
+	data.ClearDueAt = ok && string(dueAt) == "null"
+	return nil
+}
+
+type ListTasksQuery struct {
+	Limit        int
+	Cursor       string
+	SortBy       string
+	Order        string
+	IsCompleted  *bool
+	CreatedAfter *time.Time
+	Tags         []string
+	// TagMatch is "any" or "all" of Tags, "any" when empty.
+	TagMatch string
+	// ParentId keeps the direct subtasks of a task.
+	ParentId *string
+}
+
+type TaskPage struct {
+	Tasks      []task.Task `json:"tasks"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// TaskProgress counts the subtasks at any level below a task, and how many
+// of them are done.
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+type TaskTree struct {
+	Task     task.Task    `json:"task"`
+	Progress TaskProgress `json:"progress"`
+	Subtasks []TaskTree   `json:"subtasks"`
+}
+
+type TaskList struct {
+	Tasks []task.Task `json:"tasks"`
+}
+
+// PlannedTask is an open task with the open tasks that block it.
+type PlannedTask struct {
+	Task      task.Task `json:"task"`
+	BlockedBy []string  `json:"blocked_by"`
+}
+
+// TaskPlan lists the open tasks so that every task comes after the tasks
+// that block it.
+type TaskPlan struct {
+	Tasks []PlannedTask `json:"tasks"`
+}
+
+type TagList struct {
+	Tags []task.TagCount `json:"tags"`
+}
+
+type SearchTasksQuery struct {
+	Query  string
+	Limit  int
+	Cursor string
+}
+
+type SearchPage struct {
+	Tasks      []task.SearchResult `json:"tasks"`
+	NextCursor *string             `json:"next_cursor"`
+}
+
+// EventsQuery filters the change history, an empty ActorId keeps the
+// events of every actor.
+type EventsQuery struct {
+	ActorId string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Cursor  string
+}
+
+type EventPage struct {
+	Events     []task.Event `json:"events"`
+	NextCursor *string      `json:"next_cursor"`
+}
+
+type CreateWebhookData struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+	Secret *string  `json:"secret"`
+}
+
+type WebhookList struct {
+	Webhooks []task.Webhook `json:"webhooks"`
+}
+
+// DeliveriesQuery filters the delivery log of a webhook, an empty Status
+// keeps the deliveries of every status.
+type DeliveriesQuery struct {
+	Status string
+	Limit  int
+	Cursor string
+}
+
+type DeliveryPage struct {
+	Deliveries []task.Delivery `json:"deliveries"`
+	NextCursor *string         `json:"next_cursor"`
+}
+
+// Operations of a batch.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one create, update or delete of a batch. Create reads
+// the fields of Data, update the ones that are set and delete none of them.
+type BatchOperation struct {
+	Op      string         `json:"op"`
+	TaskId  string         `json:"id"`
+	Version *int           `json:"version"`
+	Data    UpdateTaskData `json:"data"`
+}
+
+type Batch struct {
+	// Atomic applies every operation or none of them, otherwise each
+	// operation is applied on its own.
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResult is the outcome of one operation. RolledBack is set on the
+// operations of an atomic batch that were undone, or never run, because
+// another operation failed.
+type BatchResult struct {
+	Op         string
+	TaskId     string
+	Task       *task.Task
+	Err        error
+	RolledBack bool
 }
 
 type ServiceHandler interface {
-	GetAllTasks() ([]task.Task, error)
-	CreateTask(title, description string) (*task.Task, error)
-	GetTask(taskId string) (*task.Task, error)
-	UpdateTask(taskId string, data UpdateTaskData) (*task.Task, error)
-	DeleteTask(taskId string) (*string, error)
-	SearchTasks(query string) ([]task.Task, error)
+	GetAllTasks(ownerId string, query ListTasksQuery) (*TaskPage, error)
+	CreateTask(ownerId string, data CreateTaskData) (*task.Task, error)
+	GetTask(ownerId, taskId string) (*task.Task, error)
+	UpdateTask(ownerId, taskId string, version *int, data UpdateTaskData) (*task.Task, error)
+	DeleteTask(ownerId, taskId string, version *int) (*string, error)
+	UpdateSeries(ownerId, taskId string, version *int, data UpdateTaskData) (*task.Task, error)
+	DeleteSeries(ownerId, taskId string, version *int) (*string, error)
+	SearchTasks(ownerId string, query SearchTasksQuery) (*SearchPage, error)
+	GetTrash(ownerId string, query ListTasksQuery) (*TaskPage, error)
+	RestoreTask(ownerId, taskId string) (*task.Task, error)
+	SetTaskTags(ownerId, taskId string, version *int, tags []string) (*task.Task, error)
+	GetTags(ownerId string) (*TagList, error)
+	PurgeTasks(retention time.Duration) (int64, error)
+	RunBatch(ownerId string, batch Batch) ([]BatchResult, error)
+	GetSubtasks(ownerId, taskId string, query ListTasksQuery) (*TaskPage, error)
+	GetTaskTree(ownerId, taskId string) (*TaskTree, error)
+	AddDependency(ownerId, taskId, blockerId string) (*TaskList, error)
+	RemoveDependency(ownerId, taskId, blockerId string) (*TaskList, error)
+	GetBlockers(ownerId, taskId string) (*TaskList, error)
+	GetBlocking(ownerId, taskId string) (*TaskList, error)
+	GetPlan(ownerId string) (*TaskPlan, error)
+	GetTaskHistory(ownerId, taskId string, query EventsQuery) (*EventPage, error)
+	GetAuditLog(query EventsQuery) (*EventPage, error)
+	SubscribeTaskChanges(ownerId string, lastId int64) *events.Subscription
+	CreateWebhook(ownerId string, data CreateWebhookData) (*task.Webhook, error)
+	GetWebhooks(ownerId string) (*WebhookList, error)
+	DeleteWebhook(ownerId, webhookId string) (*string, error)
+	GetDeliveries(ownerId, webhookId string, query DeliveriesQuery) (*DeliveryPage, error)
+	// WithActor returns the handler with its writes recorded as made by
+	// actorId.
+	WithActor(actorId string) ServiceHandler
 }
@@ -0,0 +1,95 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+)
+
+// AddDependency blocks the task by the blocker, adding it again is a no-op.
+func (mem *MemTaskRepository) AddDependency(ownerId, taskId, blockerId string) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for _, id := range []string{taskId, blockerId} {
+		if t, ok := mem.tasks[id]; !ok || t.OwnerId != ownerId {
+			return errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", id))
+		}
+	}
+
+	dependency := task.Dependency{TaskId: taskId, BlockerId: blockerId}
+	if !slices.Contains(mem.dependencies, dependency) {
+		mem.dependencies = append(mem.dependencies, dependency)
+	}
+	return nil
+}
+
+func (mem *MemTaskRepository) RemoveDependency(ownerId, taskId, blockerId string) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	i := slices.Index(mem.dependencies, task.Dependency{TaskId: taskId, BlockerId: blockerId})
+	if i < 0 || mem.tasks[taskId].OwnerId != ownerId {
+		return errors.NotFoundError(fmt.Sprintf("Task with ID %s is not blocked by %s", taskId, blockerId))
+	}
+	mem.dependencies = slices.Delete(mem.dependencies, i, i+1)
+	return nil
+}
+
+// ListDependencies returns the dependencies between the tasks of the owner
+// that are not in the trash, oldest first.
+func (mem *MemTaskRepository) ListDependencies(ownerId string) ([]task.Dependency, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	dependencies := []task.Dependency{}
+	for _, dependency := range mem.dependencies {
+		t, blocker := mem.tasks[dependency.TaskId], mem.tasks[dependency.BlockerId]
+		if t.OwnerId == ownerId && !t.IsDeleted() && !blocker.IsDeleted() {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies, nil
+}
+
+// Blockers returns the tasks that block the task, by creation.
+func (mem *MemTaskRepository) Blockers(ownerId, taskId string) ([]task.Task, error) {
+	return mem.dependentTasks(ownerId, func(dependency task.Dependency) (string, bool) {
+		return dependency.BlockerId, dependency.TaskId == taskId
+	})
+}
+
+// Blocking returns the tasks that the task blocks, by creation.
+func (mem *MemTaskRepository) Blocking(ownerId, taskId string) ([]task.Task, error) {
+	return mem.dependentTasks(ownerId, func(dependency task.Dependency) (string, bool) {
+		return dependency.TaskId, dependency.BlockerId == taskId
+	})
+}
+
+// dependentTasks returns the live tasks of the owner that pick takes out of
+// the dependencies.
+func (mem *MemTaskRepository) dependentTasks(ownerId string, pick func(dependency task.Dependency) (string, bool)) ([]task.Task, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	ids := map[string]bool{}
+	for _, dependency := range mem.dependencies {
+		if id, ok := pick(dependency); ok {
+			ids[id] = true
+		}
+	}
+
+	tasks := []task.Task{}
+	for _, id := range mem.order {
+		t := mem.tasks[id]
+		if ids[id] && t.OwnerId == ownerId && !t.IsDeleted() {
+			tasks = append(tasks, t)
+		}
+	}
+	slices.SortStableFunc(tasks, func(a, b task.Task) int {
+		return compareTasks(&a, task.SortByCreatedAt, b.SortValue(task.SortByCreatedAt), b.Id)
+	})
+	return tasks, nil
+}
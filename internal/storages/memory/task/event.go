@@ -0,0 +1,32 @@
+package task
+
+import (
+	"github.com/Arup3201/gotasks/internal/entities/task"
+)
+
+func (mem *MemTaskRepository) AddEvent(event task.Event) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	event.Id = int64(len(mem.events) + 1)
+	mem.events = append(mem.events, event)
+	return nil
+}
+
+// ListEvents returns the events that pass the options in the order they
+// happened, they stay after their task is purged.
+func (mem *MemTaskRepository) ListEvents(options task.EventOptions) ([]task.Event, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	events := []task.Event{}
+	for _, event := range mem.events[min(int(options.After), len(mem.events)):] {
+		if options.Limit > 0 && len(events) == options.Limit {
+			break
+		}
+		if options.Matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
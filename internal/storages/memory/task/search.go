@@ -0,0 +1,156 @@
+package task
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+)
+
+// Weights of title and description matches, the same as the default 'A' and
+// 'B' weights Postgres uses to rank the search vector.
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+func (mem *MemTaskRepository) Search(ownerId string, options task.SearchOptions) ([]task.SearchResult, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	results := []task.SearchResult{}
+	for _, id := range mem.order {
+		t := mem.tasks[id]
+		if t.OwnerId != ownerId || t.IsDeleted() {
+			continue
+		}
+
+		title := tokenize(t.Title)
+		description := tokenize(t.Description)
+		titleMatches := title.match(options.Terms)
+		descriptionMatches := description.match(options.Terms)
+
+		matched := true
+		for i := range options.Terms {
+			if titleMatches[i] == 0 && descriptionMatches[i] == 0 {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		rank := 0.0
+		for i := range options.Terms {
+			rank += titleWeight*float64(titleMatches[i]) + descriptionWeight*float64(descriptionMatches[i])
+		}
+		results = append(results, task.SearchResult{
+			Task:      t,
+			Rank:      rank,
+			Highlight: title.highlight(),
+			Snippet:   description.highlight(),
+		})
+	}
+
+	slices.SortStableFunc(results, func(a, b task.SearchResult) int {
+		if a.Rank != b.Rank {
+			if a.Rank > b.Rank {
+				return -1
+			}
+			return 1
+		}
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	if options.Offset >= len(results) {
+		return []task.SearchResult{}, nil
+	}
+	results = results[options.Offset:]
+	if options.Limit > 0 && len(results) > options.Limit {
+		results = results[:options.Limit]
+	}
+
+	return results, nil
+}
+
+type token struct {
+	word       string
+	start, end int
+	matched    bool
+}
+
+type tokenizedText struct {
+	text   string
+	tokens []token
+}
+
+func tokenize(text string) *tokenizedText {
+	tokenized := &tokenizedText{text: text}
+	start := -1
+	for i, r := range text {
+		separator := !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		if separator && start >= 0 {
+			tokenized.tokens = append(tokenized.tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		} else if !separator && start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokenized.tokens = append(tokenized.tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokenized
+}
+
+// match counts the occurrences of every term and marks the matched tokens
+// for highlighting.
+func (t *tokenizedText) match(terms []task.SearchTerm) []int {
+	counts := make([]int, len(terms))
+	for i, term := range terms {
+		n := len(term.Words)
+		for start := 0; start+n <= len(t.tokens); start++ {
+			if !t.matchesAt(start, term) {
+				continue
+			}
+			counts[i]++
+			for j := start; j < start+n; j++ {
+				t.tokens[j].matched = true
+			}
+		}
+	}
+	return counts
+}
+
+func (t *tokenizedText) matchesAt(start int, term task.SearchTerm) bool {
+	for i, word := range term.Words {
+		got := t.tokens[start+i].word
+		if term.Prefix && i == len(term.Words)-1 {
+			if !strings.HasPrefix(got, word) {
+				return false
+			}
+		} else if got != word {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *tokenizedText) highlight() string {
+	var builder strings.Builder
+	last := 0
+	for _, token := range t.tokens {
+		if !token.matched {
+			continue
+		}
+		builder.WriteString(t.text[last:token.start])
+		builder.WriteString("<b>" + t.text[token.start:token.end] + "</b>")
+		last = token.end
+	}
+	builder.WriteString(t.text[last:])
+	return builder.String()
+}
@@ -0,0 +1,419 @@
+package task
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+)
+
+type MemTaskRepository struct {
+	mu    sync.RWMutex
+	tasks map[string]task.Task
+	order []string
+	// tags holds the tag names of each owner, a tag stays after its last
+	// task drops it, like a row of the tags table.
+	tags map[string]map[string]bool
+	// dependencies are kept in the order they were added.
+	dependencies []task.Dependency
+	// events only grow, the ID of an event is its position plus one.
+	events []task.Event
+	// webhooks are kept by creation and deliveries by ID, lastDeliveryId is
+	// the ID of the latest delivery.
+	webhooks       []task.Webhook
+	deliveries     []task.Delivery
+	lastDeliveryId int64
+}
+
+func NewMemTaskRepository() *MemTaskRepository {
+	return &MemTaskRepository{
+		tasks: map[string]task.Task{},
+		order: []string{},
+		tags:  map[string]map[string]bool{},
+	}
+}
+
+func (mem *MemTaskRepository) Get(ownerId, taskId string) (*task.Task, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	task, ok := mem.tasks[taskId]
+	if !ok || task.OwnerId != ownerId || task.IsDeleted() {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	}
+	return &task, nil
+}
+
+func (mem *MemTaskRepository) Insert(ownerId, taskId string, taskTitle, taskDesc string, details task.Details) (*task.Task, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	if _, ok := mem.tasks[taskId]; ok {
+		return nil, fmt.Errorf("task with ID %s already exists", taskId)
+	}
+
+	details = details.WithDefaults()
+	task := task.Task{
+		Id:          taskId,
+		OwnerId:     ownerId,
+		Title:       taskTitle,
+		Description: taskDesc,
+		Tags:        []string{},
+		Priority:    details.Priority,
+		DueAt:       details.DueAt,
+		ParentId:    details.ParentId,
+		Recurrence:  details.Recurrence,
+		SeriesId:    details.SeriesId,
+		Version:     1,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	task.SetStatus(details.Status)
+	mem.tasks[taskId] = task
+	mem.order = append(mem.order, taskId)
+	return &task, nil
+}
+
+func (mem *MemTaskRepository) Update(ownerId, taskId string, version *int, data map[string]any) (*task.Task, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	task, ok := mem.tasks[taskId]
+	if !ok || task.OwnerId != ownerId || task.IsDeleted() {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	}
+	if version != nil && task.Version != *version {
+		return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d, not %d", taskId, task.Version, *version))
+	}
+
+	updated := false
+
+	if title, ok := data["Title"].(string); ok {
+		task.Title = title
+		updated = true
+	}
+	if description, ok := data["Description"].(string); ok {
+		task.Description = description
+		updated = true
+	}
+	if status, ok := data["Status"].(string); ok {
+		task.SetStatus(status)
+		updated = true
+	}
+	if priority, ok := data["Priority"].(string); ok {
+		task.Priority = priority
+		updated = true
+	}
+	if dueAt, ok := data["DueAt"].(*time.Time); ok {
+		task.DueAt = dueAt
+		updated = true
+	}
+	if parentId, ok := data["ParentId"].(*string); ok {
+		task.ParentId = parentId
+		updated = true
+	}
+	if recurrence, ok := data["Recurrence"].(*string); ok {
+		task.Recurrence = recurrence
+		updated = true
+	}
+	if seriesId, ok := data["SeriesId"].(*string); ok {
+		task.SeriesId = seriesId
+		updated = true
+	}
+
+	if !updated {
+		return nil, errors.NoOp("Found no fields to update")
+	}
+
+	task.Version++
+	task.UpdatedAt = time.Now()
+	mem.tasks[taskId] = task
+	return &task, nil
+}
+
+func (mem *MemTaskRepository) Delete(ownerId, taskId string, version *int) (*string, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	task, ok := mem.tasks[taskId]
+	if !ok || task.OwnerId != ownerId || task.IsDeleted() {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	}
+	if version != nil && task.Version != *version {
+		return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d, not %d", taskId, task.Version, *version))
+	}
+
+	deletedAt := time.Now()
+	task.DeletedAt = &deletedAt
+	task.Version++
+	mem.tasks[taskId] = task
+
+	return &taskId, nil
+}
+
+func (mem *MemTaskRepository) Restore(ownerId, taskId string) (*task.Task, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	task, ok := mem.tasks[taskId]
+	if !ok || task.OwnerId != ownerId || !task.IsDeleted() {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found in trash", taskId))
+	}
+
+	task.DeletedAt = nil
+	task.Version++
+	task.UpdatedAt = time.Now()
+	mem.tasks[taskId] = task
+	return &task, nil
+}
+
+func (mem *MemTaskRepository) Purge(deletedBefore time.Time) (int64, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	var purged int64
+	order := mem.order[:0]
+	for _, id := range mem.order {
+		task := mem.tasks[id]
+		if task.IsDeleted() && task.DeletedAt.Before(deletedBefore) {
+			delete(mem.tasks, id)
+			purged++
+			continue
+		}
+		order = append(order, id)
+	}
+	mem.order = order
+
+	mem.dependencies = slices.DeleteFunc(mem.dependencies, func(dependency task.Dependency) bool {
+		_, taskFound := mem.tasks[dependency.TaskId]
+		_, blockerFound := mem.tasks[dependency.BlockerId]
+		return !taskFound || !blockerFound
+	})
+
+	// the subtasks of a purged task move to the top level
+	for id, task := range mem.tasks {
+		if task.ParentId != nil {
+			if _, ok := mem.tasks[*task.ParentId]; !ok {
+				task.ParentId = nil
+				mem.tasks[id] = task
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+func (mem *MemTaskRepository) List(ownerId string, options task.ListOptions) ([]task.Task, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	tasks := []task.Task{}
+	for _, id := range mem.order {
+		task := mem.tasks[id]
+		if task.OwnerId != ownerId || task.IsDeleted() != options.Deleted {
+			continue
+		}
+		if options.IsCompleted != nil && task.IsCompleted != *options.IsCompleted {
+			continue
+		}
+		if options.CreatedAfter != nil && !task.CreatedAt.After(*options.CreatedAfter) {
+			continue
+		}
+		if options.ParentId != nil && (task.ParentId == nil || *task.ParentId != *options.ParentId) {
+			continue
+		}
+		if options.SeriesId != nil && (task.SeriesId == nil || *task.SeriesId != *options.SeriesId) {
+			continue
+		}
+		if len(options.Tags) > 0 && !hasTags(task.Tags, options.Tags, options.AllTags) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	direction := 1
+	if options.Descending {
+		direction = -1
+	}
+	slices.SortStableFunc(tasks, func(a, b task.Task) int {
+		return direction * compareTasks(&a, options.SortBy, b.SortValue(options.SortBy), b.Id)
+	})
+
+	if options.After != nil {
+		start := len(tasks)
+		for i := range tasks {
+			if direction*compareTasks(&tasks[i], options.SortBy, options.After.Value, options.After.Id) > 0 {
+				start = i
+				break
+			}
+		}
+		tasks = tasks[start:]
+	}
+
+	if options.Limit > 0 && len(tasks) > options.Limit {
+		tasks = tasks[:options.Limit]
+	}
+
+	return tasks, nil
+}
+
+// Subtree returns the task and the subtasks below it that are not in the
+// trash, down to MaxDepth levels, by creation.
+func (mem *MemTaskRepository) Subtree(ownerId, taskId string) ([]task.Task, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	root, ok := mem.tasks[taskId]
+	if !ok || root.OwnerId != ownerId || root.IsDeleted() {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	}
+
+	inTree := map[string]bool{taskId: true}
+	level := []string{taskId}
+	for depth := 1; depth < task.MaxDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, id := range mem.order {
+			t := mem.tasks[id]
+			if t.OwnerId != ownerId || t.IsDeleted() || t.ParentId == nil || inTree[id] {
+				continue
+			}
+			if slices.Contains(level, *t.ParentId) {
+				inTree[id] = true
+				next = append(next, id)
+			}
+		}
+		level = next
+	}
+
+	tasks := []task.Task{}
+	for _, id := range mem.order {
+		if inTree[id] {
+			tasks = append(tasks, mem.tasks[id])
+		}
+	}
+	slices.SortStableFunc(tasks, func(a, b task.Task) int {
+		return compareTasks(&a, task.SortByCreatedAt, b.SortValue(task.SortByCreatedAt), b.Id)
+	})
+	return tasks, nil
+}
+
+func (mem *MemTaskRepository) SetTags(ownerId, taskId string, version *int, tags []string) (*task.Task, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	task, ok := mem.tasks[taskId]
+	if !ok || task.OwnerId != ownerId || task.IsDeleted() {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	}
+	if version != nil && task.Version != *version {
+		return nil, errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d, not %d", taskId, task.Version, *version))
+	}
+
+	if mem.tags[ownerId] == nil {
+		mem.tags[ownerId] = map[string]bool{}
+	}
+	for _, tag := range tags {
+		mem.tags[ownerId][tag] = true
+	}
+
+	task.Tags = slices.Sorted(slices.Values(tags))
+	task.Version++
+	task.UpdatedAt = time.Now()
+	mem.tasks[taskId] = task
+	return &task, nil
+}
+
+func (mem *MemTaskRepository) ListTags(ownerId string) ([]task.TagCount, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	counts := map[string]int{}
+	for name := range mem.tags[ownerId] {
+		counts[name] = 0
+	}
+	for _, t := range mem.tasks {
+		if t.OwnerId != ownerId || t.IsDeleted() {
+			continue
+		}
+		for _, name := range t.Tags {
+			counts[name]++
+		}
+	}
+
+	tags := []task.TagCount{}
+	for _, name := range slices.Sorted(maps.Keys(counts)) {
+		tags = append(tags, task.TagCount{Name: name, Count: counts[name]})
+	}
+	return tags, nil
+}
+
+// hasTags reports whether the task tags hold any of the wanted tags, or all
+// of them when all is set.
+func hasTags(tags, wanted []string, all bool) bool {
+	for _, tag := range wanted {
+		if slices.Contains(tags, tag) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+// compareTasks compares a task against the sort value and ID of another task
+// the same way the Postgres storage orders rows.
+func compareTasks(t *task.Task, sortBy, value, id string) int {
+	var result int
+	switch sortBy {
+	case task.SortByTitle:
+		result = strings.Compare(t.Title, value)
+	case task.SortByUpdatedAt:
+		at, _ := time.Parse(time.RFC3339Nano, value)
+		result = t.UpdatedAt.Compare(at)
+	default:
+		at, _ := time.Parse(time.RFC3339Nano, value)
+		result = t.CreatedAt.Compare(at)
+	}
+	if result == 0 {
+		result = strings.Compare(t.Id, id)
+	}
+	return result
+}
+
+// InTransaction runs fn with a copy of the repository and keeps its writes
+// only when fn succeeds. The repository is locked meanwhile, so no other
+// write is lost when the copy replaces it.
+func (mem *MemTaskRepository) InTransaction(fn func(repo *MemTaskRepository) error) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	tx := &MemTaskRepository{
+		tasks:        maps.Clone(mem.tasks),
+		order:        slices.Clone(mem.order),
+		tags:         map[string]map[string]bool{},
+		dependencies: slices.Clone(mem.dependencies),
+		events:       slices.Clip(mem.events),
+		webhooks:     slices.Clip(mem.webhooks),
+		// deliveries are updated in place, unlike the events
+		deliveries:     slices.Clone(mem.deliveries),
+		lastDeliveryId: mem.lastDeliveryId,
+	}
+	for ownerId, tags := range mem.tags {
+		tx.tags[ownerId] = maps.Clone(tags)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	mem.tasks, mem.order, mem.tags, mem.dependencies, mem.events = tx.tasks, tx.order, tx.tags, tx.dependencies, tx.events
+	mem.webhooks, mem.deliveries, mem.lastDeliveryId = tx.webhooks, tx.deliveries, tx.lastDeliveryId
+	return nil
+}
+
+func (mem *MemTaskRepository) Close() error {
+	return nil
+}
@@ -0,0 +1,825 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	entities "github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/google/uuid"
+)
+
+const owner = "test-owner"
+
+func TestMemGet(t *testing.T) {
+	t.Run("should get task", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+
+		task, err := mem.Get(owner, id)
+
+		if err != nil {
+			t.Errorf("mem.Get error: %v", err)
+			return
+		}
+		if task.Id != id {
+			t.Errorf("expected task ID %s, but got %s", id, task.Id)
+		}
+	})
+	t.Run("should fail to get task", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+
+		_, err := mem.Get(owner, id)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok {
+			t.Errorf("expected not found error, but got %v", err)
+			return
+		}
+		if appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected error type %s, but got %s", errors.NOT_FOUND, appError.Type)
+		}
+	})
+}
+
+func TestMemOwnership(t *testing.T) {
+	t.Run("tasks of another owner are hidden", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert("other-owner", id, "Test task", "Test task description", entities.Details{})
+
+		_, getErr := mem.Get(owner, id)
+		_, updateErr := mem.Update(owner, id, nil, map[string]any{"Title": "Test task (updated)"})
+		_, deleteErr := mem.Delete(owner, id, nil)
+		tasks, _ := mem.List(owner, entities.ListOptions{})
+
+		for _, err := range []error{getErr, updateErr, deleteErr} {
+			appError, ok := err.(*errors.AppError)
+			if !ok || appError.Type != errors.NOT_FOUND {
+				t.Errorf("expected not found error, but got %v", err)
+			}
+		}
+		if len(tasks) != 0 {
+			t.Errorf("expected no tasks for owner but got %d", len(tasks))
+		}
+	})
+}
+
+func TestMemInsert(t *testing.T) {
+	t.Run("should create a task", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		title, description := "Test task", "Test task description"
+		mem := NewMemTaskRepository()
+
+		task, err := mem.Insert(owner, id, title, description, entities.Details{})
+
+		if err != nil {
+			t.Errorf("Insert failed with error: %v", err)
+			return
+		}
+		if task.Title != title || task.Description != description {
+			t.Errorf("Inserted task expected title and description %s, %s but got %s, %s", title, description, task.Title, task.Description)
+			return
+		}
+		if task.CreatedAt.IsZero() || task.UpdatedAt.IsZero() {
+			t.Errorf("Inserted task must have created_at and updated_at field")
+		}
+	})
+	t.Run("fail to create task with duplicate ID", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task 1", "Test task 1 description", entities.Details{})
+
+		_, err := mem.Insert(owner, id, "Test task 2", "Test task 2 description", entities.Details{})
+
+		if err == nil {
+			t.Errorf("expecting an error, but there was none")
+		}
+	})
+}
+
+func TestMemUpdate(t *testing.T) {
+	t.Run("update task success", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+		updateTitle := "Test task (updated)"
+
+		task, err := mem.Update(owner, id, nil, map[string]any{
+			"Title":  updateTitle,
+			"Status": entities.StatusDone,
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if task.Title != updateTitle {
+			t.Errorf("task is not updated, expected %s but got %s", updateTitle, task.Title)
+		}
+		if !task.IsCompleted {
+			t.Errorf("task is not updated, expected is_completed to be true")
+		}
+	})
+	t.Run("update task with no fields", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+
+		_, err := mem.Update(owner, id, nil, map[string]any{})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok {
+			t.Errorf("expected no-op error, but got %v", err)
+			return
+		}
+		if appError.Type != errors.NO_OPERATION {
+			t.Errorf("expected error type %s, but got %s", errors.NO_OPERATION, appError.Type)
+		}
+	})
+	t.Run("update an invalid task fail", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+
+		_, err := mem.Update(owner, id, nil, map[string]any{
+			"Title": "Test task (updated)",
+		})
+
+		if _, ok := err.(*errors.AppError); !ok {
+			t.Errorf("expected not found error, but got %v", err)
+		}
+	})
+	t.Run("update bumps the version", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		inserted, _ := mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+
+		task, err := mem.Update(owner, id, &inserted.Version, map[string]any{"Title": "Test task (updated)"})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if task.Version != inserted.Version+1 {
+			t.Errorf("expected version %d, but got %d", inserted.Version+1, task.Version)
+		}
+	})
+	t.Run("update a stale version fail", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		inserted, _ := mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+		stale := inserted.Version
+		mem.Update(owner, id, nil, map[string]any{"Title": "Test task (updated)"})
+
+		_, err := mem.Update(owner, id, &stale, map[string]any{"Title": "Test task (lost)"})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition failed error, but got %v", err)
+		}
+		if task, _ := mem.Get(owner, id); task.Title != "Test task (updated)" {
+			t.Errorf("stale update was applied, got title %s", task.Title)
+		}
+	})
+}
+
+func TestMemDelete(t *testing.T) {
+	t.Run("delete a task", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+
+		dId, err := mem.Delete(owner, id, nil)
+
+		if err != nil {
+			t.Errorf("error occured: %v", err)
+			return
+		}
+		if *dId != id {
+			t.Errorf("expected deleted task %s but got %s", id, *dId)
+		}
+		if _, err := mem.Get(owner, id); err == nil {
+			t.Errorf("expected deleted task to be gone")
+		}
+	})
+	t.Run("delete an invalid task fail", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+
+		_, err := mem.Delete(owner, id, nil)
+
+		if _, ok := err.(*errors.AppError); !ok {
+			t.Errorf("expected not found error, but got %v", err)
+		}
+	})
+	t.Run("delete a stale version fail", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+		stale := 2
+
+		_, err := mem.Delete(owner, id, &stale)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition failed error, but got %v", err)
+		}
+		if _, err := mem.Get(owner, id); err != nil {
+			t.Errorf("expected task to survive a stale delete, but got %v", err)
+		}
+	})
+}
+
+func TestMemTrash(t *testing.T) {
+	t.Run("deleted task is only in the trash", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		ids := []string{}
+		for i := range 2 {
+			uuid_, _ := uuid.NewUUID()
+			ids = append(ids, uuid_.String())
+			mem.Insert(owner, ids[i], fmt.Sprintf("Report task %d", i+1), "Test task description", entities.Details{})
+		}
+
+		mem.Delete(owner, ids[0], nil)
+
+		tasks, _ := mem.List(owner, entities.ListOptions{})
+		if len(tasks) != 1 || tasks[0].Id != ids[1] {
+			t.Errorf("expected only the live task in the list, but got %v", tasks)
+		}
+		trash, _ := mem.List(owner, entities.ListOptions{Deleted: true})
+		if len(trash) != 1 || trash[0].Id != ids[0] || !trash[0].IsDeleted() {
+			t.Errorf("expected only the deleted task in the trash, but got %v", trash)
+		}
+		results, _ := mem.Search(owner, entities.SearchOptions{Terms: entities.ParseSearchQuery("report")})
+		if len(results) != 1 || results[0].Id != ids[1] {
+			t.Errorf("expected search to skip the deleted task, but got %v", results)
+		}
+		if _, err := mem.Update(owner, ids[0], nil, map[string]any{"Title": "Test task (updated)"}); err == nil {
+			t.Errorf("expected update of a deleted task to fail")
+		}
+	})
+	t.Run("restore a deleted task", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+		mem.Delete(owner, id, nil)
+
+		restored, err := mem.Restore(owner, id)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if restored.IsDeleted() {
+			t.Errorf("expected restored task to not be deleted")
+		}
+		if _, err := mem.Get(owner, id); err != nil {
+			t.Errorf("expected restored task to be found, but got %v", err)
+		}
+	})
+	t.Run("restore a live task fail", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+
+		_, err := mem.Restore(owner, id)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected not found error, but got %v", err)
+		}
+	})
+	t.Run("purge only removes tasks deleted before the time", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		ids := []string{}
+		for i := range 3 {
+			uuid_, _ := uuid.NewUUID()
+			ids = append(ids, uuid_.String())
+			mem.Insert(owner, ids[i], "Test task", "Test task description", entities.Details{})
+		}
+		mem.Delete(owner, ids[0], nil)
+		deletedBefore := time.Now()
+		mem.Delete(owner, ids[1], nil)
+
+		purged, err := mem.Purge(deletedBefore)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if purged != 1 {
+			t.Errorf("expected 1 purged task, but got %d", purged)
+		}
+		if _, err := mem.Restore(owner, ids[0]); err == nil {
+			t.Errorf("expected purged task to be gone")
+		}
+		if _, err := mem.Restore(owner, ids[1]); err != nil {
+			t.Errorf("expected recently deleted task to stay in the trash, but got %v", err)
+		}
+	})
+}
+
+func TestMemTags(t *testing.T) {
+	t.Run("set tags of a task", func(t *testing.T) {
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+		version := 1
+
+		task, err := mem.SetTags(owner, id, &version, []string{"work", "home"})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if !slices.Equal(task.Tags, []string{"home", "work"}) {
+			t.Errorf("expected sorted tags [home work], but got %v", task.Tags)
+		}
+		if task.Version != 2 {
+			t.Errorf("expected version 2, but got %d", task.Version)
+		}
+		if _, err := mem.SetTags(owner, id, &version, []string{"home"}); err == nil {
+			t.Errorf("expected set tags with stale version to fail")
+		}
+	})
+	t.Run("list tags with live task counts", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		ids := []string{}
+		for i := range 3 {
+			uuid_, _ := uuid.NewUUID()
+			ids = append(ids, uuid_.String())
+			mem.Insert(owner, ids[i], "Test task", "Test task description", entities.Details{})
+		}
+		mem.SetTags(owner, ids[0], nil, []string{"home", "work"})
+		mem.SetTags(owner, ids[1], nil, []string{"work"})
+		mem.SetTags(owner, ids[2], nil, []string{"errand"})
+		mem.SetTags(owner, ids[2], nil, []string{})
+		mem.Delete(owner, ids[1], nil)
+
+		tags, err := mem.ListTags(owner)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		expected := []entities.TagCount{{Name: "errand", Count: 0}, {Name: "home", Count: 1}, {Name: "work", Count: 1}}
+		if !slices.Equal(tags, expected) {
+			t.Errorf("expected tags %v, but got %v", expected, tags)
+		}
+		if others, _ := mem.ListTags("other-owner"); len(others) != 0 {
+			t.Errorf("expected no tags for another owner, but got %v", others)
+		}
+	})
+	t.Run("filter tasks by any or all tags", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		ids := []string{}
+		for i := range 3 {
+			uuid_, _ := uuid.NewUUID()
+			ids = append(ids, uuid_.String())
+			mem.Insert(owner, ids[i], "Test task", "Test task description", entities.Details{})
+		}
+		mem.SetTags(owner, ids[0], nil, []string{"home", "work"})
+		mem.SetTags(owner, ids[1], nil, []string{"work"})
+
+		anyTasks, _ := mem.List(owner, entities.ListOptions{Tags: []string{"home", "work"}})
+		allTasks, _ := mem.List(owner, entities.ListOptions{Tags: []string{"home", "work"}, AllTags: true})
+
+		if len(anyTasks) != 2 {
+			t.Errorf("expected 2 tasks with any of the tags, but got %d", len(anyTasks))
+		}
+		if len(allTasks) != 1 || allTasks[0].Id != ids[0] {
+			t.Errorf("expected only the first task with all of the tags, but got %v", allTasks)
+		}
+	})
+}
+
+func TestMemList(t *testing.T) {
+	t.Run("list all tasks in insertion order", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		ids := []string{}
+		for range 3 {
+			uuid_, _ := uuid.NewUUID()
+			ids = append(ids, uuid_.String())
+			mem.Insert(owner, uuid_.String(), "Test task", "Test task description", entities.Details{})
+		}
+		mem.Delete(owner, ids[1], nil)
+
+		tasks, err := mem.List(owner, entities.ListOptions{})
+
+		if err != nil {
+			t.Errorf("error occured: %v", err)
+			return
+		}
+		if len(tasks) != 2 {
+			t.Errorf("expected 2 tasks but got %d", len(tasks))
+			return
+		}
+		if tasks[0].Id != ids[0] || tasks[1].Id != ids[2] {
+			t.Errorf("tasks are not listed in insertion order")
+		}
+	})
+	t.Run("concurrent inserts are all stored", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				uuid_, _ := uuid.NewUUID()
+				mem.Insert(owner, uuid_.String(), "Test task", "Test task description", entities.Details{})
+			}()
+		}
+		wg.Wait()
+
+		tasks, _ := mem.List(owner, entities.ListOptions{})
+
+		if len(tasks) != 50 {
+			t.Errorf("expected 50 tasks but got %d", len(tasks))
+		}
+	})
+	t.Run("list tasks page by page", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		for _, title := range []string{"c", "a", "d", "b"} {
+			uuid_, _ := uuid.NewUUID()
+			mem.Insert(owner, uuid_.String(), title, "Test task description", entities.Details{})
+		}
+
+		first, _ := mem.List(owner, entities.ListOptions{SortBy: entities.SortByTitle, Descending: true, Limit: 2})
+		second, _ := mem.List(owner, entities.ListOptions{
+			SortBy:     entities.SortByTitle,
+			Descending: true,
+			Limit:      2,
+			After:      &entities.Cursor{Value: first[1].Title, Id: first[1].Id},
+		})
+
+		got := []string{}
+		for _, task := range append(first, second...) {
+			got = append(got, task.Title)
+		}
+		if want := []string{"d", "c", "b", "a"}; !slices.Equal(got, want) {
+			t.Errorf("expected tasks %v but got %v", want, got)
+		}
+	})
+	t.Run("list tasks with filters", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		ids := []string{}
+		for range 3 {
+			uuid_, _ := uuid.NewUUID()
+			ids = append(ids, uuid_.String())
+			mem.Insert(owner, uuid_.String(), "Test task", "Test task description", entities.Details{})
+		}
+		mem.Update(owner, ids[0], nil, map[string]any{"Status": entities.StatusDone})
+		isCompleted := false
+		first, _ := mem.Get(owner, ids[0])
+
+		tasks, _ := mem.List(owner, entities.ListOptions{IsCompleted: &isCompleted, CreatedAfter: &first.CreatedAt})
+
+		if len(tasks) != 2 {
+			t.Errorf("expected 2 tasks but got %d", len(tasks))
+		}
+	})
+	t.Run("list the occurrences of a series", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		seriesId, recurrence := "task-1", "FREQ=DAILY"
+		mem.Insert(owner, seriesId, "Test task", "Test task description", entities.Details{Recurrence: &recurrence, SeriesId: &seriesId})
+		mem.Insert(owner, "task-2", "Test task", "Test task description", entities.Details{})
+		mem.Insert(owner, "task-3", "Test task", "Test task description", entities.Details{Recurrence: &recurrence, SeriesId: &seriesId})
+
+		tasks, _ := mem.List(owner, entities.ListOptions{SeriesId: &seriesId})
+
+		if len(tasks) != 2 || tasks[0].Id != seriesId || tasks[1].Id != "task-3" {
+			t.Errorf("expected tasks %s and task-3, but got %v", seriesId, tasks)
+		}
+	})
+}
+
+func TestMemSearch(t *testing.T) {
+	prepare := func() *MemTaskRepository {
+		mem := NewMemTaskRepository()
+		for _, task := range [][2]string{
+			{"Write weekly report", "Summary of the sprint"},
+			{"Prepare meeting", "Write the weekly report before the meeting"},
+			{"Report bug", "Login page is broken"},
+		} {
+			uuid_, _ := uuid.NewUUID()
+			mem.Insert(owner, uuid_.String(), task[0], task[1], entities.Details{})
+		}
+		return mem
+	}
+
+	t.Run("search matches title and description ranked by weight", func(t *testing.T) {
+		mem := prepare()
+
+		results, _ := mem.Search(owner, entities.SearchOptions{Terms: entities.ParseSearchQuery("report")})
+
+		got := []string{}
+		for _, result := range results {
+			got = append(got, result.Title)
+		}
+		if want := []string{"Write weekly report", "Report bug", "Prepare meeting"}; !slices.Equal(got, want) {
+			t.Errorf("expected results %v but got %v", want, got)
+		}
+	})
+	t.Run("search phrase", func(t *testing.T) {
+		mem := prepare()
+
+		results, _ := mem.Search(owner, entities.SearchOptions{Terms: entities.ParseSearchQuery(`"report before"`)})
+
+		if len(results) != 1 || results[0].Title != "Prepare meeting" {
+			t.Errorf("expected only 'Prepare meeting' but got %v", results)
+			return
+		}
+		if want := "Write the weekly <b>report</b> <b>before</b> the meeting"; results[0].Snippet != want {
+			t.Errorf("expected snippet %s but got %s", want, results[0].Snippet)
+		}
+	})
+	t.Run("search prefix", func(t *testing.T) {
+		mem := prepare()
+
+		results, _ := mem.Search(owner, entities.SearchOptions{Terms: entities.ParseSearchQuery("brok*")})
+
+		if len(results) != 1 || results[0].Title != "Report bug" {
+			t.Errorf("expected only 'Report bug' but got %v", results)
+		}
+	})
+	t.Run("search with offset and limit", func(t *testing.T) {
+		mem := prepare()
+
+		results, _ := mem.Search(owner, entities.SearchOptions{Terms: entities.ParseSearchQuery("report"), Limit: 1, Offset: 1})
+
+		if len(results) != 1 || results[0].Title != "Report bug" {
+			t.Errorf("expected only 'Report bug' but got %v", results)
+		}
+	})
+}
+
+func TestMemSubtree(t *testing.T) {
+	t.Run("subtree keeps the live subtasks at every level", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		parentId, childId := "task-1", "task-2"
+		mem.Insert(owner, parentId, "Test task", "Test task description", entities.Details{})
+		mem.Insert(owner, childId, "Test task", "Test task description", entities.Details{ParentId: &parentId})
+		mem.Insert(owner, "task-3", "Test task", "Test task description", entities.Details{ParentId: &childId})
+		mem.Insert(owner, "task-4", "Test task", "Test task description", entities.Details{ParentId: &parentId})
+		mem.Insert(owner, "task-5", "Test task", "Test task description", entities.Details{})
+		mem.Delete(owner, "task-4", nil)
+
+		tasks, err := mem.Subtree(owner, parentId)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		ids := []string{}
+		for _, task := range tasks {
+			ids = append(ids, task.Id)
+		}
+		if !slices.Equal(ids, []string{"task-1", "task-2", "task-3"}) {
+			t.Errorf("expected tasks task-1, task-2 and task-3, but got %v", ids)
+		}
+	})
+	t.Run("list keeps the direct subtasks", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		parentId, childId := "task-1", "task-2"
+		mem.Insert(owner, parentId, "Test task", "Test task description", entities.Details{})
+		mem.Insert(owner, childId, "Test task", "Test task description", entities.Details{ParentId: &parentId})
+		mem.Insert(owner, "task-3", "Test task", "Test task description", entities.Details{ParentId: &childId})
+
+		tasks, _ := mem.List(owner, entities.ListOptions{ParentId: &parentId})
+
+		if len(tasks) != 1 || tasks[0].Id != childId {
+			t.Errorf("expected only task %s, but got %v", childId, tasks)
+		}
+	})
+	t.Run("purged parent moves the subtasks to the top level", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		parentId := "task-1"
+		mem.Insert(owner, parentId, "Test task", "Test task description", entities.Details{})
+		mem.Insert(owner, "task-2", "Test task", "Test task description", entities.Details{ParentId: &parentId})
+		mem.Delete(owner, parentId, nil)
+
+		mem.Purge(time.Now().Add(time.Hour))
+
+		if task, _ := mem.Get(owner, "task-2"); task.ParentId != nil {
+			t.Errorf("expected a top level task, but got parent %s", *task.ParentId)
+		}
+	})
+}
+
+func TestMemDependencies(t *testing.T) {
+	t.Run("dependencies of the live tasks", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		for _, id := range []string{"task-1", "task-2", "task-3"} {
+			mem.Insert(owner, id, "Test task", "Test task description", entities.Details{})
+		}
+		mem.AddDependency(owner, "task-1", "task-2")
+		mem.AddDependency(owner, "task-1", "task-2")
+		mem.AddDependency(owner, "task-1", "task-3")
+		mem.Delete(owner, "task-3", nil)
+
+		dependencies, _ := mem.ListDependencies(owner)
+		blockers, _ := mem.Blockers(owner, "task-1")
+		blocking, _ := mem.Blocking(owner, "task-2")
+
+		if len(dependencies) != 1 || dependencies[0] != (entities.Dependency{TaskId: "task-1", BlockerId: "task-2"}) {
+			t.Errorf("expected task-1 to be blocked by task-2, but got %v", dependencies)
+		}
+		if len(blockers) != 1 || blockers[0].Id != "task-2" {
+			t.Errorf("expected blocker task-2, but got %v", blockers)
+		}
+		if len(blocking) != 1 || blocking[0].Id != "task-1" {
+			t.Errorf("expected blocked task task-1, but got %v", blocking)
+		}
+	})
+	t.Run("remove a missing dependency", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, "task-1", "Test task", "Test task description", entities.Details{})
+
+		err := mem.RemoveDependency(owner, "task-1", "task-2")
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected dependency not found, but got %v", err)
+		}
+	})
+	t.Run("purged tasks drop their dependencies", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, "task-1", "Test task", "Test task description", entities.Details{})
+		mem.Insert(owner, "task-2", "Test task", "Test task description", entities.Details{})
+		mem.AddDependency(owner, "task-1", "task-2")
+		mem.Delete(owner, "task-2", nil)
+		mem.Purge(time.Now().Add(time.Hour))
+		mem.Insert(owner, "task-2", "Test task", "Test task description", entities.Details{})
+
+		if blockers, _ := mem.Blockers(owner, "task-1"); len(blockers) != 0 {
+			t.Errorf("expected no blockers, but got %v", blockers)
+		}
+	})
+}
+
+func TestMemEvents(t *testing.T) {
+	t.Run("events are listed in order with filters", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		for i, actorId := range []string{owner, "other-owner", owner, owner} {
+			mem.AddEvent(entities.Event{
+				TaskId:    fmt.Sprintf("task-%d", i),
+				OwnerId:   actorId,
+				ActorId:   actorId,
+				Action:    entities.ActionCreate,
+				CreatedAt: time.Now(),
+			})
+		}
+		actorId := owner
+
+		events, _ := mem.ListEvents(entities.EventOptions{ActorId: &actorId, After: 1, Limit: 1})
+
+		if len(events) != 1 || events[0].Id != 3 || events[0].TaskId != "task-2" {
+			t.Errorf("expected event 3 of task-2, but got %+v", events)
+		}
+	})
+	t.Run("events of a failed transaction are dropped", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+
+		mem.InTransaction(func(tx *MemTaskRepository) error {
+			tx.AddEvent(entities.Event{TaskId: "task-1", Action: entities.ActionCreate})
+			return fmt.Errorf("write failed")
+		})
+
+		if events, _ := mem.ListEvents(entities.EventOptions{}); len(events) != 0 {
+			t.Errorf("expected no events, but got %+v", events)
+		}
+	})
+}
+
+func TestMemWebhooks(t *testing.T) {
+	t.Run("webhooks are kept per owner", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		mem.AddWebhook(entities.Webhook{Id: "webhook-1", OwnerId: owner, Events: []string{entities.WebhookCreated}})
+		mem.AddWebhook(entities.Webhook{Id: "webhook-2", OwnerId: "other-owner"})
+
+		webhooks, _ := mem.ListWebhooks(owner)
+		_, err := mem.GetWebhook(owner, "webhook-2")
+
+		if len(webhooks) != 1 || webhooks[0].Id != "webhook-1" {
+			t.Errorf("expected webhook-1, but got %+v", webhooks)
+		}
+		if err == nil {
+			t.Errorf("expected the webhook of another owner to be not found")
+		}
+	})
+	t.Run("due deliveries are claimed for the lease", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		now := time.Now()
+		for _, due := range []time.Time{now.Add(-time.Minute), now.Add(time.Minute), now.Add(-time.Hour)} {
+			mem.AddDelivery(entities.Delivery{WebhookId: "webhook-1", OwnerId: owner, Status: entities.DeliveryPending, NextAttemptAt: due})
+		}
+
+		claimed, _ := mem.ClaimDeliveries(now, time.Minute, 1)
+		again, _ := mem.ClaimDeliveries(now, time.Minute, 10)
+
+		if len(claimed) != 1 || claimed[0].Id != 3 {
+			t.Errorf("expected delivery 3 due first, but got %+v", claimed)
+		}
+		if len(again) != 1 || again[0].Id != 1 {
+			t.Errorf("expected only delivery 1 to be left, but got %+v", again)
+		}
+	})
+	t.Run("deliveries are listed from the latest and dropped with their webhook", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		mem.AddWebhook(entities.Webhook{Id: "webhook-1", OwnerId: owner})
+		for _, status := range []string{entities.DeliveryDelivered, entities.DeliveryFailed, entities.DeliveryDelivered} {
+			mem.AddDelivery(entities.Delivery{WebhookId: "webhook-1", OwnerId: owner, Status: status})
+		}
+		delivered := entities.DeliveryDelivered
+
+		deliveries, _ := mem.ListDeliveries(owner, "webhook-1", entities.DeliveryOptions{Status: &delivered, Before: 3})
+		mem.DeleteWebhook(owner, "webhook-1")
+		mem.AddDelivery(entities.Delivery{WebhookId: "webhook-2", OwnerId: owner})
+		left, _ := mem.ListDeliveries(owner, "webhook-1", entities.DeliveryOptions{})
+		added, _ := mem.ListDeliveries(owner, "webhook-2", entities.DeliveryOptions{})
+
+		if len(deliveries) != 1 || deliveries[0].Id != 1 {
+			t.Errorf("expected delivery 1, but got %+v", deliveries)
+		}
+		if len(left) != 0 || len(added) != 1 || added[0].Id != 4 {
+			t.Errorf("expected only delivery 4 to be left, but got %+v and %+v", left, added)
+		}
+	})
+	t.Run("deliveries of a failed transaction are dropped", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+
+		mem.InTransaction(func(tx *MemTaskRepository) error {
+			tx.AddWebhook(entities.Webhook{Id: "webhook-1", OwnerId: owner})
+			tx.AddDelivery(entities.Delivery{WebhookId: "webhook-1", OwnerId: owner})
+			return fmt.Errorf("write failed")
+		})
+
+		webhooks, _ := mem.ListWebhooks(owner)
+		deliveries, _ := mem.ListDeliveries(owner, "webhook-1", entities.DeliveryOptions{})
+		if len(webhooks) != 0 || len(deliveries) != 0 {
+			t.Errorf("expected nothing to be kept, but got %+v and %+v", webhooks, deliveries)
+		}
+	})
+}
+
+func TestMemInTransaction(t *testing.T) {
+	t.Run("writes are kept when the transaction succeeds", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+
+		err := mem.InTransaction(func(repo *MemTaskRepository) error {
+			_, err := repo.Insert(owner, "task-1", "Test task", "Test task description", entities.Details{})
+			return err
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if _, err := mem.Get(owner, "task-1"); err != nil {
+			t.Errorf("expected task to be inserted, but got %v", err)
+		}
+	})
+	t.Run("writes are dropped when the transaction fails", func(t *testing.T) {
+		mem := NewMemTaskRepository()
+		mem.Insert(owner, "task-1", "Test task", "Test task description", entities.Details{})
+
+		err := mem.InTransaction(func(repo *MemTaskRepository) error {
+			repo.Insert(owner, "task-2", "Test task", "Test task description", entities.Details{})
+			repo.SetTags(owner, "task-1", nil, []string{"home"})
+			_, err := repo.Delete(owner, "missing-task", nil)
+			return err
+		})
+
+		if err == nil {
+			t.Errorf("expected the transaction to fail")
+		}
+		if _, err := mem.Get(owner, "task-2"); err == nil {
+			t.Errorf("expected inserted task to be rolled back")
+		}
+		if task, _ := mem.Get(owner, "task-1"); len(task.Tags) != 0 {
+			t.Errorf("expected tags to be rolled back, but got %v", task.Tags)
+		}
+		if tags, _ := mem.ListTags(owner); len(tags) != 0 {
+			t.Errorf("expected no tags, but got %v", tags)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package task
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+)
+
+func (mem *MemTaskRepository) AddWebhook(webhook task.Webhook) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	if slices.ContainsFunc(mem.webhooks, func(w task.Webhook) bool { return w.Id == webhook.Id }) {
+		return fmt.Errorf("webhook with ID %s already exists", webhook.Id)
+	}
+	webhook.Events = slices.Clone(webhook.Events)
+	mem.webhooks = append(mem.webhooks, webhook)
+	return nil
+}
+
+// ListWebhooks returns the webhooks of the owner by creation.
+func (mem *MemTaskRepository) ListWebhooks(ownerId string) ([]task.Webhook, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	webhooks := []task.Webhook{}
+	for _, webhook := range mem.webhooks {
+		if webhook.OwnerId == ownerId {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (mem *MemTaskRepository) GetWebhook(ownerId, webhookId string) (*task.Webhook, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	for _, webhook := range mem.webhooks {
+		if webhook.Id == webhookId && webhook.OwnerId == ownerId {
+			return &webhook, nil
+		}
+	}
+	return nil, errors.NotFoundError(fmt.Sprintf("Webhook with ID %s not found", webhookId))
+}
+
+// DeleteWebhook removes the webhook with its deliveries.
+func (mem *MemTaskRepository) DeleteWebhook(ownerId, webhookId string) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	i := slices.IndexFunc(mem.webhooks, func(w task.Webhook) bool { return w.Id == webhookId && w.OwnerId == ownerId })
+	if i < 0 {
+		return errors.NotFoundError(fmt.Sprintf("Webhook with ID %s not found", webhookId))
+	}
+	mem.webhooks = slices.Delete(slices.Clone(mem.webhooks), i, i+1)
+	mem.deliveries = slices.DeleteFunc(slices.Clone(mem.deliveries), func(d task.Delivery) bool { return d.WebhookId == webhookId })
+	return nil
+}
+
+// AddDelivery gives the delivery the next ID, the IDs of the deliveries keep
+// growing after some of them are removed.
+func (mem *MemTaskRepository) AddDelivery(delivery task.Delivery) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	mem.lastDeliveryId++
+	delivery.Id = mem.lastDeliveryId
+	mem.deliveries = append(mem.deliveries, delivery)
+	return nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due at now,
+// the ones due first first. They are not due again before the lease ends.
+func (mem *MemTaskRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]task.Delivery, error) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	due := []int{}
+	for i, delivery := range mem.deliveries {
+		if delivery.Status == task.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	slices.SortStableFunc(due, func(a, b int) int {
+		return mem.deliveries[a].NextAttemptAt.Compare(mem.deliveries[b].NextAttemptAt)
+	})
+	due = due[:min(limit, len(due))]
+	slices.Sort(due)
+
+	deliveries := []task.Delivery{}
+	for _, i := range due {
+		mem.deliveries[i].NextAttemptAt = now.Add(lease)
+		deliveries = append(deliveries, mem.deliveries[i])
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of an attempt of the delivery.
+func (mem *MemTaskRepository) UpdateDelivery(delivery task.Delivery) error {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	i, ok := slices.BinarySearchFunc(mem.deliveries, delivery.Id, func(d task.Delivery, id int64) int {
+		return cmp.Compare(d.Id, id)
+	})
+	if !ok {
+		return errors.NotFoundError(fmt.Sprintf("Delivery with ID %d not found", delivery.Id))
+	}
+	mem.deliveries[i] = delivery
+	return nil
+}
+
+// ListDeliveries returns the deliveries of the webhook that pass the options,
+// the latest first.
+func (mem *MemTaskRepository) ListDeliveries(ownerId, webhookId string, options task.DeliveryOptions) ([]task.Delivery, error) {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	deliveries := []task.Delivery{}
+	for _, delivery := range slices.Backward(mem.deliveries) {
+		if options.Limit > 0 && len(deliveries) == options.Limit {
+			break
+		}
+		if delivery.OwnerId == ownerId && delivery.WebhookId == webhookId && options.Matches(delivery) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,263 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// lockId is the advisory lock held while a migration runs, so concurrent
+// migrate commands never apply the same migration twice.
+const lockId = 7365_3201
+
+// ownerVersion is the migration that gives every task an owner, the tasks
+// written before it are left with an empty owner_id and no user can reach them.
+const ownerVersion = 2
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func New(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(db, sub)
+}
+
+func newMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s does not match NNNN_name.(up|down).sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, path.Join(".", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must start at 1 without gaps, found %d at position %d", migration.Version, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Latest is the version the schema has after every migration is applied.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Version is the last applied migration, 0 when none are.
+func (m *Migrator) Version() (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	var version int
+	if err := m.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("schema version read error: %v", err)
+	}
+	return version, nil
+}
+
+func (m *Migrator) Pending() ([]Migration, error) {
+	version, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+	if version > m.Latest() {
+		return nil, fmt.Errorf("database schema version %d is newer than the latest known migration %d", version, m.Latest())
+	}
+	return m.migrations[version:], nil
+}
+
+// Up applies at most steps pending migrations, all of them when steps is 0.
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	applied := []Migration{}
+	for _, migration := range pending {
+		err := m.run(migration.Up, migration.Version-1,
+			"INSERT INTO schema_migrations(version, name, applied_at) VALUES ($1, $2, NOW())", migration.Version, migration.Name)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s up error: %v", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	version, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+	if version > m.Latest() {
+		return nil, fmt.Errorf("database schema version %d is newer than the latest known migration %d", version, m.Latest())
+	}
+
+	reverted := []Migration{}
+	for ; steps > 0 && version > 0; steps-- {
+		migration := m.migrations[version-1]
+		err := m.run(migration.Down, migration.Version,
+			"DELETE FROM schema_migrations WHERE version = ($1)", migration.Version)
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d_%s down error: %v", migration.Version, migration.Name, err)
+		}
+		reverted = append(reverted, migration)
+		version--
+	}
+	return reverted, nil
+}
+
+// Unowned counts the tasks written before the schema had owners.
+func (m *Migrator) Unowned() (int, error) {
+	version, err := m.Version()
+	if err != nil {
+		return 0, err
+	}
+	if version < ownerVersion {
+		return 0, nil
+	}
+
+	var count int
+	if err := m.db.QueryRow("SELECT COUNT(*) FROM tasks WHERE owner_id = ''").Scan(&count); err != nil {
+		return 0, fmt.Errorf("unowned tasks count error: %v", err)
+	}
+	return count, nil
+}
+
+// AssignOwner gives the tasks written before the schema had owners to ownerId.
+func (m *Migrator) AssignOwner(ownerId string) (int64, error) {
+	if ownerId == "" {
+		return 0, errors.New("legacy owner must not be empty")
+	}
+	version, err := m.Version()
+	if err != nil {
+		return 0, err
+	}
+	if version < ownerVersion {
+		return 0, nil
+	}
+
+	result, err := m.db.Exec("UPDATE tasks SET owner_id = ($1) WHERE owner_id = ''", ownerId)
+	if err != nil {
+		return 0, fmt.Errorf("legacy owner assign error: %v", err)
+	}
+	return result.RowsAffected()
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations(
+							version INTEGER PRIMARY KEY,
+							name TEXT NOT NULL,
+							applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+						)`)
+	if err != nil {
+		return fmt.Errorf("schema_migrations create error: %v", err)
+	}
+	return nil
+}
+
+// run executes a migration script and records it in schema_migrations in a
+// single transaction, if the schema is still at the version it starts from.
+func (m *Migrator) run(script string, from int, record string, args ...any) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", lockId); err != nil {
+		return err
+	}
+	var version int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return err
+	}
+	if version != from {
+		return fmt.Errorf("schema version changed to %d while migrating from %d", version, from)
+	}
+	if _, err := tx.Exec(script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,296 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_tasks.up.sql":   {Data: []byte("CREATE TABLE tasks()")},
+		"0001_create_tasks.down.sql": {Data: []byte("DROP TABLE tasks")},
+		"0002_add_owner.up.sql":      {Data: []byte("ALTER TABLE tasks ADD COLUMN owner_id TEXT")},
+		"0002_add_owner.down.sql":    {Data: []byte("ALTER TABLE tasks DROP COLUMN owner_id")},
+		"0003_add_search.up.sql":     {Data: []byte("ALTER TABLE tasks ADD COLUMN search_vector tsvector")},
+		"0003_add_search.down.sql":   {Data: []byte("ALTER TABLE tasks DROP COLUMN search_vector")},
+	}
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(version\\), 0\\) FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version))
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("embedded migrations are valid", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+
+		migrator, err := New(db)
+
+		if err != nil {
+			t.Errorf("embedded migrations are invalid: %v", err)
+			return
+		}
+		if migrator.Latest() == 0 {
+			t.Errorf("expected embedded migrations but found none")
+		}
+	})
+	t.Run("migrations are ordered by version", func(t *testing.T) {
+		migrations, err := load(testFS())
+
+		if err != nil {
+			t.Errorf("load error: %v", err)
+			return
+		}
+		for i, migration := range migrations {
+			if migration.Version != i+1 {
+				t.Errorf("expected version %d at position %d but got %d", i+1, i, migration.Version)
+			}
+		}
+	})
+	t.Run("fail without a down migration", func(t *testing.T) {
+		fsys := testFS()
+		delete(fsys, "0002_add_owner.down.sql")
+
+		_, err := load(fsys)
+
+		if err == nil {
+			t.Errorf("expected error for missing down migration")
+		}
+	})
+	t.Run("fail with a version gap", func(t *testing.T) {
+		fsys := testFS()
+		delete(fsys, "0002_add_owner.up.sql")
+		delete(fsys, "0002_add_owner.down.sql")
+
+		_, err := load(fsys)
+
+		if err == nil {
+			t.Errorf("expected error for missing version 2")
+		}
+	})
+}
+
+func TestPending(t *testing.T) {
+	t.Run("pending migrations after the schema version", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 1)
+		migrator, _ := newMigrator(db, testFS())
+
+		pending, err := migrator.Pending()
+
+		if err != nil {
+			t.Errorf("Pending error: %v", err)
+			return
+		}
+		if len(pending) != 2 || pending[0].Version != 2 {
+			t.Errorf("expected migrations 2 and 3 pending but got %v", pending)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("fail when the schema is newer than the migrations", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 4)
+		migrator, _ := newMigrator(db, testFS())
+
+		_, err = migrator.Pending()
+
+		if err == nil {
+			t.Errorf("expected error for unknown schema version")
+		}
+	})
+}
+
+func TestUp(t *testing.T) {
+	t.Run("apply pending migrations in transactions", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 1)
+		for _, version := range []int{2, 3} {
+			mock.ExpectBegin()
+			mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT COALESCE").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version - 1))
+			mock.ExpectExec("ALTER TABLE tasks ADD COLUMN").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(version, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+		migrator, _ := newMigrator(db, testFS())
+
+		applied, err := migrator.Up(0)
+
+		if err != nil {
+			t.Errorf("Up error: %v", err)
+			return
+		}
+		if len(applied) != 2 {
+			t.Errorf("expected 2 applied migrations but got %d", len(applied))
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("roll back a failed migration", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 0)
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT COALESCE").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(0))
+		mock.ExpectExec("CREATE TABLE tasks").WillReturnError(sqlmock.ErrCancelled)
+		mock.ExpectRollback()
+		migrator, _ := newMigrator(db, testFS())
+
+		applied, err := migrator.Up(1)
+
+		if err == nil {
+			t.Errorf("expected error from failed migration")
+		}
+		if len(applied) != 0 {
+			t.Errorf("expected no applied migrations but got %d", len(applied))
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestDown(t *testing.T) {
+	t.Run("revert the last migration", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 3)
+		mock.ExpectBegin()
+		mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT COALESCE").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectExec("ALTER TABLE tasks DROP COLUMN search_vector").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM schema_migrations").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		migrator, _ := newMigrator(db, testFS())
+
+		reverted, err := migrator.Down(1)
+
+		if err != nil {
+			t.Errorf("Down error: %v", err)
+			return
+		}
+		if len(reverted) != 1 || reverted[0].Version != 3 {
+			t.Errorf("expected migration 3 reverted but got %v", reverted)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestAssignOwner(t *testing.T) {
+	t.Run("assign the tasks without an owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 3)
+		mock.ExpectExec("UPDATE tasks SET owner_id").WithArgs("legacy-user").WillReturnResult(sqlmock.NewResult(0, 4))
+		migrator, _ := newMigrator(db, testFS())
+
+		assigned, err := migrator.AssignOwner("legacy-user")
+
+		if err != nil {
+			t.Errorf("AssignOwner error: %v", err)
+			return
+		}
+		if assigned != 4 {
+			t.Errorf("expected 4 assigned tasks but got %d", assigned)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("skip before tasks have owners", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 1)
+		migrator, _ := newMigrator(db, testFS())
+
+		assigned, err := migrator.AssignOwner("legacy-user")
+
+		if err != nil {
+			t.Errorf("AssignOwner error: %v", err)
+			return
+		}
+		if assigned != 0 {
+			t.Errorf("expected no assigned tasks but got %d", assigned)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("fail with an empty owner", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		migrator, _ := newMigrator(db, testFS())
+
+		_, err = migrator.AssignOwner("")
+
+		if err == nil {
+			t.Errorf("expected error for empty owner")
+		}
+	})
+}
+
+func TestUnowned(t *testing.T) {
+	t.Run("count the tasks without an owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		expectVersion(mock, 2)
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tasks WHERE owner_id = ''").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		migrator, _ := newMigrator(db, testFS())
+
+		unowned, err := migrator.Unowned()
+
+		if err != nil {
+			t.Errorf("Unowned error: %v", err)
+			return
+		}
+		if unowned != 2 {
+			t.Errorf("expected 2 unowned tasks but got %d", unowned)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
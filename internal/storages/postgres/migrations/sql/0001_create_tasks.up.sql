@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS tasks(
+	id VARCHAR(256) PRIMARY KEY,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL,
+	is_completed BOOLEAN NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
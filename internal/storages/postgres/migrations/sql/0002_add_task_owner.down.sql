@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS tasks_owner_id_title_idx;
+DROP INDEX IF EXISTS tasks_owner_id_updated_at_idx;
+DROP INDEX IF EXISTS tasks_owner_id_created_at_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,5 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS owner_id VARCHAR(256) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS tasks_owner_id_created_at_idx ON tasks(owner_id, created_at, id);
+CREATE INDEX IF NOT EXISTS tasks_owner_id_updated_at_idx ON tasks(owner_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS tasks_owner_id_title_idx ON tasks(owner_id, title, id);
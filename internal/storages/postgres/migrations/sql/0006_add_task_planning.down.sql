@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS tasks_owner_id_due_at_idx;
+
+ALTER TABLE tasks DROP COLUMN is_completed;
+ALTER TABLE tasks ADD COLUMN is_completed BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE tasks SET is_completed = (status = 'done');
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
+ALTER TABLE tasks DROP COLUMN IF EXISTS status;
@@ -0,0 +1,13 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'todo'
+	CHECK (status IN ('todo', 'in_progress', 'blocked', 'done'));
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'medium'
+	CHECK (priority IN ('low', 'medium', 'high', 'urgent'));
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE tasks SET status = 'done' WHERE is_completed;
+
+-- is_completed follows the status from now on
+ALTER TABLE tasks DROP COLUMN is_completed;
+ALTER TABLE tasks ADD COLUMN is_completed BOOLEAN GENERATED ALWAYS AS (status = 'done') STORED;
+
+CREATE INDEX IF NOT EXISTS tasks_owner_id_due_at_idx ON tasks(owner_id, due_at) WHERE due_at IS NOT NULL;
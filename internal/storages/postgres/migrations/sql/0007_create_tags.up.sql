@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS tags(
+	id BIGSERIAL PRIMARY KEY,
+	owner_id VARCHAR(256) NOT NULL,
+	name VARCHAR(64) NOT NULL,
+	UNIQUE (owner_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS task_tags(
+	task_id VARCHAR(256) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (task_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_tags_tag_id_idx ON task_tags(tag_id, task_id);
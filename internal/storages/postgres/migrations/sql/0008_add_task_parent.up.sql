@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id VARCHAR(256) REFERENCES tasks(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS tasks_parent_id_idx ON tasks(parent_id) WHERE parent_id IS NOT NULL;
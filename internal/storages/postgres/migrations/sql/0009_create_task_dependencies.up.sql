@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS task_dependencies(
+	task_id VARCHAR(256) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	blocker_id VARCHAR(256) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (task_id, blocker_id),
+	CHECK (task_id <> blocker_id)
+);
+
+CREATE INDEX IF NOT EXISTS task_dependencies_blocker_id_idx ON task_dependencies(blocker_id, task_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS tasks_series_id_idx;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS series_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS recurrence;
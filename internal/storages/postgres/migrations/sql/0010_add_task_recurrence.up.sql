@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence VARCHAR(512);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id VARCHAR(256);
+
+CREATE INDEX IF NOT EXISTS tasks_series_id_idx ON tasks(series_id) WHERE series_id IS NOT NULL;
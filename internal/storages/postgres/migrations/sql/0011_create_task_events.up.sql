@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS task_events(
+	id BIGSERIAL PRIMARY KEY,
+	task_id VARCHAR(256) NOT NULL,
+	owner_id VARCHAR(256) NOT NULL,
+	actor_id VARCHAR(256) NOT NULL,
+	action VARCHAR(16) NOT NULL,
+	changes JSONB NOT NULL DEFAULT '[]',
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events(task_id, id);
+CREATE INDEX IF NOT EXISTS task_events_actor_id_idx ON task_events(actor_id, created_at);
+CREATE INDEX IF NOT EXISTS task_events_created_at_idx ON task_events(created_at);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS webhooks(
+	id VARCHAR(256) PRIMARY KEY,
+	owner_id VARCHAR(256) NOT NULL,
+	url TEXT NOT NULL,
+	events TEXT[] NOT NULL,
+	secret TEXT NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_owner_id_idx ON webhooks(owner_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries(
+	id BIGSERIAL PRIMARY KEY,
+	webhook_id VARCHAR(256) NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	owner_id VARCHAR(256) NOT NULL,
+	event VARCHAR(32) NOT NULL,
+	task_id VARCHAR(256) NOT NULL,
+	payload JSONB NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	response_status INTEGER,
+	last_error TEXT,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries(next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries(webhook_id, id);
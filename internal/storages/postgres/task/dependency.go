@@ -0,0 +1,86 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+)
+
+// AddDependency blocks the task by the blocker, adding it again is a no-op.
+// Nothing is added unless both tasks belong to the owner.
+func (pg *PgTaskRepository) AddDependency(ownerId, taskId, blockerId string) error {
+	_, err := pg.conn().Exec(`INSERT INTO task_dependencies(task_id, blocker_id, created_at)
+			SELECT id, ($2), ($4) FROM tasks WHERE id = ($1) AND owner_id = ($3)
+			AND EXISTS (SELECT 1 FROM tasks WHERE id = ($2) AND owner_id = ($3) AND deleted_at IS NULL)
+			ON CONFLICT DO NOTHING`, taskId, blockerId, ownerId, time.Now())
+	return err
+}
+
+func (pg *PgTaskRepository) RemoveDependency(ownerId, taskId, blockerId string) error {
+	res, err := pg.conn().Exec("DELETE FROM task_dependencies WHERE task_id = ($1) AND blocker_id = ($2) AND task_id IN (SELECT id FROM tasks WHERE owner_id = ($3))", taskId, blockerId, ownerId)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.NotFoundError(fmt.Sprintf("Task with ID %s is not blocked by %s", taskId, blockerId))
+	}
+	return nil
+}
+
+// ListDependencies returns the dependencies between the tasks of the owner
+// that are not in the trash, oldest first.
+func (pg *PgTaskRepository) ListDependencies(ownerId string) ([]task.Dependency, error) {
+	query := `SELECT task_dependencies.task_id, task_dependencies.blocker_id FROM task_dependencies
+			JOIN tasks ON tasks.id = task_dependencies.task_id
+			JOIN tasks AS blockers ON blockers.id = task_dependencies.blocker_id
+			WHERE tasks.owner_id = ($1) AND tasks.deleted_at IS NULL AND blockers.deleted_at IS NULL
+			ORDER BY task_dependencies.created_at, task_dependencies.task_id, task_dependencies.blocker_id`
+
+	dependencies := []task.Dependency{}
+	rows, err := pg.conn().Query(query, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dependency task.Dependency
+		if err := rows.Scan(&dependency.TaskId, &dependency.BlockerId); err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, rows.Err()
+}
+
+// Blockers returns the tasks that block the task, by creation.
+func (pg *PgTaskRepository) Blockers(ownerId, taskId string) ([]task.Task, error) {
+	return pg.dependentTasks("SELECT blocker_id FROM task_dependencies WHERE task_id = ($2)", ownerId, taskId)
+}
+
+// Blocking returns the tasks that the task blocks, by creation.
+func (pg *PgTaskRepository) Blocking(ownerId, taskId string) ([]task.Task, error) {
+	return pg.dependentTasks("SELECT task_id FROM task_dependencies WHERE blocker_id = ($2)", ownerId, taskId)
+}
+
+func (pg *PgTaskRepository) dependentTasks(ids, ownerId, taskId string) ([]task.Task, error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE owner_id = ($1) AND deleted_at IS NULL AND id IN (" + ids + ") ORDER BY created_at, id"
+
+	tasks := []task.Task{}
+	rows, err := pg.conn().Query(query, ownerId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t := &task.Task{}
+		if err := rows.Scan(taskFields(t)...); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+
+	return tasks, rows.Err()
+}
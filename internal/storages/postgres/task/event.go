@@ -0,0 +1,80 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+)
+
+func (pg *PgTaskRepository) AddEvent(event task.Event) error {
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = pg.conn().Exec("INSERT INTO task_events(task_id, owner_id, actor_id, action, changes, created_at) VALUES ($1, $2, $3, $4, $5, $6)", event.TaskId, event.OwnerId, event.ActorId, event.Action, changes, event.CreatedAt)
+	return err
+}
+
+// ListEvents returns the events that pass the options in the order they
+// happened, they stay after their task is purged.
+func (pg *PgTaskRepository) ListEvents(options task.EventOptions) ([]task.Event, error) {
+	args := []any{}
+	conditions := []string{}
+	if options.OwnerId != nil {
+		args = append(args, *options.OwnerId)
+		conditions = append(conditions, fmt.Sprintf("owner_id = ($%d)", len(args)))
+	}
+	if options.TaskId != nil {
+		args = append(args, *options.TaskId)
+		conditions = append(conditions, fmt.Sprintf("task_id = ($%d)", len(args)))
+	}
+	if options.ActorId != nil {
+		args = append(args, *options.ActorId)
+		conditions = append(conditions, fmt.Sprintf("actor_id = ($%d)", len(args)))
+	}
+	if options.From != nil {
+		args = append(args, *options.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= ($%d)", len(args)))
+	}
+	if options.To != nil {
+		args = append(args, *options.To)
+		conditions = append(conditions, fmt.Sprintf("created_at <= ($%d)", len(args)))
+	}
+	if options.After > 0 {
+		args = append(args, options.After)
+		conditions = append(conditions, fmt.Sprintf("id > ($%d)", len(args)))
+	}
+
+	query := "SELECT id, task_id, owner_id, actor_id, action, changes, created_at FROM task_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id"
+	if options.Limit > 0 {
+		args = append(args, options.Limit)
+		query += fmt.Sprintf(" LIMIT ($%d)", len(args))
+	}
+
+	events := []task.Event{}
+	rows, err := pg.conn().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var event task.Event
+		var changes []byte
+		if err := rows.Scan(&event.Id, &event.TaskId, &event.OwnerId, &event.ActorId, &event.Action, &changes, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &event.Changes); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
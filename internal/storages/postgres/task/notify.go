@@ -0,0 +1,123 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/lib/pq"
+)
+
+// ChangesChannel is the channel the task writes are notified on.
+const ChangesChannel = "task_changes"
+
+const (
+	// a lost listener connection is tried again after minReconnectInterval,
+	// doubling up to maxReconnectInterval
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	// pingInterval checks that a quiet listener connection is still up
+	pingInterval = 90 * time.Second
+	// listenTimeout bounds the wait for the first listener connection
+	listenTimeout = 30 * time.Second
+)
+
+// The actions of the writes, named here since the task variables of the
+// writes hide the task package.
+const (
+	actionCreate  = task.ActionCreate
+	actionUpdate  = task.ActionUpdate
+	actionDelete  = task.ActionDelete
+	actionRestore = task.ActionRestore
+)
+
+// notification is the payload of a notification, it only names the task
+// since a payload is limited to 8000 bytes.
+type notification struct {
+	TaskId  string `json:"task_id"`
+	OwnerId string `json:"owner_id"`
+	Op      string `json:"op"`
+}
+
+// notify tells the listeners about a write to a task, they receive it once
+// the transaction of the write commits.
+func notify(db querier, ownerId, taskId, action string) error {
+	payload, err := json.Marshal(notification{TaskId: taskId, OwnerId: ownerId, Op: action})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("SELECT pg_notify($1, $2)", ChangesChannel, string(payload))
+	return err
+}
+
+func decodeNotification(payload string) (task.Notification, error) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		return task.Notification{}, err
+	}
+	return task.Notification{TaskId: n.TaskId, OwnerId: n.OwnerId, Action: n.Op}, nil
+}
+
+// ListenChanges starts calling onChange with the task writes of every client
+// of the database until ctx is done, it fails when it can't listen to them.
+// A lost connection is established again on its own, onReset is called then
+// since the writes in between are not notified.
+func ListenChanges(ctx context.Context, dsn string, onChange func(task.Notification), onReset func()) error {
+	listener := pq.NewListener(dsn, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("task changes listener error: %v", err)
+		}
+	})
+
+	// Listen waits for the connection as long as it takes
+	listening := make(chan error, 1)
+	go func() {
+		listening <- listener.Listen(ChangesChannel)
+	}()
+	select {
+	case err := <-listening:
+		if err != nil {
+			listener.Close()
+			return err
+		}
+	case <-time.After(listenTimeout):
+		listener.Close()
+		return fmt.Errorf("listen on %s timed out after %v", ChangesChannel, listenTimeout)
+	case <-ctx.Done():
+		listener.Close()
+		return ctx.Err()
+	}
+
+	go followChanges(ctx, listener, onChange, onReset)
+	return nil
+}
+
+func followChanges(ctx context.Context, listener *pq.Listener, onChange func(task.Notification), onReset func()) {
+	defer listener.Close()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				onReset()
+				continue
+			}
+			change, err := decodeNotification(n.Extra)
+			if err != nil {
+				log.Printf("task changes listener error: %v", err)
+				continue
+			}
+			onChange(change)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
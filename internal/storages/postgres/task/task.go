@@ -3,15 +3,45 @@ package task
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Arup3201/gotasks/internal/entities/task"
 	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/lib/pq"
 )
 
+// tagsColumn collects the tag names of a task into a sorted array.
+const tagsColumn = "ARRAY(SELECT tags.name FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE task_tags.task_id = tasks.id ORDER BY tags.name)"
+
+const taskColumns = "id, owner_id, title, description, status, priority, due_at, is_completed, version, created_at, updated_at, deleted_at, " + tagsColumn + ", parent_id, recurrence, series_id"
+
+// taskFields are the scan destinations of taskColumns.
+func taskFields(t *task.Task) []any {
+	return []any{&t.Id, &t.OwnerId, &t.Title, &t.Description, &t.Status, &t.Priority, &t.DueAt, &t.IsCompleted, &t.Version, &t.CreatedAt, &t.UpdatedAt, &t.DeletedAt, pq.Array(&t.Tags), &t.ParentId, &t.Recurrence, &t.SeriesId}
+}
+
+// sortColumns maps the supported sort fields to their columns, anything
+// else is never interpolated into a query.
+var sortColumns = map[string]string{
+	task.SortByCreatedAt: "created_at",
+	task.SortByUpdatedAt: "updated_at",
+	task.SortByTitle:     "title",
+}
+
+// PgTaskRepository notifies every write to a task on ChangesChannel.
 type PgTaskRepository struct {
 	db *sql.DB
+	// tx is set on the repositories of InTransaction, their queries run in
+	// it instead of on db.
+	tx *sql.Tx
+}
+
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 func NewPgTaskRepository(db *sql.DB) *PgTaskRepository {
@@ -20,9 +50,9 @@ func NewPgTaskRepository(db *sql.DB) *PgTaskRepository {
 	}
 }
 
-func (pg *PgTaskRepository) Get(taskId string) (*task.Task, error) {
+func (pg *PgTaskRepository) Get(ownerId, taskId string) (*task.Task, error) {
 	var task task.Task
-	if err := pg.db.QueryRow("SELECT * FROM tasks WHERE id = ($1)", taskId).Scan(&task.Id, &task.Title, &task.Description, &task.IsCompleted, &task.CreatedAt, &task.UpdatedAt); err != nil {
+	if err := pg.conn().QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = ($1) AND owner_id = ($2) AND deleted_at IS NULL", taskId, ownerId).Scan(taskFields(&task)...); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
 		}
@@ -31,86 +61,456 @@ func (pg *PgTaskRepository) Get(taskId string) (*task.Task, error) {
 	return &task, nil
 }
 
-func (pg *PgTaskRepository) Insert(taskId string, taskTitle, taskDesc string) (*task.Task, error) {
+func (pg *PgTaskRepository) Insert(ownerId, taskId string, taskTitle, taskDesc string, details task.Details) (*task.Task, error) {
+	details = details.WithDefaults()
 	task := task.Task{
 		Id:          taskId,
+		OwnerId:     ownerId,
 		Title:       taskTitle,
 		Description: taskDesc,
-		IsCompleted: false,
+		Tags:        []string{},
+		Priority:    details.Priority,
+		DueAt:       details.DueAt,
+		ParentId:    details.ParentId,
+		Recurrence:  details.Recurrence,
+		SeriesId:    details.SeriesId,
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	_, err := pg.db.Exec("INSERT INTO tasks(id, title, description, is_completed, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)", task.Id, task.Title, task.Description, task.IsCompleted, task.CreatedAt, task.UpdatedAt)
+	task.SetStatus(details.Status)
+	err := pg.transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec("INSERT INTO tasks(id, owner_id, title, description, status, priority, due_at, parent_id, recurrence, series_id, version, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)", task.Id, task.OwnerId, task.Title, task.Description, task.Status, task.Priority, task.DueAt, task.ParentId, task.Recurrence, task.SeriesId, task.Version, task.CreatedAt, task.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		return notify(tx, ownerId, taskId, actionCreate)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (pg *PgTaskRepository) Update(taskId string, data map[string]any) (*task.Task, error) {
-	_, err := pg.Get(taskId)
-	if err != nil {
-		return nil, err
-	}
-
+// Update changes the task only while it is at the given version, any version
+// matches when it is nil.
+func (pg *PgTaskRepository) Update(ownerId, taskId string, version *int, data map[string]any) (*task.Task, error) {
 	setFields := []string{}
+	args := []any{taskId, ownerId}
 
 	title, ok := data["Title"]
 	if ok {
-		setFields = append(setFields, fmt.Sprintf("title='%s'", title))
+		args = append(args, title)
+		setFields = append(setFields, fmt.Sprintf("title = ($%d)", len(args)))
 	}
 	description, ok := data["Description"]
 	if ok {
-		setFields = append(setFields, fmt.Sprintf("description='%s'", description))
+		args = append(args, description)
+		setFields = append(setFields, fmt.Sprintf("description = ($%d)", len(args)))
+	}
+	status, ok := data["Status"]
+	if ok {
+		args = append(args, status)
+		setFields = append(setFields, fmt.Sprintf("status = ($%d)", len(args)))
+	}
+	priority, ok := data["Priority"]
+	if ok {
+		args = append(args, priority)
+		setFields = append(setFields, fmt.Sprintf("priority = ($%d)", len(args)))
+	}
+	dueAt, ok := data["DueAt"]
+	if ok {
+		args = append(args, dueAt)
+		setFields = append(setFields, fmt.Sprintf("due_at = ($%d)", len(args)))
 	}
-	isCompleted, ok := data["IsCompleted"]
+	parentId, ok := data["ParentId"]
 	if ok {
-		setFields = append(setFields, fmt.Sprintf("is_completed=%t", isCompleted))
+		args = append(args, parentId)
+		setFields = append(setFields, fmt.Sprintf("parent_id = ($%d)", len(args)))
+	}
+	recurrence, ok := data["Recurrence"]
+	if ok {
+		args = append(args, recurrence)
+		setFields = append(setFields, fmt.Sprintf("recurrence = ($%d)", len(args)))
+	}
+	seriesId, ok := data["SeriesId"]
+	if ok {
+		args = append(args, seriesId)
+		setFields = append(setFields, fmt.Sprintf("series_id = ($%d)", len(args)))
 	}
 
 	if len(setFields) == 0 {
 		return nil, errors.NoOp("Found no fields to update")
 	}
 
-	execString := fmt.Sprintf("UPDATE tasks SET %s WHERE id=($1)", strings.Join(setFields, ", "))
-	_, err = pg.db.Exec(execString, taskId)
+	args = append(args, time.Now())
+	setFields = append(setFields, fmt.Sprintf("updated_at = ($%d)", len(args)), "version = version + 1")
+
+	conditions := "id = ($1) AND owner_id = ($2) AND deleted_at IS NULL"
+	if version != nil {
+		args = append(args, *version)
+		conditions += fmt.Sprintf(" AND version = ($%d)", len(args))
+	}
+
+	var task task.Task
+	query := fmt.Sprintf("UPDATE tasks SET %s WHERE %s RETURNING %s", strings.Join(setFields, ", "), conditions, taskColumns)
+	err := pg.transaction(func(tx *sql.Tx) error {
+		if err := tx.QueryRow(query, args...).Scan(taskFields(&task)...); err != nil {
+			if err == sql.ErrNoRows {
+				return missingOrConflict(tx, ownerId, taskId, version)
+			}
+			return err
+		}
+		return notify(tx, ownerId, taskId, actionUpdate)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return pg.Get(taskId)
+	return &task, nil
 }
 
-func (pg *PgTaskRepository) Delete(taskId string) (*string, error) {
-	res, err := pg.db.Exec("DELETE FROM tasks WHERE id=($1)", taskId)
+// Delete moves the task to the trash only while it is at the given version,
+// any version matches when it is nil.
+func (pg *PgTaskRepository) Delete(ownerId, taskId string, version *int) (*string, error) {
+	query := "UPDATE tasks SET deleted_at = ($3), version = version + 1 WHERE id = ($1) AND owner_id = ($2) AND deleted_at IS NULL"
+	args := []any{taskId, ownerId, time.Now()}
+	if version != nil {
+		args = append(args, *version)
+		query += " AND version = ($4)"
+	}
+
+	err := pg.transaction(func(tx *sql.Tx) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return missingOrConflict(tx, ownerId, taskId, version)
+		}
+		return notify(tx, ownerId, taskId, actionDelete)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if n, _ := res.RowsAffected(); n == 0 {
-		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	return &taskId, nil
+}
+
+// Restore takes the task out of the trash.
+func (pg *PgTaskRepository) Restore(ownerId, taskId string) (*task.Task, error) {
+	var task task.Task
+	query := "UPDATE tasks SET deleted_at = NULL, updated_at = ($3), version = version + 1 WHERE id = ($1) AND owner_id = ($2) AND deleted_at IS NOT NULL RETURNING " + taskColumns
+	err := pg.transaction(func(tx *sql.Tx) error {
+		if err := tx.QueryRow(query, taskId, ownerId, time.Now()).Scan(taskFields(&task)...); err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NotFoundError(fmt.Sprintf("Task with ID %s not found in trash", taskId))
+			}
+			return err
+		}
+		return notify(tx, ownerId, taskId, actionRestore)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Purge permanently removes the tasks of every owner that were moved to the
+// trash before the given time.
+func (pg *PgTaskRepository) Purge(deletedBefore time.Time) (int64, error) {
+	res, err := pg.conn().Exec("DELETE FROM tasks WHERE deleted_at < ($1)", deletedBefore)
+	if err != nil {
+		return 0, err
 	}
+	return res.RowsAffected()
+}
 
-	return &taskId, nil
+// SetTags replaces the tags of the task, tags the owner did not use before
+// are created on the way. The task is changed only while it is at the given
+// version, any version matches when it is nil.
+func (pg *PgTaskRepository) SetTags(ownerId, taskId string, version *int, tags []string) (*task.Task, error) {
+	query := "UPDATE tasks SET updated_at = ($3), version = version + 1 WHERE id = ($1) AND owner_id = ($2) AND deleted_at IS NULL"
+	args := []any{taskId, ownerId, time.Now()}
+	if version != nil {
+		args = append(args, *version)
+		query += " AND version = ($4)"
+	}
+
+	var task task.Task
+	err := pg.transaction(func(tx *sql.Tx) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return missingOrConflict(tx, ownerId, taskId, version)
+		}
+
+		names := pq.Array(tags)
+		if _, err := tx.Exec("INSERT INTO tags(owner_id, name) SELECT ($1), unnest(($2)::text[]) ON CONFLICT (owner_id, name) DO NOTHING", ownerId, names); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM task_tags WHERE task_id = ($1) AND tag_id NOT IN (SELECT id FROM tags WHERE owner_id = ($2) AND name = ANY(($3)::text[]))", taskId, ownerId, names); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO task_tags(task_id, tag_id) SELECT ($1), id FROM tags WHERE owner_id = ($2) AND name = ANY(($3)::text[]) ON CONFLICT DO NOTHING", taskId, ownerId, names); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = ($1) AND owner_id = ($2)", taskId, ownerId).Scan(taskFields(&task)...); err != nil {
+			return err
+		}
+		return notify(tx, ownerId, taskId, actionUpdate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// ListTags returns every tag of the owner by name, counting only the tasks
+// that are not in the trash.
+func (pg *PgTaskRepository) ListTags(ownerId string) ([]task.TagCount, error) {
+	query := `SELECT tags.name, COUNT(tasks.id) FROM tags
+			LEFT JOIN task_tags ON task_tags.tag_id = tags.id
+			LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL
+			WHERE tags.owner_id = ($1)
+			GROUP BY tags.name
+			ORDER BY tags.name`
+
+	tags := []task.TagCount{}
+	rows, err := pg.conn().Query(query, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag task.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
 }
 
-func (pg *PgTaskRepository) List() ([]task.Task, error) {
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// missingOrConflict tells why a conditional write did not touch the task,
+// either it does not exist or it is no longer at the expected version.
+func missingOrConflict(db queryRower, ownerId, taskId string, version *int) error {
+	notFound := errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	if version == nil {
+		return notFound
+	}
+
+	var current int
+	if err := db.QueryRow("SELECT version FROM tasks WHERE id = ($1) AND owner_id = ($2) AND deleted_at IS NULL", taskId, ownerId).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return notFound
+		}
+		return err
+	}
+
+	return errors.PreconditionFailedError(fmt.Sprintf("Task with ID %s is at version %d, not %d", taskId, current, *version))
+}
+
+func (pg *PgTaskRepository) List(ownerId string, options task.ListOptions) ([]task.Task, error) {
+	conditions := []string{"owner_id = ($1)", "deleted_at IS NULL"}
+	if options.Deleted {
+		conditions[1] = "deleted_at IS NOT NULL"
+	}
+	args := []any{ownerId}
+
+	if options.IsCompleted != nil {
+		args = append(args, *options.IsCompleted)
+		conditions = append(conditions, fmt.Sprintf("is_completed = ($%d)", len(args)))
+	}
+	if options.CreatedAfter != nil {
+		args = append(args, *options.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at > ($%d)", len(args)))
+	}
+	if options.ParentId != nil {
+		args = append(args, *options.ParentId)
+		conditions = append(conditions, fmt.Sprintf("parent_id = ($%d)", len(args)))
+	}
+	if options.SeriesId != nil {
+		args = append(args, *options.SeriesId)
+		conditions = append(conditions, fmt.Sprintf("series_id = ($%d)", len(args)))
+	}
+	if len(options.Tags) > 0 {
+		args = append(args, pq.Array(options.Tags))
+		tagged := fmt.Sprintf("SELECT task_tags.task_id FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE tags.owner_id = ($1) AND tags.name = ANY(($%d)::text[])", len(args))
+		if options.AllTags {
+			args = append(args, len(options.Tags))
+			tagged += fmt.Sprintf(" GROUP BY task_tags.task_id HAVING COUNT(*) = ($%d)", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf("id IN (%s)", tagged))
+	}
+
+	sortColumn := sortColumns[options.SortBy]
+	if sortColumn == "" {
+		sortColumn = sortColumns[task.SortByCreatedAt]
+	}
+	direction, comparison := "ASC", ">"
+	if options.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if options.After != nil {
+		args = append(args, options.After.Value, options.After.Id)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM tasks WHERE %s ORDER BY %s %s, id %s", taskColumns, strings.Join(conditions, " AND "), sortColumn, direction, direction)
+	if options.Limit > 0 {
+		args = append(args, options.Limit)
+		query += fmt.Sprintf(" LIMIT ($%d)", len(args))
+	}
+
 	var tasks []task.Task
-	rows, err := pg.db.Query("SELECT * FROM tasks")
+	rows, err := pg.conn().Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		t := &task.Task{}
-		if err := rows.Scan(&t.Id, &t.Title, &t.Description, &t.IsCompleted, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := rows.Scan(taskFields(t)...); err != nil {
 			return nil, err
 		}
 		tasks = append(tasks, *t)
 	}
 
+	return tasks, rows.Err()
+}
+
+// Subtree returns the task and the subtasks below it that are not in the
+// trash, down to MaxDepth levels, by creation.
+func (pg *PgTaskRepository) Subtree(ownerId, taskId string) ([]task.Task, error) {
+	query := `WITH RECURSIVE subtree(id, depth) AS (
+				SELECT id, 1 FROM tasks WHERE id = ($1) AND owner_id = ($2) AND deleted_at IS NULL
+				UNION ALL
+				SELECT tasks.id, subtree.depth + 1 FROM tasks JOIN subtree ON tasks.parent_id = subtree.id
+				WHERE tasks.owner_id = ($2) AND tasks.deleted_at IS NULL AND subtree.depth < ($3)
+			)
+			SELECT ` + taskColumns + ` FROM tasks WHERE id IN (SELECT id FROM subtree) ORDER BY created_at, id`
+
+	var tasks []task.Task
+	rows, err := pg.conn().Query(query, taskId, ownerId, task.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		t := &task.Task{}
+		if err := rows.Scan(taskFields(t)...); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, errors.NotFoundError(fmt.Sprintf("Task with ID %s not found", taskId))
+	}
+
 	return tasks, nil
 }
 
+func (pg *PgTaskRepository) Search(ownerId string, options task.SearchOptions) ([]task.SearchResult, error) {
+	args := []any{ownerId, tsQuery(options.Terms)}
+	query := `SELECT ` + taskColumns + `, ts_rank_cd(search_vector, query) AS rank,
+				ts_headline('english', title, query, 'StartSel=<b>, StopSel=</b>, HighlightAll=true'),
+				ts_headline('english', description, query, 'StartSel=<b>, StopSel=</b>, MaxFragments=2')
+			FROM tasks, to_tsquery('english', ($2)) query
+			WHERE owner_id = ($1) AND deleted_at IS NULL AND search_vector @@ query
+			ORDER BY rank DESC, created_at ASC, id ASC`
+	if options.Limit > 0 {
+		args = append(args, options.Limit)
+		query += fmt.Sprintf(" LIMIT ($%d)", len(args))
+	}
+	if options.Offset > 0 {
+		args = append(args, options.Offset)
+		query += fmt.Sprintf(" OFFSET ($%d)", len(args))
+	}
+
+	results := []task.SearchResult{}
+	rows, err := pg.conn().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r := &task.SearchResult{}
+		if err := rows.Scan(append(taskFields(&r.Task), &r.Rank, &r.Highlight, &r.Snippet)...); err != nil {
+			return nil, err
+		}
+		results = append(results, *r)
+	}
+
+	return results, rows.Err()
+}
+
+// tsQuery builds the to_tsquery input for the search terms. Terms only hold
+// letters and digits, so none of them can change the query syntax.
+func tsQuery(terms []task.SearchTerm) string {
+	parts := []string{}
+	for _, term := range terms {
+		words := slices.Clone(term.Words)
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+		part := strings.Join(words, " <-> ")
+		if len(words) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// InTransaction runs fn with a repository whose writes are committed
+// together once fn succeeds, and all rolled back when it fails.
+func (pg *PgTaskRepository) InTransaction(fn func(repo *PgTaskRepository) error) error {
+	return pg.transaction(func(tx *sql.Tx) error {
+		return fn(&PgTaskRepository{db: pg.db, tx: tx})
+	})
+}
+
+// conn is the transaction of the repository, or the database outside of one.
+func (pg *PgTaskRepository) conn() querier {
+	if pg.tx != nil {
+		return pg.tx
+	}
+	return pg.db
+}
+
+// transaction runs fn in the transaction of the repository, or in a new one
+// that is committed when fn succeeds.
+func (pg *PgTaskRepository) transaction(fn func(tx *sql.Tx) error) error {
+	if pg.tx != nil {
+		return fn(pg.tx)
+	}
+
+	tx, err := pg.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (pg *PgTaskRepository) Close() error {
 	return pg.db.Close()
 }
@@ -1,16 +1,23 @@
 package task
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	entities "github.com/Arup3201/gotasks/internal/entities/task"
 	"github.com/Arup3201/gotasks/internal/errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 )
 
+const owner = "test-owner"
+
+var columns = []string{"id", "owner_id", "title", "description", "status", "priority", "due_at", "is_completed", "version", "created_at", "updated_at", "deleted_at", "tags", "parent_id", "recurrence", "series_id"}
+
 type AnyTime struct{}
 
 // Match satisfies sqlmock.Argument interface
@@ -29,11 +36,11 @@ func TestPgGet(t *testing.T) {
 		uuid, _ := uuid.NewUUID()
 		id := uuid.String()
 		title, description := "Test task", "Test task description"
-		rows := sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "created_at", "updated_at"}).AddRow(id, title, description, false, time.Now(), time.Now())
-		mock.ExpectQuery("^SELECT (.+) FROM tasks").WithArgs(id).WillReturnRows(rows)
+		rows := sqlmock.NewRows(columns).AddRow(id, owner, title, description, "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil)
+		mock.ExpectQuery("^SELECT (.+) FROM tasks").WithArgs(id, owner).WillReturnRows(rows)
 		pg := NewPgTaskRepository(db)
 
-		task, err := pg.Get(id)
+		task, err := pg.Get(owner, id)
 
 		if err != nil {
 			t.Errorf("pg.Get error: %v", err)
@@ -53,14 +60,14 @@ func TestPgGet(t *testing.T) {
 		defer db.Close()
 		uuid_, _ := uuid.NewUUID()
 		id := uuid_.String()
-		mock.ExpectQuery("^SELECT (.+) FROM tasks").WithArgs(id).WillReturnError(fmt.Errorf("ErrNoRows"))
+		mock.ExpectQuery("^SELECT (.+) FROM tasks").WithArgs(id, owner).WillReturnError(fmt.Errorf("ErrNoRows"))
 		uuid_, _ = uuid.NewUUID()
 		exid := uuid_.String()
 		title, description := "Test task", "Test task description"
 		pg := NewPgTaskRepository(db)
-		pg.Insert(exid, title, description)
+		pg.Insert(owner, exid, title, description, entities.Details{})
 
-		_, err = pg.Get(id)
+		_, err = pg.Get(owner, id)
 
 		if err == nil {
 			t.Errorf("expected error but got nothing")
@@ -69,6 +76,36 @@ func TestPgGet(t *testing.T) {
 			t.Errorf("there were unfulfilled expectations: %s", err)
 		}
 	})
+	t.Run("should not get task of another owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectQuery("^SELECT (.+) FROM tasks WHERE id = (.+) AND owner_id = (.+)").WithArgs(id, "other-owner").WillReturnError(sql.ErrNoRows)
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Get("other-owner", id)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok {
+			t.Errorf("expected not found error, but got %v", err)
+			return
+		}
+		if appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected error type %s, but got %s", errors.NOT_FOUND, appError.Type)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+// expectNotify expects the notification of a write to the task.
+func expectNotify(mock sqlmock.Sqlmock, taskId, action string) {
+	mock.ExpectExec(`^SELECT pg_notify`).WithArgs(ChangesChannel, fmt.Sprintf(`{"task_id":%q,"owner_id":%q,"op":%q}`, taskId, owner, action)).WillReturnResult(sqlmock.NewResult(0, 1))
 }
 
 func TestPgInsert(t *testing.T) {
@@ -82,10 +119,13 @@ func TestPgInsert(t *testing.T) {
 		id := uuid_.String()
 		title := "Test task"
 		description := "Test task description"
-		mock.ExpectExec("INSERT INTO tasks").WithArgs(id, title, description, false, AnyTime{}, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tasks").WithArgs(id, owner, title, description, entities.StatusTodo, entities.PriorityMedium, nil, nil, nil, nil, 1, AnyTime{}, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+		expectNotify(mock, id, entities.ActionCreate)
+		mock.ExpectCommit()
 		pg := NewPgTaskRepository(db)
 
-		task, err := pg.Insert(id, title, description)
+		task, err := pg.Insert(owner, id, title, description, entities.Details{})
 
 		if err != nil {
 			t.Errorf("Insert failed with error: %v", err)
@@ -113,11 +153,17 @@ func TestPgInsert(t *testing.T) {
 		id := uuid_.String()
 		title := "Test task 2"
 		description := "Test task 2 description"
-		mock.ExpectExec("INSERT INTO tasks").WithArgs(id, title, description, false, AnyTime{}, AnyTime{}).WillReturnError(fmt.Errorf("DB integrity error"))
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tasks").WithArgs(id, owner, "Test task 1", "Test task 1 description", entities.StatusTodo, entities.PriorityMedium, nil, nil, nil, nil, 1, AnyTime{}, AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+		expectNotify(mock, id, entities.ActionCreate)
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tasks").WithArgs(id, owner, title, description, entities.StatusTodo, entities.PriorityMedium, nil, nil, nil, nil, 1, AnyTime{}, AnyTime{}).WillReturnError(fmt.Errorf("DB integrity error"))
+		mock.ExpectRollback()
 		pg := NewPgTaskRepository(db)
-		pg.Insert(id, "Test task 1", "Test task 1 description")
+		pg.Insert(owner, id, "Test task 1", "Test task 1 description", entities.Details{})
 
-		_, err = pg.Insert(id, title, description)
+		_, err = pg.Insert(owner, id, title, description, entities.Details{})
 
 		if err == nil {
 			t.Errorf("expecting an error, but there was none")
@@ -138,20 +184,21 @@ func TestPgUpdate(t *testing.T) {
 		defer db.Close()
 		uuid_, _ := uuid.NewUUID()
 		id := uuid_.String()
-		title, description := "Test task", "Test task description"
-		row := sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "created_at", "updated_at"}).AddRow(id, title, description, false, time.Now(), time.Now())
+		description := "Test task description"
 		updateTitle := "Test task (updated)"
-		mock.ExpectQuery("SELECT (.+) FROM tasks").WithArgs(id).WillReturnRows(row)
-		mock.ExpectExec("UPDATE tasks").WillReturnResult(sqlmock.NewResult(0, 1))
-		mock.ExpectQuery("SELECT (.+) FROM tasks").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "created_at", "updated_at"}).AddRow(id, updateTitle, description, false, time.Now(), time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^UPDATE tasks SET title = \(\$3\), updated_at = \(\$4\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NULL RETURNING (.+)$`).WithArgs(id, owner, updateTitle, AnyTime{}).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, owner, updateTitle, description, "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil))
+		expectNotify(mock, id, entities.ActionUpdate)
+		mock.ExpectCommit()
 		pg := NewPgTaskRepository(db)
 
-		task, err := pg.Update(id, map[string]any{
+		task, err := pg.Update(owner, id, nil, map[string]any{
 			"Title": "Test task (updated)",
 		})
 
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
+			return
 		}
 		if task.Title != updateTitle {
 			t.Errorf("task is not updated, expected %s but got %s", updateTitle, task.Title)
@@ -160,6 +207,148 @@ func TestPgUpdate(t *testing.T) {
 			t.Errorf("there were unfulfilled expectations: %s", err)
 		}
 	})
+	t.Run("update all fields", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectBegin()
+		dueAt := time.Now().Add(24 * time.Hour)
+		mock.ExpectQuery(`^UPDATE tasks SET title = \(\$3\), description = \(\$4\), status = \(\$5\), priority = \(\$6\), due_at = \(\$7\), updated_at = \(\$8\), version = version \+ 1 WHERE`).WithArgs(id, owner, "Title", "Description", entities.StatusDone, entities.PriorityHigh, dueAt, AnyTime{}).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, owner, "Title", "Description", "done", "high", dueAt, true, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil))
+		expectNotify(mock, id, entities.ActionUpdate)
+		mock.ExpectCommit()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Update(owner, id, nil, map[string]any{
+			"Title":       "Title",
+			"Description": "Description",
+			"Status":      entities.StatusDone,
+			"Priority":    entities.PriorityHigh,
+			"DueAt":       &dueAt,
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("update an invalid task fail", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectBegin()
+		mock.ExpectQuery("^UPDATE tasks").WithArgs(id, owner, "Title", AnyTime{}).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Update(owner, id, nil, map[string]any{
+			"Title": "Title",
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected not found error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("update a stale version fail", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		version := 1
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^UPDATE tasks SET title = \(\$3\), updated_at = \(\$4\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NULL AND version = \(\$5\) RETURNING (.+)$`).WithArgs(id, owner, "Title", AnyTime{}, version).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectQuery("^SELECT version FROM tasks").WithArgs(id, owner).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectRollback()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Update(owner, id, &version, map[string]any{
+			"Title": "Title",
+		})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition failed error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("update without fields is a no-op", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Update(owner, "1", nil, map[string]any{})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.NO_OPERATION {
+			t.Errorf("expected no-op error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("hostile input is only passed as parameters", func(t *testing.T) {
+		hostileInputs := []string{
+			"Robert'); DROP TABLE tasks;--",
+			"it's done",
+			"' OR '1'='1",
+			`\'; UPDATE tasks SET owner_id = 'me' WHERE '' = '`,
+			"$1 $2 $3",
+		}
+		for _, input := range hostileInputs {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+				if strings.Contains(actualSQL, input) {
+					return fmt.Errorf("input %q is part of the query %q", input, actualSQL)
+				}
+				return sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL)
+			})))
+			if err != nil {
+				t.Fatalf("sqlmock.New error: %v", err)
+			}
+			uuid_, _ := uuid.NewUUID()
+			id := uuid_.String()
+			mock.ExpectBegin()
+			mock.ExpectQuery("^UPDATE tasks").WithArgs(id, owner, input, input, AnyTime{}).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, owner, input, input, "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil))
+			expectNotify(mock, id, entities.ActionUpdate)
+			mock.ExpectCommit()
+			pg := NewPgTaskRepository(db)
+
+			task, err := pg.Update(owner, id, nil, map[string]any{
+				"Title":       input,
+				"Description": input,
+			})
+
+			if err != nil {
+				t.Errorf("unexpected error for input %q: %v", input, err)
+			} else if task.Title != input || task.Description != input {
+				t.Errorf("expected title and description %q but got %q, %q", input, task.Title, task.Description)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations for input %q: %s", input, err)
+			}
+			db.Close()
+		}
+	})
 }
 
 func TestPgDelete(t *testing.T) {
@@ -171,11 +360,14 @@ func TestPgDelete(t *testing.T) {
 		defer db.Close()
 		uuid_, _ := uuid.NewUUID()
 		id := uuid_.String()
-		sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "created_at", "updated_at"}).AddRow(id, "Test task 1", "Test task 1 description", false, time.Now(), time.Now()).AddRow(2, "Test task 2", "Test task 2 description", true, time.Now(), time.Now()).AddRow(3, "Test task 3", "Test task 3 description", false, time.Now(), time.Now())
-		mock.ExpectExec("DELETE FROM tasks").WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlmock.NewRows(columns).AddRow(id, owner, "Test task 1", "Test task 1 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).AddRow(2, owner, "Test task 2", "Test task 2 description", "done", "medium", nil, true, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).AddRow(3, owner, "Test task 3", "Test task 3 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE tasks SET deleted_at = \(\$3\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NULL$`).WithArgs(id, owner, AnyTime{}).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectNotify(mock, id, entities.ActionDelete)
+		mock.ExpectCommit()
 		pg := NewPgTaskRepository(db)
 
-		dId, err := pg.Delete(id)
+		dId, err := pg.Delete(owner, id, nil)
 
 		if err != nil {
 			t.Errorf("error occured: %v", err)
@@ -196,11 +388,13 @@ func TestPgDelete(t *testing.T) {
 		defer db.Close()
 		uuid_, _ := uuid.NewUUID()
 		id := uuid_.String()
-		sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "created_at", "updated_at"}).AddRow(1, "Test task 1", "Test task 1 description", false, time.Now(), time.Now()).AddRow(2, "Test task 2", "Test task 2 description", true, time.Now(), time.Now()).AddRow(3, "Test task 3", "Test task 3 description", false, time.Now(), time.Now())
-		mock.ExpectExec("DELETE FROM tasks").WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlmock.NewRows(columns).AddRow(1, owner, "Test task 1", "Test task 1 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).AddRow(2, owner, "Test task 2", "Test task 2 description", "done", "medium", nil, true, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).AddRow(3, owner, "Test task 3", "Test task 3 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE tasks SET deleted_at = \(\$3\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NULL$`).WithArgs(id, owner, AnyTime{}).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
 		pg := NewPgTaskRepository(db)
 
-		_, err = pg.Delete(id)
+		_, err = pg.Delete(owner, id, nil)
 
 		if err == nil {
 			t.Errorf("expected not found error, but got no error")
@@ -212,6 +406,110 @@ func TestPgDelete(t *testing.T) {
 			t.Errorf("there were unfulfilled expectations: %s", err)
 		}
 	})
+	t.Run("delete a stale version fail", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		version := 1
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE tasks SET deleted_at = \(\$3\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NULL AND version = \(\$4\)$`).WithArgs(id, owner, AnyTime{}, version).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT version FROM tasks").WithArgs(id, owner).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		mock.ExpectRollback()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Delete(owner, id, &version)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition failed error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgRestore(t *testing.T) {
+	t.Run("restore a deleted task", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^UPDATE tasks SET deleted_at = NULL, updated_at = \(\$3\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NOT NULL RETURNING (.+)$`).WithArgs(id, owner, AnyTime{}).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, owner, "Test task", "Test task description", "todo", "medium", nil, false, 3, time.Now(), time.Now(), nil, "{}", nil, nil, nil))
+		expectNotify(mock, id, entities.ActionRestore)
+		mock.ExpectCommit()
+		pg := NewPgTaskRepository(db)
+
+		task, err := pg.Restore(owner, id)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if task.IsDeleted() {
+			t.Errorf("expected restored task to not be deleted")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("restore a task not in trash fail", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectBegin()
+		mock.ExpectQuery("^UPDATE tasks SET deleted_at = NULL").WithArgs(id, owner, AnyTime{}).WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectRollback()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Restore(owner, id)
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected not found error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgPurge(t *testing.T) {
+	t.Run("purge tasks deleted before a time", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		deletedBefore := time.Now().Add(-24 * time.Hour)
+		mock.ExpectExec(`^DELETE FROM tasks WHERE deleted_at < \(\$1\)$`).WithArgs(deletedBefore).WillReturnResult(sqlmock.NewResult(0, 2))
+		pg := NewPgTaskRepository(db)
+
+		purged, err := pg.Purge(deletedBefore)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if purged != 2 {
+			t.Errorf("expected 2 purged tasks, but got %d", purged)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
 }
 
 func TestPgList(t *testing.T) {
@@ -221,11 +519,11 @@ func TestPgList(t *testing.T) {
 			t.Fatalf("sqlmock.New error: %v", err)
 		}
 		defer db.Close()
-		rows := sqlmock.NewRows([]string{"id", "title", "description", "is_completed", "created_at", "updated_at"}).AddRow(1, "Test task 1", "Test task 1 description", false, time.Now(), time.Now()).AddRow(2, "Test task 2", "Test task 2 description", true, time.Now(), time.Now()).AddRow(3, "Test task 3", "Test task 3 description", false, time.Now(), time.Now())
-		mock.ExpectQuery("^SELECT (.+) FROM tasks$").WillReturnRows(rows)
+		rows := sqlmock.NewRows(columns).AddRow(1, owner, "Test task 1", "Test task 1 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).AddRow(2, owner, "Test task 2", "Test task 2 description", "done", "medium", nil, true, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).AddRow(3, owner, "Test task 3", "Test task 3 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil)
+		mock.ExpectQuery("^SELECT (.+) FROM tasks WHERE owner_id = (.+) AND deleted_at IS NULL ORDER BY created_at ASC, id ASC$").WithArgs(owner).WillReturnRows(rows)
 		pg := NewPgTaskRepository(db)
 
-		tasks, err := pg.List()
+		tasks, err := pg.List(owner, entities.ListOptions{})
 
 		if err != nil {
 			t.Errorf("error occured: %v", err)
@@ -243,4 +541,625 @@ func TestPgList(t *testing.T) {
 			t.Errorf("there were unfulfilled expectations: %s", err)
 		}
 	})
+	t.Run("list tasks with filters and cursor", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		isCompleted := true
+		createdAfter := time.Now().Add(-time.Hour)
+		rows := sqlmock.NewRows(columns).AddRow(1, owner, "Test task 1", "Test task 1 description", "done", "medium", nil, true, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil)
+		mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \(\$1\) AND deleted_at IS NULL AND is_completed = \(\$2\) AND created_at > \(\$3\) AND \(title, id\) < \(\$4, \$5\) ORDER BY title DESC, id DESC LIMIT \(\$6\)$`).WithArgs(owner, true, createdAfter, "Test task 2", "2", 10).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		tasks, err := pg.List(owner, entities.ListOptions{
+			SortBy:       entities.SortByTitle,
+			Descending:   true,
+			Limit:        10,
+			After:        &entities.Cursor{Value: "Test task 2", Id: "2"},
+			IsCompleted:  &isCompleted,
+			CreatedAfter: &createdAfter,
+		})
+
+		if err != nil {
+			t.Errorf("error occured: %v", err)
+			return
+		}
+		if len(tasks) != 1 {
+			t.Errorf("expected 1 task but got %d", len(tasks))
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("list tasks with all of the tags", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows(columns).AddRow(1, owner, "Test task 1", "Test task 1 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{home,work}", nil, nil, nil)
+		mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \(\$1\) AND deleted_at IS NULL AND id IN \(SELECT task_tags.task_id FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE tags.owner_id = \(\$1\) AND tags.name = ANY\(\(\$2\)::text\[\]\) GROUP BY task_tags.task_id HAVING COUNT\(\*\) = \(\$3\)\) ORDER BY created_at ASC, id ASC$`).WithArgs(owner, `{"home","work"}`, 2).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		tasks, err := pg.List(owner, entities.ListOptions{Tags: []string{"home", "work"}, AllTags: true})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(tasks) != 1 || strings.Join(tasks[0].Tags, ",") != "home,work" {
+			t.Errorf("expected 1 task tagged home and work, but got %v", tasks)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("list the trash", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows(columns).AddRow(1, owner, "Test task 1", "Test task 1 description", "todo", "medium", nil, false, 2, time.Now(), time.Now(), time.Now(), "{}", nil, nil, nil)
+		mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \(\$1\) AND deleted_at IS NOT NULL ORDER BY created_at ASC, id ASC$`).WithArgs(owner).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		tasks, err := pg.List(owner, entities.ListOptions{Deleted: true})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(tasks) != 1 || !tasks[0].IsDeleted() {
+			t.Errorf("expected 1 deleted task, but got %v", tasks)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("list the occurrences of a series", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		seriesId, recurrence := "1", "FREQ=DAILY"
+		rows := sqlmock.NewRows(columns).AddRow(1, owner, "Test task 1", "Test task 1 description", "done", "medium", time.Now(), true, 2, time.Now(), time.Now(), nil, "{}", nil, recurrence, seriesId).AddRow(2, owner, "Test task 1", "Test task 1 description", "todo", "medium", time.Now(), false, 1, time.Now(), time.Now(), nil, "{}", nil, recurrence, seriesId)
+		mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \(\$1\) AND deleted_at IS NULL AND series_id = \(\$2\) ORDER BY created_at ASC, id ASC$`).WithArgs(owner, seriesId).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		tasks, err := pg.List(owner, entities.ListOptions{SeriesId: &seriesId})
+
+		if err != nil || len(tasks) != 2 {
+			t.Errorf("expected 2 occurrences, but got %v, %v", tasks, err)
+			return
+		}
+		if tasks[1].SeriesId == nil || *tasks[1].SeriesId != seriesId || tasks[1].Recurrence == nil || *tasks[1].Recurrence != recurrence {
+			t.Errorf("expected occurrence of series %s, but got %+v", seriesId, tasks[1])
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgSearch(t *testing.T) {
+	t.Run("search tasks with phrase and prefix", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows(append(columns, "rank", "highlight", "snippet")).AddRow(1, owner, "Write weekly report", "Before the meeting", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil, 0.2, "<b>Write</b> weekly <b>report</b>", "Before the <b>meeting</b>")
+		mock.ExpectQuery(`^SELECT (.+) FROM tasks, to_tsquery\('english', \(\$2\)\) query WHERE owner_id = \(\$1\) AND deleted_at IS NULL AND search_vector @@ query ORDER BY rank DESC, created_at ASC, id ASC LIMIT \(\$3\) OFFSET \(\$4\)$`).WithArgs(owner, "(weekly <-> report) & meet:*", 10, 20).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		results, err := pg.Search(owner, entities.SearchOptions{
+			Terms:  entities.ParseSearchQuery(`"Weekly report" meet*`),
+			Limit:  10,
+			Offset: 20,
+		})
+
+		if err != nil {
+			t.Errorf("error occured: %v", err)
+			return
+		}
+		if len(results) != 1 {
+			t.Errorf("expected 1 result but got %d", len(results))
+			return
+		}
+		if results[0].Snippet != "Before the <b>meeting</b>" {
+			t.Errorf("expected highlighted snippet but got %s", results[0].Snippet)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("search terms can not inject tsquery operators", func(t *testing.T) {
+		got := tsQuery(entities.ParseSearchQuery(`a&b | !c:* 'd'`))
+
+		want := "(a <-> b) & c:* & d"
+		if got != want {
+			t.Errorf("expected tsquery %s but got %s", want, got)
+		}
+	})
+}
+
+func TestPgSetTags(t *testing.T) {
+	t.Run("replace the tags of a task", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		version := 2
+		tags := `{"home","work"}`
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE tasks SET updated_at = \(\$3\), version = version \+ 1 WHERE id = \(\$1\) AND owner_id = \(\$2\) AND deleted_at IS NULL AND version = \(\$4\)$`).WithArgs(id, owner, AnyTime{}, version).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^INSERT INTO tags\(owner_id, name\) (.+) ON CONFLICT \(owner_id, name\) DO NOTHING$`).WithArgs(owner, tags).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^DELETE FROM task_tags WHERE task_id = \(\$1\)`).WithArgs(id, owner, tags).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^INSERT INTO task_tags\(task_id, tag_id\)`).WithArgs(id, owner, tags).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("^SELECT (.+) FROM tasks").WithArgs(id, owner).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, owner, "Test task", "Test task description", "todo", "medium", nil, false, 3, time.Now(), time.Now(), nil, "{home,work}", nil, nil, nil))
+		expectNotify(mock, id, entities.ActionUpdate)
+		mock.ExpectCommit()
+		pg := NewPgTaskRepository(db)
+
+		task, err := pg.SetTags(owner, id, &version, []string{"home", "work"})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if strings.Join(task.Tags, ",") != "home,work" {
+			t.Errorf("expected tags [home work], but got %v", task.Tags)
+		}
+		if task.Version != 3 {
+			t.Errorf("expected version 3, but got %d", task.Version)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("set tags with stale version fail", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		version := 1
+		mock.ExpectBegin()
+		mock.ExpectExec("^UPDATE tasks SET updated_at").WithArgs(id, owner, AnyTime{}, version).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("^SELECT version FROM tasks").WithArgs(id, owner).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+		mock.ExpectRollback()
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.SetTags(owner, id, &version, []string{"home"})
+
+		appError, ok := err.(*errors.AppError)
+		if !ok || appError.Type != errors.PRECONDITION {
+			t.Errorf("expected precondition error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgListTags(t *testing.T) {
+	t.Run("list tags with usage counts", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows([]string{"name", "count"}).AddRow("home", 0).AddRow("work", 2)
+		mock.ExpectQuery(`^SELECT tags.name, COUNT\(tasks.id\) FROM tags (.+) WHERE tags.owner_id = \(\$1\) GROUP BY tags.name ORDER BY tags.name$`).WithArgs(owner).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		tags, err := pg.ListTags(owner)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(tags) != 2 || tags[0] != (entities.TagCount{Name: "home", Count: 0}) || tags[1] != (entities.TagCount{Name: "work", Count: 2}) {
+			t.Errorf("expected tags home(0) and work(2), but got %v", tags)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgSubtree(t *testing.T) {
+	t.Run("subtree of a task", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		parentId := "1"
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, owner, "Test task 1", "Test task 1 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil).
+			AddRow(2, owner, "Test task 2", "Test task 2 description", "done", "medium", nil, true, 2, time.Now(), time.Now(), nil, "{}", parentId, nil, nil)
+		mock.ExpectQuery(`^WITH RECURSIVE subtree\(id, depth\) AS \((.+)\) SELECT (.+) FROM tasks WHERE id IN \(SELECT id FROM subtree\) ORDER BY created_at, id$`).WithArgs("1", owner, entities.MaxDepth).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		tasks, err := pg.Subtree(owner, "1")
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(tasks) != 2 || tasks[0].ParentId != nil || tasks[1].ParentId == nil || *tasks[1].ParentId != parentId {
+			t.Errorf("expected task 1 with subtask 2, but got %+v", tasks)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("subtree of a missing task", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectQuery("^WITH RECURSIVE subtree").WithArgs("1", owner, entities.MaxDepth).WillReturnRows(sqlmock.NewRows(columns))
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.Subtree(owner, "1")
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected task not found, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgDependencies(t *testing.T) {
+	t.Run("list dependencies", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows([]string{"task_id", "blocker_id"}).AddRow("1", "2").AddRow("1", "3")
+		mock.ExpectQuery(`^SELECT task_dependencies.task_id, task_dependencies.blocker_id FROM task_dependencies (.+) WHERE tasks.owner_id = \(\$1\) AND tasks.deleted_at IS NULL AND blockers.deleted_at IS NULL`).WithArgs(owner).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		dependencies, err := pg.ListDependencies(owner)
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if len(dependencies) != 2 || dependencies[1] != (entities.Dependency{TaskId: "1", BlockerId: "3"}) {
+			t.Errorf("expected task 1 to be blocked by 2 and 3, but got %v", dependencies)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("add a dependency on a live blocker of the owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectExec(`^INSERT INTO task_dependencies(.+) WHERE id = \(\$1\) AND owner_id = \(\$3\) AND EXISTS \(SELECT 1 FROM tasks WHERE id = \(\$2\) AND owner_id = \(\$3\) AND deleted_at IS NULL\)`).WithArgs("1", "2", owner, AnyTime{}).WillReturnResult(sqlmock.NewResult(0, 1))
+		pg := NewPgTaskRepository(db)
+
+		err = pg.AddDependency(owner, "1", "2")
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("blockers of a task", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows(columns).AddRow(2, owner, "Test task 2", "Test task 2 description", "todo", "medium", nil, false, 1, time.Now(), time.Now(), nil, "{}", nil, nil, nil)
+		mock.ExpectQuery(`^SELECT (.+) FROM tasks WHERE owner_id = \(\$1\) AND deleted_at IS NULL AND id IN \(SELECT blocker_id FROM task_dependencies WHERE task_id = \(\$2\)\) ORDER BY created_at, id$`).WithArgs(owner, "1").WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		blockers, err := pg.Blockers(owner, "1")
+
+		if err != nil || len(blockers) != 1 || blockers[0].Title != "Test task 2" {
+			t.Errorf("expected blocker 2, but got %v, %v", blockers, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("remove a missing dependency", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectExec("^DELETE FROM task_dependencies").WithArgs("1", "2", owner).WillReturnResult(sqlmock.NewResult(0, 0))
+		pg := NewPgTaskRepository(db)
+
+		err = pg.RemoveDependency(owner, "1", "2")
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected dependency not found, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgEvents(t *testing.T) {
+	t.Run("add event", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectExec("^INSERT INTO task_events").WithArgs("1", owner, owner, entities.ActionUpdate, []byte(`[{"Field":"Title","From":"Old title","To":"New title"}]`), AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+		pg := NewPgTaskRepository(db)
+
+		err = pg.AddEvent(entities.Event{
+			TaskId:    "1",
+			OwnerId:   owner,
+			ActorId:   owner,
+			Action:    entities.ActionUpdate,
+			Changes:   []entities.Change{{Field: "Title", From: "Old title", To: "New title"}},
+			CreatedAt: time.Now(),
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("list events with filters", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		from, to := time.Now().Add(-time.Hour), time.Now()
+		rows := sqlmock.NewRows([]string{"id", "task_id", "owner_id", "actor_id", "action", "changes", "created_at"}).AddRow(3, "1", owner, owner, entities.ActionDelete, []byte("[]"), time.Now()).AddRow(4, "1", owner, owner, entities.ActionRestore, []byte("[]"), time.Now())
+		mock.ExpectQuery(`^SELECT (.+) FROM task_events WHERE actor_id = \(\$1\) AND created_at >= \(\$2\) AND created_at <= \(\$3\) AND id > \(\$4\) ORDER BY id LIMIT \(\$5\)$`).WithArgs(owner, from, to, 2, 10).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+		actorId := owner
+
+		events, err := pg.ListEvents(entities.EventOptions{ActorId: &actorId, From: &from, To: &to, After: 2, Limit: 10})
+
+		if err != nil || len(events) != 2 || events[1].Id != 4 || events[1].Action != entities.ActionRestore {
+			t.Errorf("expected 2 events, but got %+v, %v", events, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgWebhooks(t *testing.T) {
+	t.Run("add webhook", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectExec("^INSERT INTO webhooks").WithArgs("webhook-1", owner, "http://example.com/hook", "{\"task.created\",\"task.deleted\"}", "webhook-secret", AnyTime{}).WillReturnResult(sqlmock.NewResult(1, 1))
+		pg := NewPgTaskRepository(db)
+
+		err = pg.AddWebhook(entities.Webhook{
+			Id:        "webhook-1",
+			OwnerId:   owner,
+			URL:       "http://example.com/hook",
+			Events:    []string{entities.WebhookCreated, entities.WebhookDeleted},
+			Secret:    "webhook-secret",
+			CreatedAt: time.Now(),
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("get webhook of another owner", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectQuery(`^SELECT (.+) FROM webhooks WHERE id = \(\$1\) AND owner_id = \(\$2\)$`).WithArgs("webhook-1", owner).WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "url", "events", "secret", "created_at"}))
+		pg := NewPgTaskRepository(db)
+
+		_, err = pg.GetWebhook(owner, "webhook-1")
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected webhook to be not found, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("list webhooks", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows([]string{"id", "owner_id", "url", "events", "secret", "created_at"}).AddRow("webhook-1", owner, "http://example.com/hook", []byte("{task.created,task.completed}"), "webhook-secret", time.Now())
+		mock.ExpectQuery(`^SELECT (.+) FROM webhooks WHERE owner_id = \(\$1\) ORDER BY created_at, id$`).WithArgs(owner).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		webhooks, err := pg.ListWebhooks(owner)
+
+		if err != nil || len(webhooks) != 1 || !webhooks[0].Subscribes(entities.WebhookCompleted) {
+			t.Errorf("expected webhook-1 to subscribe to completed tasks, but got %+v, %v", webhooks, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("delete missing webhook", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		mock.ExpectExec(`^DELETE FROM webhooks WHERE id = \(\$1\) AND owner_id = \(\$2\)$`).WithArgs("webhook-1", owner).WillReturnResult(sqlmock.NewResult(0, 0))
+		pg := NewPgTaskRepository(db)
+
+		err = pg.DeleteWebhook(owner, "webhook-1")
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected webhook to be not found, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("claim due deliveries", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		now := time.Now()
+		rows := sqlmock.NewRows(strings.Split(deliveryColumns, ", ")).
+			AddRow(7, "webhook-1", owner, entities.WebhookCreated, "1", []byte(`{"event":"task.created"}`), entities.DeliveryPending, 1, now.Add(time.Minute), 500, "webhook answered with status 500", now, nil).
+			AddRow(5, "webhook-1", owner, entities.WebhookUpdated, "1", []byte(`{"event":"task.updated"}`), entities.DeliveryPending, 0, now.Add(time.Minute), nil, nil, now, nil)
+		mock.ExpectQuery(`^UPDATE webhook_deliveries SET next_attempt_at = \(\$3\) WHERE id IN \((.+) FOR UPDATE SKIP LOCKED\s*\) RETURNING`).WithArgs(entities.DeliveryPending, now, now.Add(time.Minute), 10).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+
+		deliveries, err := pg.ClaimDeliveries(now, time.Minute, 10)
+
+		if err != nil || len(deliveries) != 2 || deliveries[0].Id != 5 || string(deliveries[1].Payload) != `{"event":"task.created"}` {
+			t.Errorf("expected deliveries 5 and 7, but got %+v, %v", deliveries, err)
+		}
+		if deliveries[1].ResponseStatus == nil || *deliveries[1].ResponseStatus != 500 || deliveries[0].LastError != nil {
+			t.Errorf("expected the outcome of the attempts, but got %+v", deliveries)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("list deliveries with filters", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		rows := sqlmock.NewRows(strings.Split(deliveryColumns, ", ")).AddRow(3, "webhook-1", owner, entities.WebhookCreated, "1", []byte("{}"), entities.DeliveryFailed, 8, time.Now(), nil, "connection refused", time.Now(), nil)
+		mock.ExpectQuery(`^SELECT (.+) FROM webhook_deliveries WHERE owner_id = \(\$1\) AND webhook_id = \(\$2\) AND status = \(\$3\) AND id < \(\$4\) ORDER BY id DESC LIMIT \(\$5\)$`).WithArgs(owner, "webhook-1", entities.DeliveryFailed, 4, 21).WillReturnRows(rows)
+		pg := NewPgTaskRepository(db)
+		failed := entities.DeliveryFailed
+
+		deliveries, err := pg.ListDeliveries(owner, "webhook-1", entities.DeliveryOptions{Status: &failed, Before: 4, Limit: 21})
+
+		if err != nil || len(deliveries) != 1 || deliveries[0].Id != 3 || deliveries[0].Attempts != 8 {
+			t.Errorf("expected failed delivery 3, but got %+v, %v", deliveries, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+func TestPgNotifications(t *testing.T) {
+	t.Run("decode a notification", func(t *testing.T) {
+		notification, err := decodeNotification(`{"task_id":"1","owner_id":"test-owner","op":"delete"}`)
+
+		want := entities.Notification{TaskId: "1", OwnerId: owner, Action: entities.ActionDelete}
+		if err != nil || notification != want {
+			t.Errorf("expected notification %+v, but got %+v, %v", want, notification, err)
+		}
+	})
+	t.Run("decode a malformed notification fail", func(t *testing.T) {
+		_, err := decodeNotification("task 1 deleted")
+
+		if err == nil {
+			t.Errorf("expected an error, but there was none")
+		}
+	})
+}
+
+func TestPgInTransaction(t *testing.T) {
+	t.Run("writes share one transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectBegin()
+		mock.ExpectExec("^INSERT INTO tasks").WillReturnResult(sqlmock.NewResult(1, 1))
+		expectNotify(mock, id, entities.ActionCreate)
+		mock.ExpectQuery(`^UPDATE tasks SET title = \(\$3\)`).WithArgs(id, owner, "Test task (updated)", AnyTime{}).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, owner, "Test task (updated)", "Test task description", "todo", "medium", nil, false, 2, time.Now(), time.Now(), nil, "{}", nil, nil, nil))
+		expectNotify(mock, id, entities.ActionUpdate)
+		mock.ExpectCommit()
+		pg := NewPgTaskRepository(db)
+
+		err = pg.InTransaction(func(repo *PgTaskRepository) error {
+			if _, err := repo.Insert(owner, id, "Test task", "Test task description", entities.Details{}); err != nil {
+				return err
+			}
+			_, err := repo.Update(owner, id, nil, map[string]any{"Title": "Test task (updated)"})
+			return err
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+	t.Run("failure rolls back every write", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("sqlmock.New error: %v", err)
+		}
+		defer db.Close()
+		uuid_, _ := uuid.NewUUID()
+		id := uuid_.String()
+		mock.ExpectBegin()
+		mock.ExpectExec("^INSERT INTO tasks").WillReturnResult(sqlmock.NewResult(1, 1))
+		expectNotify(mock, id, entities.ActionCreate)
+		mock.ExpectExec("^UPDATE tasks SET deleted_at").WithArgs("missing-task", owner, AnyTime{}).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		pg := NewPgTaskRepository(db)
+
+		err = pg.InTransaction(func(repo *PgTaskRepository) error {
+			if _, err := repo.Insert(owner, id, "Test task", "Test task description", entities.Details{}); err != nil {
+				return err
+			}
+			_, err := repo.Delete(owner, "missing-task", nil)
+			return err
+		})
+
+		if appError, ok := err.(*errors.AppError); !ok || appError.Type != errors.NOT_FOUND {
+			t.Errorf("expected not found error, but got %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
 }
@@ -0,0 +1,156 @@
+package task
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Arup3201/gotasks/internal/entities/task"
+	"github.com/Arup3201/gotasks/internal/errors"
+	"github.com/lib/pq"
+)
+
+const webhookColumns = "id, owner_id, url, events, secret, created_at"
+
+func webhookFields(w *task.Webhook) []any {
+	return []any{&w.Id, &w.OwnerId, &w.URL, pq.Array(&w.Events), &w.Secret, &w.CreatedAt}
+}
+
+const deliveryColumns = "id, webhook_id, owner_id, event, task_id, payload, status, attempts, next_attempt_at, response_status, last_error, created_at, delivered_at"
+
+// deliveryFields scans the payload as bytes, which copies them out of the
+// buffer of the driver.
+func deliveryFields(d *task.Delivery) []any {
+	return []any{&d.Id, &d.WebhookId, &d.OwnerId, &d.Event, &d.TaskId, (*[]byte)(&d.Payload), &d.Status, &d.Attempts, &d.NextAttemptAt, &d.ResponseStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt}
+}
+
+func (pg *PgTaskRepository) AddWebhook(webhook task.Webhook) error {
+	_, err := pg.conn().Exec("INSERT INTO webhooks(id, owner_id, url, events, secret, created_at) VALUES ($1, $2, $3, $4, $5, $6)", webhook.Id, webhook.OwnerId, webhook.URL, pq.Array(webhook.Events), webhook.Secret, webhook.CreatedAt)
+	return err
+}
+
+// ListWebhooks returns the webhooks of the owner by creation.
+func (pg *PgTaskRepository) ListWebhooks(ownerId string) ([]task.Webhook, error) {
+	webhooks := []task.Webhook{}
+	rows, err := pg.conn().Query("SELECT "+webhookColumns+" FROM webhooks WHERE owner_id = ($1) ORDER BY created_at, id", ownerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var webhook task.Webhook
+		if err := rows.Scan(webhookFields(&webhook)...); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (pg *PgTaskRepository) GetWebhook(ownerId, webhookId string) (*task.Webhook, error) {
+	var webhook task.Webhook
+	if err := pg.conn().QueryRow("SELECT "+webhookColumns+" FROM webhooks WHERE id = ($1) AND owner_id = ($2)", webhookId, ownerId).Scan(webhookFields(&webhook)...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundError(fmt.Sprintf("Webhook with ID %s not found", webhookId))
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook removes the webhook with its deliveries.
+func (pg *PgTaskRepository) DeleteWebhook(ownerId, webhookId string) error {
+	res, err := pg.conn().Exec("DELETE FROM webhooks WHERE id = ($1) AND owner_id = ($2)", webhookId, ownerId)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.NotFoundError(fmt.Sprintf("Webhook with ID %s not found", webhookId))
+	}
+	return nil
+}
+
+func (pg *PgTaskRepository) AddDelivery(delivery task.Delivery) error {
+	_, err := pg.conn().Exec("INSERT INTO webhook_deliveries(webhook_id, owner_id, event, task_id, payload, status, attempts, next_attempt_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", delivery.WebhookId, delivery.OwnerId, delivery.Event, delivery.TaskId, []byte(delivery.Payload), delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.CreatedAt)
+	return err
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due at now,
+// the ones due first first. They are not due again before the lease ends, so
+// the other replicas skip them while this one sends them.
+func (pg *PgTaskRepository) ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]task.Delivery, error) {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = ($3) WHERE id IN (
+				SELECT id FROM webhook_deliveries WHERE status = ($1) AND next_attempt_at <= ($2)
+				ORDER BY next_attempt_at, id LIMIT ($4) FOR UPDATE SKIP LOCKED
+			) RETURNING ` + deliveryColumns
+
+	deliveries := []task.Delivery{}
+	rows, err := pg.conn().Query(query, task.DeliveryPending, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var delivery task.Delivery
+		if err := rows.Scan(deliveryFields(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING keeps no order, the ones created first were due first
+	slices.SortFunc(deliveries, func(a, b task.Delivery) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of an attempt of the delivery.
+func (pg *PgTaskRepository) UpdateDelivery(delivery task.Delivery) error {
+	_, err := pg.conn().Exec("UPDATE webhook_deliveries SET status = ($2), attempts = ($3), next_attempt_at = ($4), response_status = ($5), last_error = ($6), delivered_at = ($7) WHERE id = ($1)", delivery.Id, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.LastError, delivery.DeliveredAt)
+	return err
+}
+
+// ListDeliveries returns the deliveries of the webhook that pass the options,
+// the latest first.
+func (pg *PgTaskRepository) ListDeliveries(ownerId, webhookId string, options task.DeliveryOptions) ([]task.Delivery, error) {
+	args := []any{ownerId, webhookId}
+	conditions := []string{"owner_id = ($1)", "webhook_id = ($2)"}
+	if options.Status != nil {
+		args = append(args, *options.Status)
+		conditions = append(conditions, fmt.Sprintf("status = ($%d)", len(args)))
+	}
+	if options.Before > 0 {
+		args = append(args, options.Before)
+		conditions = append(conditions, fmt.Sprintf("id < ($%d)", len(args)))
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM webhook_deliveries WHERE %s ORDER BY id DESC", deliveryColumns, strings.Join(conditions, " AND "))
+	if options.Limit > 0 {
+		args = append(args, options.Limit)
+		query += fmt.Sprintf(" LIMIT ($%d)", len(args))
+	}
+
+	deliveries := []task.Delivery{}
+	rows, err := pg.conn().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var delivery task.Delivery
+		if err := rows.Scan(deliveryFields(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
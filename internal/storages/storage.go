@@ -1,11 +1,14 @@
 package storages
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"time"
 
 	"github.com/Arup3201/gotasks/internal/entities/task"
+	memory "github.com/Arup3201/gotasks/internal/storages/memory/task"
+	"github.com/Arup3201/gotasks/internal/storages/postgres/migrations"
 	postgres "github.com/Arup3201/gotasks/internal/storages/postgres/task"
 	. "github.com/Arup3201/gotasks/internal/utils"
 	_ "github.com/lib/pq"
@@ -13,42 +16,136 @@ import (
 
 const (
 	Postgres = "Postgres"
+	InMemory = "InMemory"
 )
 
 func New(dbType string) (TaskRepository, error) {
 	var repo TaskRepository
 	switch dbType {
 	case Postgres:
-		db, err := sql.Open("postgres", fmt.Sprintf("postgres://%s:%s@%s/%s?sslmode=disable", Config.DBUser, Config.DBPass, Config.DBHost, Config.DBName))
+		db, err := OpenPostgres()
 		if err != nil {
-			return nil, fmt.Errorf("sql.Open error: %v", err)
+			return nil, err
 		}
 
-		_, err = db.Exec(`CREATE TABLE IF NOT EXISTS tasks(
-								id VARCHAR(256) PRIMARY KEY,
-								title TEXT NOT NULL, 
-								description TEXT NOT NULL, 
-								is_completed BOOLEAN NOT NULL, 
-								created_at TIMESTAMP WITH TIME ZONE NOT NULL,
-								updated_at TIMESTAMP WITH TIME ZONE NOT NULL
-							)`)
+		migrator, err := migrations.New(db)
 		if err != nil {
-			log.Fatalf("Table create error: %v", err)
+			db.Close()
+			return nil, err
+		}
+		pending, err := migrator.Pending()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if len(pending) > 0 {
+			db.Close()
+			return nil, fmt.Errorf("database schema is at version %d but the latest is %d, run the 'migrate up' command first", migrator.Latest()-len(pending), migrator.Latest())
+		}
+		unowned, err := migrator.Unowned()
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if unowned > 0 {
+			db.Close()
+			return nil, fmt.Errorf("%d tasks have no owner, run the 'migrate up' command with %s first", unowned, LEGACY_OWNER_ID)
 		}
 
-		repo = postgres.NewPgTaskRepository(db)
+		repo = pgRepository{postgres.NewPgTaskRepository(db)}
+	case InMemory:
+		repo = memRepository{memory.NewMemTaskRepository()}
 	default:
-		// in-memory
+		return nil, fmt.Errorf("unknown storage type %q", dbType)
 	}
 
 	return repo, nil
 }
 
+func OpenPostgres() (*sql.DB, error) {
+	db, err := sql.Open("postgres", postgresURL())
+	if err != nil {
+		return nil, fmt.Errorf("sql.Open error: %v", err)
+	}
+
+	return db, nil
+}
+
+func postgresURL() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", Config.DBUser, Config.DBPass, Config.DBHost, Config.DBPort, Config.DBName)
+}
+
 type TaskRepository interface {
-	Get(taskId string) (*task.Task, error)
-	Insert(taskId string, taskTitle, taskDesc string) (*task.Task, error)
-	Update(taskId string, data map[string]any) (*task.Task, error)
-	Delete(taskId string) (*string, error)
-	List() ([]task.Task, error)
+	Get(ownerId, taskId string) (*task.Task, error)
+	Insert(ownerId, taskId string, taskTitle, taskDesc string, details task.Details) (*task.Task, error)
+	Update(ownerId, taskId string, version *int, data map[string]any) (*task.Task, error)
+	Delete(ownerId, taskId string, version *int) (*string, error)
+	Restore(ownerId, taskId string) (*task.Task, error)
+	Purge(deletedBefore time.Time) (int64, error)
+	List(ownerId string, options task.ListOptions) ([]task.Task, error)
+	// Subtree returns the task followed by the subtasks below it.
+	Subtree(ownerId, taskId string) ([]task.Task, error)
+	Search(ownerId string, options task.SearchOptions) ([]task.SearchResult, error)
+	SetTags(ownerId, taskId string, version *int, tags []string) (*task.Task, error)
+	ListTags(ownerId string) ([]task.TagCount, error)
+	AddDependency(ownerId, taskId, blockerId string) error
+	RemoveDependency(ownerId, taskId, blockerId string) error
+	ListDependencies(ownerId string) ([]task.Dependency, error)
+	// Blockers returns the tasks that block the task, Blocking the ones it
+	// blocks.
+	Blockers(ownerId, taskId string) ([]task.Task, error)
+	Blocking(ownerId, taskId string) ([]task.Task, error)
+	// AddEvent and ListEvents keep the change history of the tasks.
+	AddEvent(event task.Event) error
+	ListEvents(options task.EventOptions) ([]task.Event, error)
+	AddWebhook(webhook task.Webhook) error
+	ListWebhooks(ownerId string) ([]task.Webhook, error)
+	GetWebhook(ownerId, webhookId string) (*task.Webhook, error)
+	DeleteWebhook(ownerId, webhookId string) error
+	// AddDelivery puts a webhook call in the outbox, ClaimDeliveries takes
+	// the due ones out of it for one lease and UpdateDelivery saves how an
+	// attempt went.
+	AddDelivery(delivery task.Delivery) error
+	ClaimDeliveries(now time.Time, lease time.Duration, limit int) ([]task.Delivery, error)
+	UpdateDelivery(delivery task.Delivery) error
+	ListDeliveries(ownerId, webhookId string, options task.DeliveryOptions) ([]task.Delivery, error)
+	// InTransaction runs fn with a repository whose writes are kept all
+	// together when fn succeeds, or not at all when it fails.
+	InTransaction(fn func(repo TaskRepository) error) error
 	Close() error
 }
+
+// ChangeNotifier is a storage that tells about the task writes of every
+// replica of the API, not only the ones of this process.
+type ChangeNotifier interface {
+	// ListenChanges starts calling onChange with every task write until ctx
+	// is done, and onReset when some of the writes could not be told. It
+	// fails when it can't listen to the writes.
+	ListenChanges(ctx context.Context, onChange func(task.Notification), onReset func()) error
+}
+
+// pgRepository and memRepository hand their transactions to functions of
+// any TaskRepository, the storage packages can't name the interface.
+type pgRepository struct {
+	*postgres.PgTaskRepository
+}
+
+func (repo pgRepository) InTransaction(fn func(repo TaskRepository) error) error {
+	return repo.PgTaskRepository.InTransaction(func(tx *postgres.PgTaskRepository) error {
+		return fn(pgRepository{tx})
+	})
+}
+
+func (repo pgRepository) ListenChanges(ctx context.Context, onChange func(task.Notification), onReset func()) error {
+	return postgres.ListenChanges(ctx, postgresURL(), onChange, onReset)
+}
+
+type memRepository struct {
+	*memory.MemTaskRepository
+}
+
+func (repo memRepository) InTransaction(fn func(repo TaskRepository) error) error {
+	return repo.MemTaskRepository.InTransaction(func(tx *memory.MemTaskRepository) error {
+		return fn(memRepository{tx})
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -13,35 +15,330 @@ const (
 	DBPORT                 = "DBPORT"
 	DBPASS                 = "DBPASS"
 	DBNAME                 = "DBNAME"
+	LEGACY_OWNER_ID        = "LEGACY_OWNER_ID"
 	KEYCLOAK_SERVER_URL    = "KEYCLOAK_SERVER_URL"
 	KEYCLOAK_REALM_NAME    = "KEYCLOAK_REALM"
 	KEYCLOAK_CLIENT_ID     = "KEYCLOAK_CLIENT_ID"
 	KEYCLOAK_CLIENT_SECRET = "KEYCLOAK_CLIENT_SECRET"
-	TESTING                = "TESTING"
+	KEYCLOAK_ISSUER        = "KEYCLOAK_ISSUER"
+	KEYCLOAK_AUDIENCE      = "KEYCLOAK_AUDIENCE"
+	KEYCLOAK_USERINFO      = "KEYCLOAK_USERINFO"
+	JWKS_REFRESH_INTERVAL  = "JWKS_REFRESH_INTERVAL"
+	KEYCLOAK_TIMEOUT       = "KEYCLOAK_TIMEOUT"
+	KEYCLOAK_BREAKER_FAILS = "KEYCLOAK_BREAKER_FAILURES"
+	KEYCLOAK_BREAKER_WAIT  = "KEYCLOAK_BREAKER_COOLDOWN"
+	AUTH                   = "AUTH"
+	AUTH_JWT_SECRET        = "AUTH_JWT_SECRET"
+	AUTH_USERS             = "AUTH_USERS"
+	AUTH_TOKEN_TTL         = "AUTH_TOKEN_TTL"
+	AUTH_API_KEYS          = "AUTH_API_KEYS"
+	STORAGE                = "STORAGE"
+	TRASH_RETENTION_DAYS   = "TRASH_RETENTION_DAYS"
+	PURGE_INTERVAL         = "PURGE_INTERVAL"
+	SUBTASK_COMPLETION     = "SUBTASK_COMPLETION"
+	EVENT_REPLAY_SIZE      = "EVENT_REPLAY_SIZE"
+	EVENT_HEARTBEAT        = "EVENT_HEARTBEAT"
+	WEBHOOK_INTERVAL       = "WEBHOOK_INTERVAL"
+	WEBHOOK_TIMEOUT        = "WEBHOOK_TIMEOUT"
+	WEBHOOK_MAX_ATTEMPTS   = "WEBHOOK_MAX_ATTEMPTS"
+	WEBHOOK_BACKOFF        = "WEBHOOK_BACKOFF"
+	WEBHOOK_ALLOW_PRIVATE  = "WEBHOOK_ALLOW_PRIVATE"
+	GRPC_PORT              = "GRPC_PORT"
+	GRAPHQL_MAX_DEPTH      = "GRAPHQL_MAX_DEPTH"
+	GRAPHQL_MAX_COMPLEXITY = "GRAPHQL_MAX_COMPLEXITY"
 )
 
 const defaultPort = "8086"
+const defaultGrpcPort = "9086"
 const defaultDBPort = "5432"
+const defaultStorage = "Postgres"
+const defaultTrashRetentionDays = 30
+const defaultPurgeInterval = time.Hour
+const defaultSubtaskCompletion = "block"
+const defaultEventReplaySize = 1000
+const defaultEventHeartbeat = 15 * time.Second
+const defaultWebhookInterval = 5 * time.Second
+const defaultWebhookTimeout = 10 * time.Second
+const defaultWebhookMaxAttempts = 8
+const defaultWebhookBackoff = 30 * time.Second
+const defaultGraphQLMaxDepth = 10
+const defaultGraphQLMaxComplexity = 2000
+const defaultJWKSRefreshInterval = 15 * time.Minute
+const defaultKeycloakTimeout = 5 * time.Second
+const defaultBreakerFailures = 5
+const defaultBreakerCooldown = 30 * time.Second
+const defaultAuth = "Keycloak"
+const defaultAuthTokenTTL = time.Hour
 
 type envList struct {
 	Port                 string
+	GrpcPort             string
 	DBHost               string
 	DBUser               string
 	DBPort               string
 	DBPass               string
 	DBName               string
+	LegacyOwnerId        string
 	KeycloakServerUrl    string
 	KeycloakRealName     string
 	KeycloakClientId     string
 	KeycloakClientSecret string
-	Testing              bool
+	KeycloakIssuer       string
+	KeycloakAudience     string
+	KeycloakUserInfo     bool
+	JWKSRefreshInterval  time.Duration
+	KeycloakTimeout      time.Duration
+	BreakerFailures      int
+	BreakerCooldown      time.Duration
+	Auth                 string
+	AuthJWTSecret        string
+	AuthUsers            string
+	AuthTokenTTL         time.Duration
+	AuthAPIKeys          string
+	Storage              string
+	TrashRetentionDays   int
+	PurgeInterval        time.Duration
+	SubtaskCompletion    string
+	EventReplaySize      int
+	EventHeartbeat       time.Duration
+	WebhookInterval      time.Duration
+	WebhookTimeout       time.Duration
+	WebhookMaxAttempts   int
+	WebhookBackoff       time.Duration
+	WebhookAllowPrivate  bool
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
 }
 
 var Config = &envList{}
 
 func (eList *envList) Configure() {
 	eList.Port = defaultPort
+	eList.configureGrpc()
 
+	storage, ok := os.LookupEnv(STORAGE)
+	if !ok {
+		eList.Storage = defaultStorage
+	} else {
+		eList.Storage = storage
+	}
+
+	if eList.Storage == defaultStorage {
+		eList.ConfigureDB()
+	}
+
+	eList.configurePurge()
+	eList.configureSubtasks()
+	eList.configureEvents()
+	eList.configureWebhooks()
+	eList.configureGraphQL()
+}
+
+// ConfigureAuth reads the variables of the authenticator picked by AUTH, the
+// tests inject their own authenticator and skip it.
+func (eList *envList) ConfigureAuth() {
+	authType, ok := os.LookupEnv(AUTH)
+	if !ok {
+		eList.Auth = defaultAuth
+	} else {
+		eList.Auth = authType
+	}
+
+	switch eList.Auth {
+	case "Keycloak":
+		eList.configureKeycloak()
+	case "StaticJWT":
+		secret, ok := os.LookupEnv(AUTH_JWT_SECRET)
+		if !ok || len(secret) < 32 {
+			log.Fatalf("%s variable should hold at least 32 characters", AUTH_JWT_SECRET)
+		}
+		eList.AuthJWTSecret = secret
+		eList.AuthUsers = os.Getenv(AUTH_USERS)
+
+		ttl, ok := os.LookupEnv(AUTH_TOKEN_TTL)
+		if !ok {
+			eList.AuthTokenTTL = defaultAuthTokenTTL
+		} else {
+			parsed, err := time.ParseDuration(ttl)
+			if err != nil || parsed <= 0 {
+				log.Fatalf("%s variable should be a positive duration like 1h or 30m", AUTH_TOKEN_TTL)
+			}
+			eList.AuthTokenTTL = parsed
+		}
+	case "APIKey":
+		keys, ok := os.LookupEnv(AUTH_API_KEYS)
+		if !ok {
+			log.Fatalf("%s variable missing in environment variables", AUTH_API_KEYS)
+		}
+		eList.AuthAPIKeys = keys
+	}
+}
+
+// configurePurge reads how long deleted tasks stay in the trash, a retention
+// of 0 days keeps them forever.
+func (eList *envList) configurePurge() {
+	retention, ok := os.LookupEnv(TRASH_RETENTION_DAYS)
+	if !ok {
+		eList.TrashRetentionDays = defaultTrashRetentionDays
+	} else {
+		days, err := strconv.Atoi(retention)
+		if err != nil || days < 0 {
+			log.Fatalf("%s variable should be a number of days", TRASH_RETENTION_DAYS)
+		}
+		eList.TrashRetentionDays = days
+	}
+
+	interval, ok := os.LookupEnv(PURGE_INTERVAL)
+	if !ok {
+		eList.PurgeInterval = defaultPurgeInterval
+	} else {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive duration like 1h or 30m", PURGE_INTERVAL)
+		}
+		eList.PurgeInterval = parsed
+	}
+}
+
+// configureSubtasks reads whether completing a task with open subtasks is
+// refused ("block") or completes them too ("cascade").
+func (eList *envList) configureSubtasks() {
+	completion, ok := os.LookupEnv(SUBTASK_COMPLETION)
+	if !ok {
+		eList.SubtaskCompletion = defaultSubtaskCompletion
+	} else {
+		switch strings.ToLower(completion) {
+		case "block", "cascade":
+			eList.SubtaskCompletion = strings.ToLower(completion)
+		default:
+			log.Fatalf("%s variable should be block/cascade", SUBTASK_COMPLETION)
+		}
+	}
+}
+
+// configureEvents reads how many task changes are kept for the streams to
+// resume from, and how often an idle stream gets a heartbeat.
+func (eList *envList) configureEvents() {
+	size, ok := os.LookupEnv(EVENT_REPLAY_SIZE)
+	if !ok {
+		eList.EventReplaySize = defaultEventReplaySize
+	} else {
+		parsed, err := strconv.Atoi(size)
+		if err != nil || parsed < 0 {
+			log.Fatalf("%s variable should be a number of changes", EVENT_REPLAY_SIZE)
+		}
+		eList.EventReplaySize = parsed
+	}
+
+	heartbeat, ok := os.LookupEnv(EVENT_HEARTBEAT)
+	if !ok {
+		eList.EventHeartbeat = defaultEventHeartbeat
+	} else {
+		parsed, err := time.ParseDuration(heartbeat)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive duration like 15s", EVENT_HEARTBEAT)
+		}
+		eList.EventHeartbeat = parsed
+	}
+}
+
+// configureWebhooks reads how often the webhook outbox is checked, how long a
+// delivery may take and how it is retried: WEBHOOK_BACKOFF after the first
+// failure, doubling after each other one, until WEBHOOK_MAX_ATTEMPTS failed.
+// WEBHOOK_ALLOW_PRIVATE lets webhooks reach loopback, private and link-local
+// addresses, for a receiver running next to the API in development.
+func (eList *envList) configureWebhooks() {
+	for _, duration := range []struct {
+		name         string
+		value        *time.Duration
+		defaultValue time.Duration
+	}{
+		{WEBHOOK_INTERVAL, &eList.WebhookInterval, defaultWebhookInterval},
+		{WEBHOOK_TIMEOUT, &eList.WebhookTimeout, defaultWebhookTimeout},
+		{WEBHOOK_BACKOFF, &eList.WebhookBackoff, defaultWebhookBackoff},
+	} {
+		value, ok := os.LookupEnv(duration.name)
+		if !ok {
+			*duration.value = duration.defaultValue
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive duration like 10s", duration.name)
+		}
+		*duration.value = parsed
+	}
+
+	attempts, ok := os.LookupEnv(WEBHOOK_MAX_ATTEMPTS)
+	if !ok {
+		eList.WebhookMaxAttempts = defaultWebhookMaxAttempts
+	} else {
+		parsed, err := strconv.Atoi(attempts)
+		if err != nil || parsed < 1 {
+			log.Fatalf("%s variable should be a positive number of attempts", WEBHOOK_MAX_ATTEMPTS)
+		}
+		eList.WebhookMaxAttempts = parsed
+	}
+
+	allowPrivate, ok := os.LookupEnv(WEBHOOK_ALLOW_PRIVATE)
+	if !ok {
+		eList.WebhookAllowPrivate = false
+	} else {
+		switch strings.ToLower(allowPrivate) {
+		case "true":
+			eList.WebhookAllowPrivate = true
+		case "false":
+			eList.WebhookAllowPrivate = false
+		default:
+			log.Fatalf("%s variable should be true/false", WEBHOOK_ALLOW_PRIVATE)
+		}
+	}
+}
+
+// configureGraphQL reads how deeply a GraphQL query may nest fields, and how
+// many fields it may resolve counting every task of a list.
+func (eList *envList) configureGraphQL() {
+	for _, limit := range []struct {
+		name         string
+		value        *int
+		defaultValue int
+	}{
+		{GRAPHQL_MAX_DEPTH, &eList.GraphQLMaxDepth, defaultGraphQLMaxDepth},
+		{GRAPHQL_MAX_COMPLEXITY, &eList.GraphQLMaxComplexity, defaultGraphQLMaxComplexity},
+	} {
+		value, ok := os.LookupEnv(limit.name)
+		if !ok {
+			*limit.value = limit.defaultValue
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			log.Fatalf("%s variable should be a positive number", limit.name)
+		}
+		*limit.value = parsed
+	}
+}
+
+// configureGrpc reads the port of the gRPC API, served next to the HTTP one.
+func (eList *envList) configureGrpc() {
+	port, ok := os.LookupEnv(GRPC_PORT)
+	if !ok {
+		eList.GrpcPort = defaultGrpcPort
+		return
+	}
+	parsed, err := strconv.Atoi(port)
+	if err != nil || parsed < 1 || parsed > 65535 {
+		log.Fatalf("%s variable should be a port number", GRPC_PORT)
+	}
+	if port == eList.Port {
+		log.Fatalf("%s variable should differ from the HTTP port %s", GRPC_PORT, eList.Port)
+	}
+	eList.GrpcPort = port
+}
+
+// ConfigureDB only reads the database variables, for commands that do not serve
+// the API.
+func (eList *envList) ConfigureDB() {
 	db_host, ok := os.LookupEnv(DBHOST)
 	if !ok {
 		log.Fatalf("%s variable missing in environment variables", DBHOST)
@@ -78,6 +375,11 @@ func (eList *envList) Configure() {
 		eList.DBName = db_name
 	}
 
+	eList.LegacyOwnerId = os.Getenv(LEGACY_OWNER_ID)
+}
+
+func (eList *envList) configureKeycloak() {
+
 	KeycloakServerUrl, ok := os.LookupEnv(KEYCLOAK_SERVER_URL)
 	if !ok {
 		log.Fatalf("%s variable missing in environment variables", KEYCLOAK_SERVER_URL)
@@ -106,18 +408,86 @@ func (eList *envList) Configure() {
 		eList.KeycloakClientSecret = KeycloakClientSecret
 	}
 
-	IsTesting, ok := os.LookupEnv(TESTING)
+	eList.configureTokens()
+	eList.configureClient()
+}
+
+// configureTokens reads how access tokens are validated, the issuer defaults to
+// the realm URL and the audience to the client.
+func (eList *envList) configureTokens() {
+	issuer, ok := os.LookupEnv(KEYCLOAK_ISSUER)
 	if !ok {
-		eList.Testing = false
+		eList.KeycloakIssuer = fmt.Sprintf("%s/realms/%s", eList.KeycloakServerUrl, eList.KeycloakRealName)
 	} else {
-		parsed := strings.ToLower(IsTesting)
-		switch parsed {
+		eList.KeycloakIssuer = issuer
+	}
+
+	audience, ok := os.LookupEnv(KEYCLOAK_AUDIENCE)
+	if !ok {
+		eList.KeycloakAudience = eList.KeycloakClientId
+	} else {
+		eList.KeycloakAudience = audience
+	}
+
+	userInfo, ok := os.LookupEnv(KEYCLOAK_USERINFO)
+	if !ok {
+		eList.KeycloakUserInfo = false
+	} else {
+		switch strings.ToLower(userInfo) {
 		case "true":
-			eList.Testing = true
+			eList.KeycloakUserInfo = true
 		case "false":
-			eList.Testing = false
+			eList.KeycloakUserInfo = false
 		default:
-			log.Fatalf("%s variable should be true/false", TESTING)
+			log.Fatalf("%s variable should be true/false", KEYCLOAK_USERINFO)
+		}
+	}
+
+	interval, ok := os.LookupEnv(JWKS_REFRESH_INTERVAL)
+	if !ok {
+		eList.JWKSRefreshInterval = defaultJWKSRefreshInterval
+	} else {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive duration like 15m or 1h", JWKS_REFRESH_INTERVAL)
+		}
+		eList.JWKSRefreshInterval = parsed
+	}
+}
+
+// configureClient reads how long a request to Keycloak may take, and after
+// how many failures in a row the requests stop for the cooldown.
+func (eList *envList) configureClient() {
+	timeout, ok := os.LookupEnv(KEYCLOAK_TIMEOUT)
+	if !ok {
+		eList.KeycloakTimeout = defaultKeycloakTimeout
+	} else {
+		parsed, err := time.ParseDuration(timeout)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive duration like 5s", KEYCLOAK_TIMEOUT)
+		}
+		eList.KeycloakTimeout = parsed
+	}
+
+	failures, ok := os.LookupEnv(KEYCLOAK_BREAKER_FAILS)
+	if !ok {
+		eList.BreakerFailures = defaultBreakerFailures
+	} else {
+		parsed, err := strconv.Atoi(failures)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive integer", KEYCLOAK_BREAKER_FAILS)
+		}
+		eList.BreakerFailures = parsed
+	}
+
+	cooldown, ok := os.LookupEnv(KEYCLOAK_BREAKER_WAIT)
+	if !ok {
+		eList.BreakerCooldown = defaultBreakerCooldown
+	} else {
+		parsed, err := time.ParseDuration(cooldown)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("%s variable should be a positive duration like 30s", KEYCLOAK_BREAKER_WAIT)
 		}
+		eList.BreakerCooldown = parsed
 	}
 }
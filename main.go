@@ -1,27 +1,93 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"time"
 
+	"github.com/Arup3201/gotasks/internal/auth"
+	grpcController "github.com/Arup3201/gotasks/internal/controllers/grpc"
 	httpController "github.com/Arup3201/gotasks/internal/controllers/http"
+	"github.com/Arup3201/gotasks/internal/services/domain/task"
 	"github.com/Arup3201/gotasks/internal/storages"
 	. "github.com/Arup3201/gotasks/internal/utils"
 )
 
+// shutdownTimeout bounds the wait for the requests in flight when the server
+// stops.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		Config.ConfigureDB()
+
+		if err := migrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	Config.Configure()
+	Config.ConfigureAuth()
 
-	storage, err := storages.New(storages.Postgres)
+	storage, err := storages.New(Config.Storage)
 	if err != nil {
 		log.Fatalf("Storage creation failed: %v", err)
 	}
 
-	err = httpController.InitServer(storage)
+	// the jobs and the APIs share the service, so that the writes of the
+	// jobs reach the subscribers of the APIs
+	serviceHandler, err := task.NewTaskService(storage)
+	if err != nil {
+		log.Fatalf("Service creation failed: %v", err)
+	}
+	if err := serviceHandler.FollowChanges(context.Background()); err != nil {
+		log.Fatalf("Task changes listen failed: %v", err)
+	}
+
+	if Config.TrashRetentionDays > 0 {
+		retention := time.Duration(Config.TrashRetentionDays) * 24 * time.Hour
+		go serviceHandler.RunPurge(context.Background(), retention, Config.PurgeInterval)
+	}
+	go serviceHandler.RunWebhooks(context.Background(), Config.WebhookInterval)
+
+	authenticator, err := auth.New(Config.Auth)
+	if err != nil {
+		log.Fatalf("Authenticator creation failed: %v", err)
+	}
+
+	err = httpController.InitServer(serviceHandler, authenticator)
 	if err != nil {
 		log.Fatalf("Server create failed: %v", err)
 	}
-	err = httpController.Server.Run("0.0.0.0")
+	err = grpcController.InitServer(serviceHandler, authenticator)
 	if err != nil {
+		log.Fatalf("gRPC server create failed: %v", err)
+	}
+
+	grpcErrors := make(chan error, 1)
+	go func() {
+		grpcErrors <- grpcController.Server.Run("0.0.0.0")
+	}()
+	httpErrors := make(chan error, 1)
+	go func() {
+		httpErrors <- httpController.Server.Run("0.0.0.0")
+	}()
+
+	// the process exits when either API stops, a failed gRPC server lets the
+	// HTTP requests in flight be answered first
+	select {
+	case err := <-grpcErrors:
+		log.Printf("gRPC server failed: %v", err)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		if err := httpController.Server.Shutdown(ctx); err != nil {
+			log.Printf("Server shutdown failed: %v", err)
+		}
+		cancel()
+		os.Exit(1)
+	case err := <-httpErrors:
+		grpcController.Server.Stop()
 		log.Fatalf("Server create failed: %v", err)
 	}
 }
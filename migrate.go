@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Arup3201/gotasks/internal/storages"
+	"github.com/Arup3201/gotasks/internal/storages/postgres/migrations"
+	. "github.com/Arup3201/gotasks/internal/utils"
+)
+
+const migrateUsage = "usage: migrate up [steps] | migrate down [steps] | migrate status"
+
+// migrate runs the schema migrations of the Postgres storage, up applies all
+// pending migrations and down reverts the last one unless steps is given.
+func migrate(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New(migrateUsage)
+	}
+
+	steps := 0
+	if args[0] == "down" {
+		steps = 1
+	}
+	if len(args) == 2 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil || parsed < 1 {
+			return fmt.Errorf("steps must be a positive number, %s", migrateUsage)
+		}
+		steps = parsed
+	}
+
+	db, err := storages.OpenPostgres()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(steps)
+		for _, migration := range applied {
+			log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if err := assignLegacyOwner(migrator); err != nil {
+			return err
+		}
+	case "down":
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted migration %d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+		log.Printf("Schema version %d of %d", migrator.Latest()-len(pending), migrator.Latest())
+		for _, migration := range pending {
+			log.Printf("Pending migration %d_%s", migration.Version, migration.Name)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
+
+// assignLegacyOwner gives the tasks written before the schema had owners to
+// LEGACY_OWNER_ID, and fails while some are left without one, since no user
+// could reach them.
+func assignLegacyOwner(migrator *migrations.Migrator) error {
+	if Config.LegacyOwnerId != "" {
+		assigned, err := migrator.AssignOwner(Config.LegacyOwnerId)
+		if err != nil {
+			return err
+		}
+		if assigned > 0 {
+			log.Printf("Assigned %d tasks to legacy owner %s", assigned, Config.LegacyOwnerId)
+		}
+		return nil
+	}
+
+	unowned, err := migrator.Unowned()
+	if err != nil {
+		return err
+	}
+	if unowned > 0 {
+		return fmt.Errorf("%d tasks have no owner, set %s to the user that owns them and run 'migrate up' again", unowned, LEGACY_OWNER_ID)
+	}
+	return nil
+}